@@ -66,5 +66,6 @@ func newAddCmd() *cobra.Command {
 	}
 	setRequireBuildahAnnotation(addCmd)
 	addArgs.RegisterFlags(addCmd.Flags(), "be joined", "join")
+	registerEventOutputFlag(addCmd)
 	return addCmd
 }
@@ -15,9 +15,12 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/labring/sealos/pkg/apply"
+	"github.com/labring/sealos/pkg/apply/applydrivers"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
@@ -32,6 +35,9 @@ func newApplyCmd() *cobra.Command {
 		Example: `sealos apply -f Clusterfile`,
 		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setupPlanOutput(cmd, applyArgs.DryRun); err != nil {
+				return err
+			}
 			applier, err := apply.NewApplierFromFile(cmd, clusterFile, applyArgs)
 			if err != nil {
 				return err
@@ -45,5 +51,19 @@ func newApplyCmd() *cobra.Command {
 	setRequireBuildahAnnotation(applyCmd)
 	applyCmd.Flags().StringVarP(&clusterFile, "Clusterfile", "f", "Clusterfile", "apply a kubernetes cluster")
 	applyArgs.RegisterFlags(applyCmd.Flags())
+	registerEventOutputFlag(applyCmd)
 	return applyCmd
 }
+
+// setupPlanOutput keeps stdout for the plan printed by --dry-run only.
+func setupPlanOutput(cmd *cobra.Command, dryRun bool) error {
+	if !dryRun {
+		return nil
+	}
+	out, err := reserveStdout()
+	if err != nil {
+		return fmt.Errorf("failed to reserve stdout for the plan: %v", err)
+	}
+	cmd.SetContext(applydrivers.WithPlanOutput(cmd.Context(), out))
+	return nil
+}
@@ -17,10 +17,14 @@ package cmd
 import (
 	"fmt"
 	"path"
+	"strings"
+	"time"
 
+	"github.com/modood/table"
 	"github.com/spf13/cobra"
 
 	"github.com/labring/sealos/pkg/apply/processor"
+	"github.com/labring/sealos/pkg/cert"
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/runtime"
@@ -47,49 +51,165 @@ func newCertCmd() *cobra.Command {
     3. kubectl get pod, to check if it works or not
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			processor.SyncNewVersionConfig(clusterName)
-
-			clusterPath := constants.Clusterfile(clusterName)
-			pathResolver := constants.NewPathResolver(clusterName)
-
-			var runtimeConfigPath string
-
-			for _, f := range []string{
-				path.Join(pathResolver.ConfigsPath(), "kubeadm-init.yaml"),
-				path.Join(pathResolver.EtcPath(), "kubeadm-init.yaml"),
-				path.Join(pathResolver.ConfigsPath(), "k3s-init.yaml"),
-			} {
-				if fileutils.IsExist(f) {
-					runtimeConfigPath = f
-					break
-				}
-			}
-			if runtimeConfigPath == "" {
-				logger.Warn("cannot locate the default runtime config file")
-			}
-			var opts []clusterfile.OptionFunc
-			if runtimeConfigPath != "" {
-				opts = append(opts, clusterfile.WithCustomRuntimeConfigFiles([]string{runtimeConfigPath}))
-			}
-			cf := clusterfile.NewClusterFile(clusterPath, opts...)
-			if err := cf.Process(); err != nil {
-				return err
-			}
-
-			rt, err := factory.New(cf.GetCluster(), cf.GetRuntimeConfig())
+			cm, err := getCertManager(clusterName)
 			if err != nil {
-				return fmt.Errorf("create runtime failed: %v", err)
+				return err
 			}
-			if cm, ok := rt.(runtime.CertManager); ok {
-				logger.Info("using %s cert update implement", cf.GetCluster().GetDistribution())
+			if cm != nil {
 				return cm.UpdateCertSANs(altNames)
 			}
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to applied exec action")
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to update the API server cert of")
 	cmd.Flags().StringSliceVar(&altNames, "alt-names", []string{}, "add extra Subject Alternative Names for certs, domain or ip, eg. sealos.io or 10.103.97.2")
 	_ = cmd.MarkFlagRequired("alt-names")
+	cmd.AddCommand(newCertRenewCmd())
+	cmd.AddCommand(newCertCheckCmd())
 
 	return cmd
 }
+
+func newCertRenewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "renew",
+		Short: "renew all certificates and kubeconfig files of control-plane",
+		Long: `Renew all certificates signed by the cluster CA and the kubeconfig files used by control-plane components,
+    the CA certificates are not changed. Static pods will be restarted master by master after renewing.
+	you had better backup /etc/kubernetes first.`,
+		Example: `
+renew all certs of the default cluster:
+	sealos cert renew
+
+renew all certs of the cluster named "mycluster":
+	sealos cert renew -c mycluster`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, err := getCertManager(clusterName)
+			if err != nil {
+				return err
+			}
+			if cm == nil {
+				return fmt.Errorf("cert renewal is not supported by this cluster")
+			}
+			return cm.Renew()
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to renew the certs of")
+	return cmd
+}
+
+func newCertCheckCmd() *cobra.Command {
+	var threshold int
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "check the expiration of certificates and kubeconfig files on all masters",
+		Long: `Fetch all certificates under /etc/kubernetes/pki and the kubeconfig files used by control-plane
+    from every master, print their subjects, SANs and days remaining.
+	exit with non-zero code if any of them expires within the threshold days, so it can be used in cron jobs.`,
+		Example: `
+check all certs of the default cluster:
+	sealos cert check
+
+fail if any cert expires within 60 days:
+	sealos cert check --threshold 60`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cm, err := getCertManager(clusterName)
+			if err != nil {
+				return err
+			}
+			if cm == nil {
+				return fmt.Errorf("cert check is not supported by this cluster")
+			}
+			expirations, err := cm.CheckExpiration()
+			if err != nil {
+				return err
+			}
+			return printCertExpirations(expirations, threshold)
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to check the certs of")
+	cmd.Flags().IntVar(&threshold, "threshold", 30, "exit with non-zero code if any cert expires within the given days")
+	return cmd
+}
+
+func printCertExpirations(expirations []cert.Expiration, threshold int) error {
+	type certRow struct {
+		Host     string
+		Name     string
+		Subject  string
+		SANs     string
+		Expires  string
+		Residual string
+	}
+	now := time.Now()
+	rows := make([]certRow, 0, len(expirations))
+	var expiring []string
+	for _, e := range expirations {
+		days := e.DaysRemaining(now)
+		name := e.Name
+		if e.IsCA {
+			name += " (CA)"
+		}
+		rows = append(rows, certRow{
+			Host:     e.Host,
+			Name:     name,
+			Subject:  e.Subject,
+			SANs:     strings.Join(e.SANs, ","),
+			Expires:  e.NotAfter.Format(time.RFC3339),
+			Residual: fmt.Sprintf("%dd", days),
+		})
+		if days < threshold {
+			expiring = append(expiring, fmt.Sprintf("%s:%s", e.Host, e.Name))
+		}
+	}
+	table.OutputA(rows)
+	if len(expiring) > 0 {
+		return fmt.Errorf("%d certs expire within %d days: %s", len(expiring), threshold, strings.Join(expiring, " "))
+	}
+	return nil
+}
+
+// getCertManager returns nil if the runtime of cluster does not implement runtime.CertManager
+func getCertManager(clusterName string) (runtime.CertManager, error) {
+	processor.SyncNewVersionConfig(clusterName)
+
+	clusterPath := constants.Clusterfile(clusterName)
+	pathResolver := constants.NewPathResolver(clusterName)
+
+	var runtimeConfigPath string
+
+	for _, f := range []string{
+		path.Join(pathResolver.ConfigsPath(), "kubeadm-init.yaml"),
+		path.Join(pathResolver.EtcPath(), "kubeadm-init.yaml"),
+		path.Join(pathResolver.ConfigsPath(), "k3s-init.yaml"),
+	} {
+		if fileutils.IsExist(f) {
+			runtimeConfigPath = f
+			break
+		}
+	}
+	if runtimeConfigPath == "" {
+		logger.Warn("cannot locate the default runtime config file")
+	}
+	var opts []clusterfile.OptionFunc
+	if runtimeConfigPath != "" {
+		opts = append(opts, clusterfile.WithCustomRuntimeConfigFiles([]string{runtimeConfigPath}))
+	}
+	cf := clusterfile.NewClusterFile(clusterPath, opts...)
+	if err := cf.Process(); err != nil {
+		return nil, err
+	}
+
+	rt, err := factory.New(cf.GetCluster(), cf.GetRuntimeConfig())
+	if err != nil {
+		return nil, fmt.Errorf("create runtime failed: %v", err)
+	}
+	if cm, ok := rt.(runtime.CertManager); ok {
+		logger.Info("using %s cert implement", cf.GetCluster().GetDistribution())
+		return cm, nil
+	}
+	logger.Warn("%s does not support managing certs", cf.GetCluster().GetDistribution())
+	return nil, nil
+}
@@ -0,0 +1,90 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/checker"
+	"github.com/labring/sealos/pkg/clusterfile"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+)
+
+const exampleCheck = `
+run preflight checks on all hosts of Clusterfile before creating the cluster:
+	sealos check --phase pre -f Clusterfile
+run preflight checks on hosts to join default cluster:
+	sealos check --phase pre --hosts 192.168.0.5,192.168.0.6
+check the status of default cluster:
+	sealos check --phase post
+`
+
+func newCheckCmd() *cobra.Command {
+	var file, phase string
+	var hosts []string
+	var opts statusOptions
+	var cmd = &cobra.Command{
+		Use:     "check",
+		Short:   "Run the preflight checks on hosts or check the status of cluster",
+		Example: exampleCheck,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cluster, err := loadCheckCluster(file)
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(phase) {
+			case strings.ToLower(checker.PhasePre):
+				known := append(cluster.GetMasterIPAndPortList(), cluster.GetNodeIPAndPortList()...)
+				if len(hosts) == 0 {
+					hosts = known
+				}
+				for _, h := range hosts {
+					if !slices.Contains(iputils.GetHostIPs(known), iputils.GetHostIP(h)) {
+						// hosts to join are not in cluster yet, they are connected with the global ssh config
+						cluster.Spec.Hosts = append(cluster.Spec.Hosts, v2.Host{IPS: []string{h}, Roles: []string{v2.NODE}})
+					}
+				}
+				return checker.RunCheckList([]checker.Interface{checker.NewPreflightChecker(hosts)}, cluster, checker.PhasePre)
+			case strings.ToLower(checker.PhasePost):
+				return opts.run(cluster, postCheckers())
+			default:
+				return fmt.Errorf("invalid phase %s, must be pre or post", phase)
+			}
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to check")
+	cmd.Flags().StringVarP(&file, "Clusterfile", "f", "", "check the cluster of Clusterfile instead of the one of cluster name")
+	cmd.Flags().StringVar(&phase, "phase", strings.ToLower(checker.PhasePre), "phase of checks, pre runs the preflight checks on hosts, post checks the status of cluster")
+	cmd.Flags().StringSliceVar(&hosts, "hosts", nil, "hosts to run the preflight checks, defaults to all hosts of cluster, hosts not in cluster are checked as nodes")
+	opts.registerFlags(cmd.Flags())
+	return cmd
+}
+
+func loadCheckCluster(file string) (*v2.Cluster, error) {
+	if file == "" {
+		return clusterfile.GetClusterFromName(clusterName)
+	}
+	cf := clusterfile.NewClusterFile(file)
+	if err := cf.Process(); err != nil {
+		return nil, fmt.Errorf("failed to load Clusterfile %s: %v", file, err)
+	}
+	return cf.GetCluster(), nil
+}
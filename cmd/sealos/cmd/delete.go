@@ -73,6 +73,7 @@ func newDeleteCmd() *cobra.Command {
 	}
 	setRequireBuildahAnnotation(deleteCmd)
 	deleteArgs.RegisterFlags(deleteCmd.Flags(), "removed", "remove")
+	registerEventOutputFlag(deleteCmd)
 	deleteCmd.Flags().BoolVar(&processor.ForceDelete, "force", false, "we also can input an --force flag to delete cluster by force")
 	return deleteCmd
 }
@@ -20,12 +20,13 @@ import (
 	"context"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
+	"golang.org/x/exp/slices"
 
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 var clusterName string
@@ -37,6 +38,8 @@ specify the cluster name(If there is only one cluster in the $HOME/.sealos direc
     sealos exec -c my-cluster "cat /etc/hosts"
 set role label to exec cmd:
     sealos exec -c my-cluster -r master,node "cat /etc/hosts"
+set host group of Clusterfile to exec cmd:
+    sealos exec -c my-cluster -r gpu-workers "nvidia-smi"
 set ips to exec cmd:
     sealos exec -c my-cluster --ips 172.16.1.38 "cat /etc/hosts"
 `
@@ -62,7 +65,7 @@ func newExecCmd() *cobra.Command {
 		},
 	}
 	execCmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to run commands")
-	execCmd.Flags().StringSliceVarP(&roles, "roles", "r", []string{}, "run command on nodes with role")
+	execCmd.Flags().StringSliceVarP(&roles, "roles", "r", []string{}, "run command on nodes with role or in host group")
 	execCmd.Flags().StringSliceVar(&ips, "ips", []string{}, "run command on nodes with ip address")
 	return execCmd
 }
@@ -76,7 +79,12 @@ func getTargets(cluster *v2.Cluster, ips []string, roles []string) []string {
 	}
 	var targets []string
 	for i := range roles {
-		targets = append(targets, cluster.GetIPSByRole(roles[i])...)
+		// a host can be in more than one group
+		for _, ip := range cluster.GetIPSByRole(roles[i]) {
+			if !slices.Contains(targets, ip) {
+				targets = append(targets, ip)
+			}
+		}
 	}
 	return targets
 }
@@ -86,7 +94,7 @@ func runCommand(cluster *v2.Cluster, targets []string, args []string) error {
 	if err != nil {
 		return err
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, ipAddr := range targets {
 		ip := ipAddr
 		eg.Go(func() error {
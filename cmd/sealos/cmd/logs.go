@@ -0,0 +1,103 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/transcript"
+)
+
+const exampleLogs = `
+list the recorded runs of default cluster:
+	sealos logs
+show the outputs of all hosts in a run:
+	sealos logs --run 20230601-120000
+show the outputs of a host in the latest run:
+	sealos logs -c my-cluster --host 192.168.0.2
+`
+
+func newLogsCmd() *cobra.Command {
+	var host, runID string
+	var cmd = &cobra.Command{
+		Use:     "logs",
+		Short:   "Show the outputs of commands executed on hosts in previous runs",
+		Long:    "Outputs of commands executed on each host by apply, run, add, delete, reset and uninstall are recorded per run, list the runs if neither host nor run is specified.",
+		Example: exampleLogs,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if host == "" && runID == "" {
+				return listRuns(clusterName)
+			}
+			run, err := transcript.GetRun(clusterName, runID)
+			if err != nil {
+				return err
+			}
+			if host != "" {
+				return printHostLog(run, host, false)
+			}
+			for _, h := range run.Hosts {
+				if err := printHostLog(run, h, true); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to show logs")
+	cmd.Flags().StringVar(&host, "host", "", "show the outputs of the host, the latest run is used if run is not specified")
+	cmd.Flags().StringVar(&runID, "run", "", "show the outputs of the run")
+	return cmd
+}
+
+func listRuns(cluster string) error {
+	runs, err := transcript.ListRuns(cluster)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "RUN\tSTATUS\tSTARTED\tDURATION\tHOSTS\tCOMMAND")
+	for _, r := range runs {
+		duration := "-"
+		if r.FinishTime != nil {
+			duration = r.FinishTime.Sub(r.StartTime).Round(time.Second).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", r.ID, r.Status(), r.StartTime.Format(time.RFC3339), duration, len(r.Hosts), r.Command)
+	}
+	return w.Flush()
+}
+
+func printHostLog(run *transcript.Run, host string, withHeader bool) error {
+	path, ok := run.LogFile(host)
+	if !ok {
+		return fmt.Errorf("no output of host %s is recorded in run %s", host, run.ID)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if withHeader {
+		fmt.Printf("==> %s <==\n", host)
+	}
+	_, err = io.Copy(os.Stdout, f)
+	return err
+}
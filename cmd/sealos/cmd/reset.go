@@ -59,6 +59,7 @@ func newResetCmd() *cobra.Command {
 	}
 	setRequireBuildahAnnotation(resetCmd)
 	resetArgs.RegisterFlags(resetCmd.Flags())
+	registerEventOutputFlag(resetCmd)
 	resetCmd.Flags().BoolVar(&processor.ForceDelete, "force", false, "we also can input an --force flag to reset cluster by force")
 	return resetCmd
 }
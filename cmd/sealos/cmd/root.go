@@ -15,23 +15,34 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
 
 	sreglog "github.com/labring/sreg/pkg/utils/logger"
 	"github.com/spf13/cobra"
+	"golang.org/x/sys/unix"
 	"k8s.io/kubectl/pkg/util/templates"
 
 	"github.com/labring/sealos/pkg/buildah"
 	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/events"
 	"github.com/labring/sealos/pkg/system"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 var (
 	debug bool
+	// eventOutput is the format of the output of commands in the apply path.
+	eventOutput string
+)
+
+const (
+	textOutput = "text"
+	jsonOutput = "json"
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -50,13 +61,32 @@ func Execute() {
 		if rootCmd.SilenceErrors {
 			fmt.Println(err)
 		}
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// exitCodeError is returned by commands exiting with a code other than 1.
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitCodeError) Unwrap() error {
+	return e.err
+}
+
 func init() {
 	cobra.OnInitialize(onBootOnDie)
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logger")
+	parallel.RegisterFlags(rootCmd.PersistentFlags())
 	buildah.RegisterRootCommand(rootCmd)
 
 	groups := templates.CommandGroups{
@@ -66,8 +96,11 @@ func init() {
 				newApplyCmd(),
 				newCertCmd(),
 				newRunCmd(),
+				newUninstallCmd(),
 				newResetCmd(),
 				newStatusCmd(),
+				newCheckCmd(),
+				newLogsCmd(),
 			},
 		},
 		{
@@ -82,6 +115,7 @@ func init() {
 			Commands: []*cobra.Command{
 				newExecCmd(),
 				newScpCmd(),
+				newSSHKeysCmd(),
 			},
 		},
 		{
@@ -107,6 +141,50 @@ func setRequireBuildahAnnotation(cmd *cobra.Command) {
 	buildah.SetRequireBuildahAnnotation(cmd)
 }
 
+func registerEventOutputFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&eventOutput, "output", "o", textOutput,
+		fmt.Sprintf("output format, one of '%s' or '%s', '%s' prints the event stream as JSON lines to stdout and logs to stderr", textOutput, jsonOutput, jsonOutput))
+}
+
+// reservedStdout is the duplicate of stdout returned by reserveStdout.
+var reservedStdout *os.File
+
+// reserveStdout keeps stdout for the machine-readable output only. It returns a duplicate of stdout,
+// then stdout is redirected to stderr, so that the logs and outputs of commands, including the ones of
+// third party packages and child processes, never break the output written to the duplicate.
+func reserveStdout() (*os.File, error) {
+	if reservedStdout != nil {
+		return reservedStdout, nil
+	}
+	fd, err := unix.Dup(int(os.Stdout.Fd()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to duplicate stdout: %v", err)
+	}
+	if err = unix.Dup3(int(os.Stderr.Fd()), int(os.Stdout.Fd()), 0); err != nil {
+		_ = unix.Close(fd)
+		return nil, fmt.Errorf("failed to redirect stdout to stderr: %v", err)
+	}
+	reservedStdout = os.NewFile(uintptr(fd), "stdout")
+	return reservedStdout, nil
+}
+
+// setupEventOutput keeps stdout for the event stream only.
+func setupEventOutput() error {
+	switch eventOutput {
+	case "", textOutput:
+		return nil
+	case jsonOutput:
+		out, err := reserveStdout()
+		if err != nil {
+			return fmt.Errorf("failed to reserve stdout for events: %v", err)
+		}
+		events.SetOutput(out)
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %s, available formats are: %s, %s", eventOutput, textOutput, jsonOutput)
+	}
+}
+
 func onBootOnDie() {
 	val, err := system.Get(system.DataRootConfigKey)
 	errExit(err)
@@ -120,6 +198,7 @@ func onBootOnDie() {
 		constants.WorkDir(),
 	}
 	errExit(file.MkDirs(rootDirs...))
+	errExit(setupEventOutput())
 
 	logger.CfgConsoleAndFileLogger(debug, constants.LogPath(), "sealos", false)
 	sreglog.CfgConsoleAndFileLogger(debug, constants.LogPath(), "sealos", false)
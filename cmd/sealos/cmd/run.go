@@ -65,6 +65,9 @@ func newRunCmd() *cobra.Command {
 		Long:    `sealos run labring/kubernetes:v1.24.0 --masters [arg] --nodes [arg]`,
 		Example: exampleRun,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := setupPlanOutput(cmd, runArgs.DryRun); err != nil {
+				return err
+			}
 			images, err := buildah.PreloadIfTarFile(args, transport)
 			if err != nil {
 				return err
@@ -85,6 +88,7 @@ func newRunCmd() *cobra.Command {
 	}
 	setRequireBuildahAnnotation(runCmd)
 	runArgs.RegisterFlags(runCmd.Flags())
+	registerEventOutputFlag(runCmd)
 	runCmd.Flags().BoolVar(new(bool), "single", false, "run cluster in single mode")
 	if err := runCmd.Flags().MarkDeprecated("single", "it defaults to running cluster in single mode when there are no master and node"); err != nil {
 		logger.Fatal(err)
@@ -20,13 +20,13 @@ import (
 	"context"
 
 	"github.com/spf13/cobra"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/ssh"
 	"github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 const exampleScp = `
@@ -71,7 +71,7 @@ func runCopy(cluster *v1beta1.Cluster, targets []string, args []string) error {
 	if err != nil {
 		return err
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, ipAddr := range targets {
 		ip := ipAddr
 		eg.Go(func() error {
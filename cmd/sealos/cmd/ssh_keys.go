@@ -0,0 +1,146 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const exampleSSHKeys = `
+list the pinned host keys of default cluster:
+	sealos ssh-keys list
+trust the current host keys of hosts after they are reinstalled:
+	sealos ssh-keys rotate -c my-cluster 192.168.0.2:22 192.168.0.3:22
+trust the current host keys of all nodes:
+	sealos ssh-keys rotate -c my-cluster -r node
+`
+
+func newSSHKeysCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "ssh-keys",
+		Short:   "Manage the host keys pinned in known_hosts of cluster",
+		Example: exampleSSHKeys,
+	}
+	cmd.AddCommand(newSSHKeysListCmd())
+	cmd.AddCommand(newSSHKeysRotateCmd())
+	return cmd
+}
+
+func newSSHKeysListCmd() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the pinned host keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hosts, err := ssh.NewKnownHosts(constants.KnownHostsFile(clusterName)).List()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "HOST\tTYPE\tFINGERPRINT")
+			for _, h := range hosts {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", strings.Join(h.Hosts, ","), h.Type, h.Fingerprint)
+			}
+			return w.Flush()
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to list host keys")
+	return cmd
+}
+
+func newSSHKeysRotateCmd() *cobra.Command {
+	var (
+		roles   []string
+		cluster *v2.Cluster
+	)
+	var cmd = &cobra.Command{
+		Use:   "rotate [HOST...]",
+		Short: "Replace the pinned host keys with the current ones of hosts",
+		Long: "Remove the pinned host keys of hosts and pin the ones they present now, " +
+			"all hosts of cluster are rotated if neither hosts nor roles are specified.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return rotateHostKeys(cluster, getTargets(cluster, args, roles))
+		},
+		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
+			cluster, err = clusterfile.GetClusterFromName(clusterName)
+			return
+		},
+	}
+	cmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to rotate host keys")
+	cmd.Flags().StringSliceVarP(&roles, "roles", "r", []string{}, "rotate host keys of nodes with role or in host group")
+	return cmd
+}
+
+// normalizeTargets completes the port of targets with the one of the host of cluster with the same ip,
+// so that the host keys of the same host:port are removed and pinned again.
+func normalizeTargets(cluster *v2.Cluster, targets []string) []string {
+	ret := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if !strings.Contains(target, ":") {
+			for _, host := range cluster.GetAllIPS() {
+				if iputils.GetHostIP(host) == target {
+					target = host
+					break
+				}
+			}
+		}
+		ret = append(ret, target)
+	}
+	return ret
+}
+
+func rotateHostKeys(cluster *v2.Cluster, targets []string) error {
+	targets = normalizeTargets(cluster, targets)
+	knownHosts := ssh.NewKnownHosts(constants.KnownHostsFile(cluster.Name))
+	if _, err := knownHosts.Remove(targets...); err != nil {
+		return fmt.Errorf("failed to remove host keys from %s: %v", knownHosts.Path(), err)
+	}
+
+	// connect in accept-new mode to pin the current host keys
+	cluster = cluster.DeepCopy()
+	cluster.Spec.SSH.HostKeyChecking = v2.HostKeyCheckingAcceptNew
+	for i := range cluster.Spec.Hosts {
+		if cluster.Spec.Hosts[i].SSH != nil {
+			cluster.Spec.Hosts[i].SSH.HostKeyChecking = v2.HostKeyCheckingAcceptNew
+		}
+	}
+	var hosts []string
+	for _, target := range targets {
+		if slices.Contains(cluster.GetAllIPS(), target) {
+			hosts = append(hosts, target)
+		} else {
+			// e.g. the jump hosts, which are pinned again once connected through
+			logger.Info("host key of %s is removed, it is not a host of cluster %s", target, cluster.Name)
+		}
+	}
+	if err := ssh.WaitReady(ssh.NewCacheClientFromCluster(cluster, false), 0, hosts...); err != nil {
+		return err
+	}
+	logger.Info("host keys of %v are rotated", hosts)
+	return nil
+}
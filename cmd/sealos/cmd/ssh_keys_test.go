@@ -0,0 +1,35 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func Test_normalizeTargets(t *testing.T) {
+	cluster := &v2.Cluster{}
+	cluster.Spec.Hosts = []v2.Host{
+		{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.MASTER}},
+		{IPS: []string{"192.168.0.3:2222"}, Roles: []string{v2.NODE}},
+	}
+	targets := []string{"192.168.0.2", "192.168.0.3", "192.168.0.3:2222", "192.168.0.100"}
+	want := []string{"192.168.0.2:22", "192.168.0.3:2222", "192.168.0.3:2222", "192.168.0.100"}
+	if got := normalizeTargets(cluster, targets); !reflect.DeepEqual(got, want) {
+		t.Errorf("normalizeTargets() = %v, want %v", got, want)
+	}
+}
@@ -16,28 +16,90 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/labring/sealos/pkg/checker"
 	"github.com/labring/sealos/pkg/clusterfile"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+const exampleStatus = `
+print the status of default cluster as a table:
+	sealos status
+print the status as JSON, and exit with code 2 if any component is not ok:
+	sealos status -o json --fail-on warning
+`
+
+// exitUnhealthy is the exit code of status if any component reaches the --fail-on level,
+// other failures of the command exit with code 1.
+const exitUnhealthy = 2
+
+type statusOptions struct {
+	output string
+	failOn string
+}
+
+func (o *statusOptions) registerFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&o.output, "output", "o", checker.OutputTable,
+		fmt.Sprintf("output format, one of '%s', '%s' or '%s'", checker.OutputTable, checker.OutputJSON, checker.OutputYAML))
+	fs.StringVar(&o.failOn, "fail-on", checker.StatusError,
+		fmt.Sprintf("exit with code %d if any component is at this status or worse, one of '%s', '%s' or 'never'", exitUnhealthy, checker.StatusWarning, checker.StatusError))
+}
+
+// run checks the status of cluster with the reporters and prints the results.
+func (o *statusOptions) run(cluster *v2.Cluster, reporters []checker.Reporter) error {
+	// the flags are validated before the checkers run, which may take a while
+	switch o.output {
+	case checker.OutputTable, checker.OutputJSON, checker.OutputYAML:
+	default:
+		return fmt.Errorf("invalid --output %s, must be %s, %s or %s", o.output, checker.OutputTable, checker.OutputJSON, checker.OutputYAML)
+	}
+	if o.failOn != checker.StatusWarning && o.failOn != checker.StatusError && o.failOn != "never" {
+		return fmt.Errorf("invalid --fail-on %s, must be %s, %s or never", o.failOn, checker.StatusWarning, checker.StatusError)
+	}
+	out := os.Stdout
+	if o.output != checker.OutputTable {
+		// the checkers log to stdout while they run
+		var err error
+		if out, err = reserveStdout(); err != nil {
+			return fmt.Errorf("failed to reserve stdout for the results: %v", err)
+		}
+	}
+	results := checker.RunReports(reporters, cluster)
+	if err := checker.PrintResults(out, o.output, results); err != nil {
+		return err
+	}
+	worst := checker.WorstStatus(results)
+	if o.failOn == "never" || worst == checker.StatusOK || (worst == checker.StatusWarning && o.failOn == checker.StatusError) {
+		return nil
+	}
+	return &exitCodeError{code: exitUnhealthy, err: fmt.Errorf("status of cluster %s is %s", cluster.Name, worst)}
+}
+
 // newStatusCmd
 func newStatusCmd() *cobra.Command {
+	var opts statusOptions
 	checkCmd := &cobra.Command{
-		Use:   "status",
-		Short: "state of sealos",
-		Args:  cobra.NoArgs,
+		Use:     "status",
+		Short:   "state of sealos",
+		Example: exampleStatus,
+		Args:    cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cluster, err := clusterfile.GetClusterFromName(clusterName)
 			if err != nil {
 				return fmt.Errorf("get default cluster failed, %v", err)
 			}
-			list := []checker.Interface{checker.NewRegistryChecker(), checker.NewCRIShimChecker(), checker.NewCRICtlChecker(), checker.NewInitSystemChecker(), checker.NewNodeChecker(), checker.NewPodChecker(), checker.NewSvcChecker(), checker.NewClusterChecker()}
-			return checker.RunCheckList(list, cluster, checker.PhasePost)
+			return opts.run(cluster, postCheckers())
 		},
 	}
 	checkCmd.Flags().StringVarP(&clusterName, "cluster", "c", "default", "name of cluster to applied status action")
+	opts.registerFlags(checkCmd.Flags())
 	return checkCmd
 }
+
+func postCheckers() []checker.Reporter {
+	return []checker.Reporter{checker.NewRegistryChecker(), checker.NewCRIShimChecker(), checker.NewCRICtlChecker(), checker.NewInitSystemChecker(), checker.NewNodeChecker(), checker.NewPodChecker(), checker.NewSvcChecker(), checker.NewClusterChecker()}
+}
@@ -0,0 +1,88 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/labring/sealos/pkg/checker"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// loggingReporter writes to stdout while reporting, like the registry checker does.
+type loggingReporter struct {
+	results []checker.Result
+}
+
+func (r *loggingReporter) Check(*v2.Cluster, string) error { return nil }
+func (r *loggingReporter) Name() string                    { return "registry" }
+func (r *loggingReporter) Report(*v2.Cluster) ([]checker.Result, error) {
+	logger.Info("registry is running in local host")
+	fmt.Println("output of a child process")
+	return r.results, nil
+}
+
+func TestStatusOptions_run_json(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// stdout of the process is replaced with the pipe while running
+	stdout, err := unix.Dup(int(os.Stdout.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(stdout)
+	if err = unix.Dup3(int(w.Fd()), int(os.Stdout.Fd()), 0); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []checker.Result{{Node: "master0", Component: "registry", Status: checker.StatusOK}}
+	o := &statusOptions{output: checker.OutputJSON, failOn: checker.StatusError}
+	runErr := o.run(&v2.Cluster{}, []checker.Reporter{&loggingReporter{results: want}})
+
+	// restore stdout and close all the writers of pipe before reading
+	if err = unix.Dup3(stdout, int(os.Stdout.Fd()), 0); err != nil {
+		t.Fatal(err)
+	}
+	if reservedStdout != nil {
+		reservedStdout.Close()
+		reservedStdout = nil
+	}
+	w.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("run() error = %v", runErr)
+	}
+	var got []checker.Result
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("failed to decode the output %q: %v", data, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("run() printed %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/apply"
+	"github.com/labring/sealos/pkg/apply/processor"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var exampleUninstall = `
+uninstall an application installed by sealos run:
+	sealos uninstall labring/helm-app:v1.0.0
+
+uninstall multiple applications without confirmation:
+	sealos uninstall labring/app-a:v1.0.0 labring/app-b:v1.0.0 --force
+
+the uninstall command of image is set by label in Kubefile:
+	LABEL apps.sealos.io/uninstall="helm uninstall app -n app-system"
+`
+
+func newUninstallCmd() *cobra.Command {
+	uninstallArgs := &apply.UninstallArgs{}
+	var uninstallCmd = &cobra.Command{
+		Use:     "uninstall",
+		Short:   "Uninstall applications from a cluster by running the uninstall command of images",
+		Example: exampleUninstall,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			applier, err := apply.NewApplierFromUninstallArgs(cmd, uninstallArgs)
+			if err != nil {
+				return err
+			}
+			return applier.Uninstall(args)
+		},
+		PostRun: func(cmd *cobra.Command, args []string) {
+			logger.Info(getContact())
+		},
+	}
+	setRequireBuildahAnnotation(uninstallCmd)
+	uninstallArgs.RegisterFlags(uninstallCmd.Flags())
+	registerEventOutputFlag(uninstallCmd)
+	uninstallCmd.Flags().BoolVarP(&processor.ForceUninstall, "force", "f", false, "uninstall apps without confirmation")
+	return uninstallCmd
+}
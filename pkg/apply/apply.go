@@ -50,7 +50,10 @@ func NewApplierFromFile(cmd *cobra.Command, path string, args *Args) (applydrive
 		return nil, fmt.Errorf("cluster name cannot be empty, make sure %s file is correct", path)
 	}
 
-	if err := CheckAndInitialize(cluster); err != nil {
+	if args.DryRun {
+		// CheckAndInitialize connects to the local host if no host is declared
+		setSSHDefaults(cluster)
+	} else if err := CheckAndInitialize(cluster); err != nil {
 		return nil, err
 	}
 
@@ -24,8 +24,6 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/labring/sealos/pkg/apply/processor"
 	"github.com/labring/sealos/pkg/client-go/kubernetes"
 	"github.com/labring/sealos/pkg/clusterfile"
@@ -33,10 +31,12 @@ import (
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/ssh"
 	"github.com/labring/sealos/pkg/system"
+	"github.com/labring/sealos/pkg/transcript"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/confirm"
 	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/yaml"
 )
 
@@ -84,7 +84,12 @@ type Applier struct {
 	RunNewImages       []string
 }
 
-func (c *Applier) Apply() error {
+func (c *Applier) Apply() (err error) {
+	if IsDryRun(c.Context) {
+		return c.printPlan()
+	}
+	finish := transcript.Start(c.ClusterDesired.Name)
+	defer func() { finish(err) }()
 	// clusterErr and appErr should not appear in the same time
 	var clusterErr, appErr error
 	defer func() {
@@ -95,19 +100,24 @@ func (c *Applier) Apply() error {
 		c.applyAfter()
 	}()
 	c.initStatus()
-	if c.ClusterCurrent == nil || c.ClusterCurrent.CreationTimestamp.IsZero() {
-		if !c.ClusterDesired.CreationTimestamp.IsZero() {
-			if yes, _ := confirm.Confirm("Desired cluster CreationTimestamp is not zero, do you want to initialize it again?", "you have canceled to create cluster"); !yes {
-				clusterErr = processor.NewPreProcessError(fmt.Errorf("canceled to create cluster"))
-				return clusterErr
+	resume := c.isResumable()
+	if resume || c.ClusterCurrent == nil || c.ClusterCurrent.CreationTimestamp.IsZero() {
+		if resume {
+			clusterErr = c.resumeCluster()
+		} else {
+			if !c.ClusterDesired.CreationTimestamp.IsZero() {
+				if yes, _ := confirm.Confirm("Desired cluster CreationTimestamp is not zero, do you want to initialize it again?", "you have canceled to create cluster"); !yes {
+					clusterErr = processor.NewPreProcessError(fmt.Errorf("canceled to create cluster"))
+					return clusterErr
+				}
 			}
+			clusterErr = c.initCluster()
 		}
-		clusterErr = c.initCluster()
 		if clusterErr != nil && processor.IsRunGuestFailed(clusterErr) {
 			appErr = errors.Unwrap(clusterErr)
 			clusterErr = nil
 		}
-		c.ClusterDesired.CreationTimestamp = metav1.Now()
+		c.finishCreation(clusterErr)
 	} else {
 		clusterErr, appErr = c.reconcileCluster()
 		c.ClusterDesired.CreationTimestamp = c.ClusterCurrent.CreationTimestamp
@@ -133,6 +143,11 @@ func (c *Applier) getWriteBackObjects() []interface{} {
 			obj = append(obj, configs[i])
 		}
 	}
+	if plugins := c.ClusterFile.GetPlugins(); len(plugins) > 0 {
+		for i := range plugins {
+			obj = append(obj, plugins[i])
+		}
+	}
 	return obj
 }
 
@@ -143,7 +158,6 @@ func (c *Applier) initStatus() {
 	}
 }
 
-// todo: atomic updating status after each installation for better reconcile?
 // todo: set up signal handler
 func (c *Applier) updateStatus(clusterErr error, appErr error) {
 	switch clusterErr.(type) {
@@ -188,12 +202,57 @@ func (c *Applier) reconcileCluster() (clusterErr error, appErr error) {
 	}
 	mj, md := iputils.GetDiffHosts(c.ClusterCurrent.GetMasterIPAndPortList(), c.ClusterDesired.GetMasterIPAndPortList())
 	nj, nd := iputils.GetDiffHosts(c.ClusterCurrent.GetNodeIPAndPortList(), c.ClusterDesired.GetNodeIPAndPortList())
-	return c.scaleCluster(mj, md, nj, nd), nil
+	if clusterErr = c.scaleCluster(mj, md, nj, nd); clusterErr != nil {
+		return clusterErr, nil
+	}
+	return processor.SyncNodeLabels(c.ClusterCurrent, c.ClusterDesired, c.ClusterFile.GetRuntimeConfig()), nil
+}
+
+// isResumable returns true if the creation of current cluster was interrupted,
+// or the operator asks to run the create pipeline from a specified phase.
+func (c *Applier) isResumable() bool {
+	if c.ClusterCurrent == nil {
+		return false
+	}
+	if c.Context != nil && processor.GetPhaseOptions(c.Context).From != "" {
+		return true
+	}
+	// the creation timestamp is only set once the creation is finished
+	return c.ClusterCurrent.CreationTimestamp.IsZero() && len(c.ClusterCurrent.Status.CompletedPhases) > 0
+}
+
+// finishCreation marks the cluster as created if the creation succeeded, the completed phases
+// are only kept for resuming an unfinished creation.
+func (c *Applier) finishCreation(clusterErr error) {
+	if clusterErr != nil {
+		c.ClusterDesired.CreationTimestamp = metav1.Time{}
+		return
+	}
+	c.ClusterDesired.Status.CompletedPhases = nil
+	if c.ClusterCurrent != nil && !c.ClusterCurrent.CreationTimestamp.IsZero() {
+		c.ClusterDesired.CreationTimestamp = c.ClusterCurrent.CreationTimestamp
+		return
+	}
+	c.ClusterDesired.CreationTimestamp = metav1.Now()
+}
+
+func (c *Applier) resumeCluster() error {
+	logger.Info("Start to resume the creation of cluster, completed phases: %v", c.ClusterCurrent.Status.CompletedPhases)
+	// the status of desired cluster may come from a Clusterfile without status
+	c.ClusterDesired.Status.Mounts = c.ClusterCurrent.Status.Mounts
+	c.ClusterDesired.Status.CompletedPhases = c.ClusterCurrent.Status.CompletedPhases
+	return c.createCluster()
 }
 
 func (c *Applier) initCluster() error {
 	logger.Info("Start to create a new cluster: master %s, worker %s, registry %s", c.ClusterDesired.GetMasterIPList(), c.ClusterDesired.GetNodeIPList(), c.ClusterDesired.GetRegistryIP())
-	createProcessor, err := processor.NewCreateProcessor(c.Context, c.ClusterDesired.Name, c.ClusterFile)
+	c.ClusterDesired.Status.CompletedPhases = nil
+	return c.createCluster()
+}
+
+func (c *Applier) createCluster() error {
+	ctx := processor.WithCheckpoint(c.Context, func(*v2.Cluster) { c.saveClusterFile() })
+	createProcessor, err := processor.NewCreateProcessor(ctx, c.ClusterDesired.Name, c.ClusterFile)
 	if err != nil {
 		return err
 	}
@@ -245,7 +304,9 @@ func (c *Applier) scaleCluster(mj, md, nj, nd []string) error {
 	return nil
 }
 
-func (c *Applier) Delete() error {
+func (c *Applier) Delete() (err error) {
+	finish := transcript.Start(c.ClusterDesired.Name)
+	defer func() { finish(err) }()
 	t := metav1.Now()
 	c.ClusterDesired.DeletionTimestamp = &t
 	defer func() {
@@ -260,6 +321,35 @@ func (c *Applier) Delete() error {
 	return c.deleteCluster()
 }
 
+// Uninstall removes the images installed in the cluster, and records the result into command conditions.
+func (c *Applier) Uninstall(images []string) (err error) {
+	finish := transcript.Start(c.ClusterDesired.Name)
+	defer func() { finish(err) }()
+	if c.ClusterCurrent == nil || c.ClusterCurrent.CreationTimestamp.IsZero() {
+		return fmt.Errorf("cluster %s is not exist", c.ClusterDesired.Name)
+	}
+	uninstallProcessor, err := processor.NewUninstallProcessor(c.ClusterFile, c.ClusterDesired.Name, images)
+	if err != nil {
+		return err
+	}
+	logger.Info("start to uninstall %v from this cluster", images)
+	err = uninstallProcessor.Execute(c.ClusterDesired)
+	if errors.Is(err, processor.ErrCancelled) {
+		return nil
+	}
+	var cmdCondition v2.CommandCondition
+	if err != nil {
+		cmdCondition = v2.NewFailedUninstallCommandCondition(err.Error())
+	} else {
+		cmdCondition = v2.NewSuccessUninstallCommandCondition()
+		logger.Info("succeeded in uninstalling %v", images)
+	}
+	cmdCondition.Images = images
+	c.ClusterDesired.Status.CommandConditions = v2.UpdateCommandCondition(c.ClusterDesired.Status.CommandConditions, cmdCondition)
+	c.saveClusterFile()
+	return err
+}
+
 func (c *Applier) deleteCluster() error {
 	deleteProcessor, err := processor.NewDeleteProcessor(c.ClusterDesired.Name, c.ClusterFile)
 	if err != nil {
@@ -288,7 +378,7 @@ func (c *Applier) syncWorkdir() {
 	if err != nil {
 		logger.Error("failed to create ssh client: %v", err)
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, ipAddr := range ipList {
 		ip := ipAddr
 		eg.Go(func() error {
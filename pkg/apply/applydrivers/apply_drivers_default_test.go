@@ -0,0 +1,94 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applydrivers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/labring/sealos/pkg/apply/processor"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestApplier_isResumable(t *testing.T) {
+	newCluster := func(created bool, completed ...string) *v2.Cluster {
+		cluster := newTestCluster([]string{"192.168.0.2:22"}, nil, "labring/kubernetes:v1.25.0")
+		if created {
+			cluster.CreationTimestamp = metav1.Now()
+		}
+		cluster.Status.CompletedPhases = completed
+		return cluster
+	}
+	tests := []struct {
+		name    string
+		current *v2.Cluster
+		from    string
+		want    bool
+	}{
+		{name: "new cluster"},
+		{name: "interrupted creation", current: newCluster(false, processor.PhaseMountRootfs, processor.PhaseInit), want: true},
+		{name: "failed before any phase completed", current: newCluster(false)},
+		{name: "created cluster", current: newCluster(true)},
+		// RunGuest failures do not fail the creation, the phases after it are never completed
+		{name: "created cluster with run guest failed", current: newCluster(true, processor.PhaseInit, processor.PhaseJoin)},
+		{name: "from phase", current: newCluster(true), from: processor.PhaseJoin, want: true},
+		{name: "from phase without cluster", from: processor.PhaseJoin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := processor.WithPhaseOptions(context.Background(), processor.PhaseOptions{From: tt.from})
+			c := &Applier{Context: ctx, ClusterDesired: newCluster(false), ClusterCurrent: tt.current}
+			if got := c.isResumable(); got != tt.want {
+				t.Errorf("isResumable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplier_finishCreation(t *testing.T) {
+	created := metav1.NewTime(metav1.Now().Add(-1e9))
+	tests := []struct {
+		name          string
+		current       *v2.Cluster
+		err           error
+		wantCreated   bool
+		wantTimestamp *metav1.Time
+		wantCompleted int
+	}{
+		{name: "failed", err: errors.New("failed to join"), wantCompleted: 2},
+		{name: "succeeded", wantCreated: true},
+		{name: "resumed from phase", current: &v2.Cluster{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: created}}, wantCreated: true, wantTimestamp: &created},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := &v2.Cluster{}
+			desired.Status.CompletedPhases = []string{processor.PhaseMountRootfs, processor.PhaseInit}
+			c := &Applier{ClusterDesired: desired, ClusterCurrent: tt.current}
+			c.finishCreation(tt.err)
+			if created := !desired.CreationTimestamp.IsZero(); created != tt.wantCreated {
+				t.Errorf("finishCreation() created = %v, want %v", created, tt.wantCreated)
+			}
+			if tt.wantTimestamp != nil && !desired.CreationTimestamp.Equal(tt.wantTimestamp) {
+				t.Errorf("finishCreation() timestamp = %v, want %v", desired.CreationTimestamp, tt.wantTimestamp)
+			}
+			if len(desired.Status.CompletedPhases) != tt.wantCompleted {
+				t.Errorf("finishCreation() completed = %v, want %d phases", desired.Status.CompletedPhases, tt.wantCompleted)
+			}
+		})
+	}
+}
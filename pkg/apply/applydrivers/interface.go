@@ -17,4 +17,5 @@ package applydrivers
 type Interface interface {
 	Apply() error
 	Delete() error
+	Uninstall(images []string) error
 }
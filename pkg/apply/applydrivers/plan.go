@@ -0,0 +1,161 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applydrivers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/labring/sealos/pkg/config"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/yaml"
+)
+
+const (
+	PlanActionCreate    = "create"
+	PlanActionResume    = "resume"
+	PlanActionReconcile = "reconcile"
+)
+
+// Plan describes the changes an apply would make to the cluster.
+type Plan struct {
+	Cluster         string   `json:"cluster"`
+	Action          string   `json:"action"`
+	MastersToJoin   []string `json:"mastersToJoin,omitempty"`
+	MastersToDelete []string `json:"mastersToDelete,omitempty"`
+	NodesToJoin     []string `json:"nodesToJoin,omitempty"`
+	NodesToDelete   []string `json:"nodesToDelete,omitempty"`
+	// ImagesToMount are the images mounted locally and copied to the hosts.
+	ImagesToMount []string `json:"imagesToMount,omitempty"`
+	// ImagesToRun are the images whose CMD will be executed.
+	ImagesToRun []string     `json:"imagesToRun,omitempty"`
+	ConfigFiles []PlanConfig `json:"configFiles,omitempty"`
+	// RegistrySyncTargets are the registry hosts the images are synced to.
+	RegistrySyncTargets []string `json:"registrySyncTargets,omitempty"`
+}
+
+// PlanConfig is a config file written into the rootfs of an image.
+type PlanConfig struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	Path     string `json:"path"`
+	Strategy string `json:"strategy"`
+}
+
+type dryRunKey struct{}
+
+// WithDryRun makes the applier print the plan instead of executing it.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+func IsDryRun(ctx context.Context) bool {
+	v, _ := ctx.Value(dryRunKey{}).(bool)
+	return v
+}
+
+type planOutputKey struct{}
+
+// WithPlanOutput sets where the plan is printed, it defaults to stdout.
+func WithPlanOutput(ctx context.Context, out io.Writer) context.Context {
+	return context.WithValue(ctx, planOutputKey{}, out)
+}
+
+func getPlanOutput(ctx context.Context) io.Writer {
+	if out, ok := ctx.Value(planOutputKey{}).(io.Writer); ok {
+		return out
+	}
+	return os.Stdout
+}
+
+// Plan computes the changes from current cluster to desired cluster,
+// only the local Clusterfiles are read and none of the hosts is touched.
+func (c *Applier) Plan() *Plan {
+	plan := &Plan{Cluster: c.ClusterDesired.Name}
+	switch {
+	case c.isResumable():
+		plan.Action = PlanActionResume
+	case c.ClusterCurrent == nil || c.ClusterCurrent.CreationTimestamp.IsZero():
+		plan.Action = PlanActionCreate
+	default:
+		plan.Action = PlanActionReconcile
+	}
+
+	var mounts []v2.MountImage
+	if plan.Action == PlanActionReconcile {
+		plan.MastersToJoin, plan.MastersToDelete = iputils.GetDiffHosts(c.ClusterCurrent.GetMasterIPAndPortList(), c.ClusterDesired.GetMasterIPAndPortList())
+		plan.NodesToJoin, plan.NodesToDelete = iputils.GetDiffHosts(c.ClusterCurrent.GetNodeIPAndPortList(), c.ClusterDesired.GetNodeIPAndPortList())
+		plan.ImagesToMount = c.RunNewImages
+		plan.ImagesToRun = c.RunNewImages
+		mounts = c.ClusterCurrent.Status.Mounts
+	} else {
+		plan.MastersToJoin = c.ClusterDesired.GetMasterIPAndPortList()
+		plan.NodesToJoin = c.ClusterDesired.GetNodeIPAndPortList()
+		plan.ImagesToMount = c.ClusterDesired.Spec.Image
+		plan.ImagesToRun = c.ClusterDesired.Spec.Image
+		if c.ClusterCurrent != nil {
+			mounts = c.ClusterCurrent.Status.Mounts
+		}
+	}
+
+	if len(plan.ImagesToMount) > 0 {
+		plan.RegistrySyncTargets = c.ClusterDesired.GetRegistryIPAndPortList()
+	}
+	for _, img := range plan.ImagesToMount {
+		for _, cfg := range c.ClusterFile.GetConfigs() {
+			if !config.IsMatched(cfg, img) {
+				continue
+			}
+			plan.ConfigFiles = append(plan.ConfigFiles, PlanConfig{
+				Name:     cfg.Name,
+				Image:    img,
+				Path:     configPath(mounts, img, cfg.Spec.Path),
+				Strategy: configStrategy(cfg.Spec.Strategy),
+			})
+		}
+	}
+	return plan
+}
+
+// configPath returns the absolute path if the image has been mounted before,
+// otherwise the path relative to the rootfs of image.
+func configPath(mounts []v2.MountImage, imageName, path string) string {
+	for _, m := range mounts {
+		if m.ImageName == imageName && m.MountPoint != "" {
+			return filepath.Join(m.MountPoint, path)
+		}
+	}
+	return path
+}
+
+func configStrategy(strategy v2.StrategyType) string {
+	if strategy == "" {
+		return string(v2.Override)
+	}
+	return string(strategy)
+}
+
+func (c *Applier) printPlan() error {
+	out, err := yaml.Marshal(c.Plan())
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %v", err)
+	}
+	_, err = getPlanOutput(c.Context).Write(out)
+	return err
+}
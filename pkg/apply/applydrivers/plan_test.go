@@ -0,0 +1,154 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package applydrivers
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/labring/sealos/pkg/clusterfile"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type fakeClusterFile struct {
+	clusterfile.Interface
+	configs []v2.Config
+}
+
+func (f *fakeClusterFile) GetConfigs() []v2.Config {
+	return f.configs
+}
+
+func newTestCluster(masters, nodes []string, images ...string) *v2.Cluster {
+	cluster := &v2.Cluster{}
+	cluster.Name = "default"
+	cluster.Spec.Hosts = []v2.Host{
+		{IPS: masters, Roles: []string{v2.MASTER}},
+		{IPS: nodes, Roles: []string{v2.NODE}},
+	}
+	cluster.Spec.Image = images
+	return cluster
+}
+
+func TestApplier_Plan(t *testing.T) {
+	configs := []v2.Config{
+		{ObjectMeta: metav1.ObjectMeta{Name: "calico"}, Spec: v2.ConfigSpec{Match: "labring/calico:v3.24.1", Path: "charts/calico/values.yaml", Strategy: v2.Merge}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "all"}, Spec: v2.ConfigSpec{Path: "etc/sealos.yaml"}},
+	}
+	current := newTestCluster([]string{"192.168.0.2:22"}, []string{"192.168.0.3:22"}, "labring/kubernetes:v1.25.0")
+	current.CreationTimestamp = metav1.Now()
+	current.Status.Mounts = []v2.MountImage{{ImageName: "labring/kubernetes:v1.25.0", MountPoint: "/var/lib/containers/storage/overlay/1/merged"}}
+
+	tests := []struct {
+		name    string
+		applier *Applier
+		want    *Plan
+	}{
+		{
+			name: "create",
+			applier: &Applier{
+				ClusterDesired: newTestCluster([]string{"192.168.0.2:22"}, []string{"192.168.0.3:22"}, "labring/kubernetes:v1.25.0", "labring/calico:v3.24.1"),
+				ClusterFile:    &fakeClusterFile{configs: configs},
+			},
+			want: &Plan{
+				Cluster:       "default",
+				Action:        PlanActionCreate,
+				MastersToJoin: []string{"192.168.0.2:22"},
+				NodesToJoin:   []string{"192.168.0.3:22"},
+				ImagesToMount: []string{"labring/kubernetes:v1.25.0", "labring/calico:v3.24.1"},
+				ImagesToRun:   []string{"labring/kubernetes:v1.25.0", "labring/calico:v3.24.1"},
+				ConfigFiles: []PlanConfig{
+					{Name: "all", Image: "labring/kubernetes:v1.25.0", Path: "etc/sealos.yaml", Strategy: "override"},
+					{Name: "calico", Image: "labring/calico:v3.24.1", Path: "charts/calico/values.yaml", Strategy: "merge"},
+					{Name: "all", Image: "labring/calico:v3.24.1", Path: "etc/sealos.yaml", Strategy: "override"},
+				},
+				RegistrySyncTargets: []string{"192.168.0.2:22"},
+			},
+		},
+		{
+			name: "scale",
+			applier: &Applier{
+				ClusterDesired: newTestCluster([]string{"192.168.0.2:22"}, []string{"192.168.0.4:22"}, "labring/kubernetes:v1.25.0"),
+				ClusterCurrent: current,
+				ClusterFile:    &fakeClusterFile{},
+			},
+			want: &Plan{
+				Cluster:       "default",
+				Action:        PlanActionReconcile,
+				NodesToJoin:   []string{"192.168.0.4:22"},
+				NodesToDelete: []string{"192.168.0.3:22"},
+			},
+		},
+		{
+			name: "run new app on mounted image",
+			applier: &Applier{
+				ClusterDesired: newTestCluster([]string{"192.168.0.2:22"}, []string{"192.168.0.3:22"}, "labring/kubernetes:v1.25.0"),
+				ClusterCurrent: current,
+				ClusterFile:    &fakeClusterFile{configs: configs[1:]},
+				RunNewImages:   []string{"labring/kubernetes:v1.25.0"},
+			},
+			want: &Plan{
+				Cluster:       "default",
+				Action:        PlanActionReconcile,
+				ImagesToMount: []string{"labring/kubernetes:v1.25.0"},
+				ImagesToRun:   []string{"labring/kubernetes:v1.25.0"},
+				ConfigFiles: []PlanConfig{
+					{Name: "all", Image: "labring/kubernetes:v1.25.0", Path: "/var/lib/containers/storage/overlay/1/merged/etc/sealos.yaml", Strategy: "override"},
+				},
+				RegistrySyncTargets: []string{"192.168.0.2:22"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.applier.Plan(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Plan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDryRun(t *testing.T) {
+	if IsDryRun(context.Background()) {
+		t.Errorf("IsDryRun() = true, want false")
+	}
+	if !IsDryRun(WithDryRun(context.Background())) {
+		t.Errorf("IsDryRun() = false, want true")
+	}
+}
+
+func TestApplier_Apply_dryRun(t *testing.T) {
+	var out bytes.Buffer
+	c := &Applier{
+		Context:        WithPlanOutput(WithDryRun(context.Background()), &out),
+		ClusterDesired: newTestCluster([]string{"192.168.0.2:22"}, nil, "labring/kubernetes:v1.25.0"),
+		ClusterFile:    &fakeClusterFile{},
+	}
+	if err := c.Apply(); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	var got Plan
+	if err := yaml.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode plan %q: %v", out.String(), err)
+	}
+	if !reflect.DeepEqual(&got, c.Plan()) {
+		t.Errorf("printed plan = %+v, want %+v", got, c.Plan())
+	}
+}
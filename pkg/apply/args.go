@@ -23,6 +23,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/labring/sealos/pkg/constants"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 )
 
 type Cluster struct {
@@ -43,6 +44,10 @@ type SSH struct {
 	Pk         string
 	PkPassword string
 	Port       uint16
+	ProxyJump  string
+	Agent      string
+	// HostKeyChecking is the mode to verify host keys, one of strict, accept-new and insecure
+	HostKeyChecking string
 }
 
 func (s *SSH) RegisterFlags(fs *pflag.FlagSet) {
@@ -52,6 +57,13 @@ func (s *SSH) RegisterFlags(fs *pflag.FlagSet) {
 		"selects a file from which the identity (private key) for public key authentication is read")
 	fs.StringVar(&s.PkPassword, "pk-passwd", "", "passphrase for decrypting a PEM encoded private key")
 	fs.Uint16Var(&s.Port, "port", 22, "port to connect to on the remote host")
+	fs.StringVarP(&s.ProxyJump, "proxy-jump", "J", "",
+		"comma separated jump hosts in format [user@]host[:port] to connect to the remote host through")
+	fs.StringVar(&s.Agent, "ssh-agent", "", "socket of ssh-agent to authenticate with, $SSH_AUTH_SOCK is used if no value is given")
+	fs.Lookup("ssh-agent").NoOptDefVal = "$SSH_AUTH_SOCK"
+	fs.StringVar(&s.HostKeyChecking, "host-key-checking", "",
+		fmt.Sprintf("mode to verify host keys with the known_hosts of cluster, one of '%s', '%s' or '%s', defaults to '%s'",
+			v2.HostKeyCheckingStrict, v2.HostKeyCheckingAcceptNew, v2.HostKeyCheckingInsecure, v2.HostKeyCheckingAcceptNew))
 }
 
 type RunArgs struct {
@@ -60,6 +72,9 @@ type RunArgs struct {
 	CustomEnv         []string
 	CustomCMD         []string
 	CustomConfigFiles []string
+	DryRun            bool
+	FromPhase         string
+	SkipPhases        []string
 }
 
 func (arg *RunArgs) RegisterFlags(fs *pflag.FlagSet) {
@@ -68,6 +83,8 @@ func (arg *RunArgs) RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVarP(&arg.CustomEnv, "env", "e", []string{}, "environment variables to be set for images")
 	fs.StringSliceVar(&arg.CustomCMD, "cmd", []string{}, "override CMD directive in images")
 	fs.StringSliceVar(&arg.CustomConfigFiles, "config-file", []string{}, "path of custom config files, to use to replace the resource")
+	fs.BoolVar(&arg.DryRun, "dry-run", false, "print the plan of changes without touching any host")
+	registerPhaseFlags(fs, &arg.FromPhase, &arg.SkipPhases)
 }
 
 type Args struct {
@@ -75,6 +92,9 @@ type Args struct {
 	Sets              []string
 	CustomEnv         []string
 	CustomConfigFiles []string
+	DryRun            bool
+	FromPhase         string
+	SkipPhases        []string
 }
 
 func (arg *Args) RegisterFlags(fs *pflag.FlagSet) {
@@ -82,6 +102,13 @@ func (arg *Args) RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&arg.Sets, "set", []string{}, "set values on the command line")
 	fs.StringSliceVar(&arg.CustomEnv, "env", []string{}, "environment variables to be set for images")
 	fs.StringSliceVar(&arg.CustomConfigFiles, "config-file", []string{}, "path of custom config files, to use to replace the resource")
+	fs.BoolVar(&arg.DryRun, "dry-run", false, "print the plan of changes without touching any host")
+	registerPhaseFlags(fs, &arg.FromPhase, &arg.SkipPhases)
+}
+
+func registerPhaseFlags(fs *pflag.FlagSet, from *string, skips *[]string) {
+	fs.StringVar(from, "from-phase", "", "run the create pipeline from the specified phase, the phases before it are skipped")
+	fs.StringSliceVar(skips, "skip-phase", nil, "skip the specified phases of the create pipeline")
 }
 
 type ResetArgs struct {
@@ -94,6 +121,14 @@ func (arg *ResetArgs) RegisterFlags(fs *pflag.FlagSet) {
 	arg.SSH.RegisterFlags(fs)
 }
 
+type UninstallArgs struct {
+	ClusterName string
+}
+
+func (arg *UninstallArgs) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&arg.ClusterName, "cluster", "c", "default", "name of cluster to applied uninstall action")
+}
+
 type ScaleArgs struct {
 	*Cluster
 	*SSH
@@ -28,6 +28,7 @@ import (
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/filesystem/rootfs"
 	"github.com/labring/sealos/pkg/guest"
+	"github.com/labring/sealos/pkg/plugin"
 	"github.com/labring/sealos/pkg/runtime"
 	"github.com/labring/sealos/pkg/runtime/factory"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
@@ -42,41 +43,40 @@ type CreateProcessor struct {
 	Runtime     runtime.Interface
 	Guest       guest.Interface
 	ExtraEnvs   map[string]string // parsing from CLI arguments
+	Phases      PhaseOptions
+	ctx         context.Context
 }
 
 func (c *CreateProcessor) Execute(cluster *v2.Cluster) error {
-	pipeLine, err := c.GetPipeLine()
-	if err != nil {
-		return err
-	}
-	for _, f := range pipeLine {
-		if err = f(cluster); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return runPhases(c.ctx, "CreateProcessor", cluster, c.GetPhases(), c.Phases.From, c.Phases.Skips)
 }
 
-func (c *CreateProcessor) GetPipeLine() ([]func(cluster *v2.Cluster) error, error) {
-	var todoList []func(cluster *v2.Cluster) error
-	todoList = append(todoList,
-		// c.GetPhasePluginFunc(plugin.PhaseOriginally),
-		c.Check,
-		c.PreProcess,
-		c.RunConfig,
-		c.MountRootfs,
-		c.MirrorRegistry,
-		c.Bootstrap,
-		// c.GetPhasePluginFunc(plugin.PhasePreInit),
-		c.Init,
-		c.Join,
-		// c.GetPhasePluginFunc(plugin.PhasePreGuest),
-		c.RunGuest,
-		// c.GetPhasePluginFunc(plugin.PhasePostInstall),
-	)
+func (c *CreateProcessor) GetPhases() []Phase {
+	return []Phase{
+		{Name: PhaseCheck, Run: c.Check, Required: true},
+		// preflight is checkpointed, the ports are in use once the cluster is initialized
+		{Name: PhasePreflight, Run: c.Preflight},
+		{Name: PhasePreProcess, Run: c.PreProcess, Required: true},
+		{Name: PhaseRunConfig, Run: c.RunConfig, Required: true},
+		{Name: plugin.PhaseOriginally, Run: c.GetPhasePluginFunc(plugin.PhaseOriginally)},
+		{Name: PhaseMountRootfs, Run: c.MountRootfs},
+		{Name: PhaseMirrorRegistry, Run: c.MirrorRegistry},
+		{Name: PhaseBootstrap, Run: c.Bootstrap},
+		{Name: plugin.PhasePreInit, Run: c.GetPhasePluginFunc(plugin.PhasePreInit)},
+		{Name: PhaseInit, Run: c.Init},
+		{Name: PhaseJoin, Run: c.Join},
+		{Name: plugin.PhasePreGuest, Run: c.GetPhasePluginFunc(plugin.PhasePreGuest)},
+		{Name: PhaseRunGuest, Run: c.RunGuest},
+		{Name: plugin.PhasePostInstall, Run: c.GetPhasePluginFunc(plugin.PhasePostInstall)},
+	}
+}
 
-	return todoList, nil
+func (c *CreateProcessor) GetPhasePluginFunc(phase string) func(cluster *v2.Cluster) error {
+	return func(cluster *v2.Cluster) error {
+		logger.Info("Executing pipeline %s plugins in CreateProcessor.", phase)
+		hosts := append(cluster.GetMasterIPAndPortList(), cluster.GetNodeIPAndPortList()...)
+		return RunPlugins(cluster, c.ClusterFile.GetPlugins(), phase, hosts)
+	}
 }
 
 func (c *CreateProcessor) Check(cluster *v2.Cluster) error {
@@ -88,6 +88,12 @@ func (c *CreateProcessor) Check(cluster *v2.Cluster) error {
 	return NewCheckError(checker.RunCheckList([]checker.Interface{checker.NewIPsHostChecker(ips)}, cluster, checker.PhasePre))
 }
 
+func (c *CreateProcessor) Preflight(cluster *v2.Cluster) error {
+	logger.Info("Executing pipeline Preflight in CreateProcessor.")
+	hosts := append(cluster.GetMasterIPAndPortList(), cluster.GetNodeIPAndPortList()...)
+	return NewCheckError(checker.RunCheckList([]checker.Interface{checker.NewPreflightChecker(hosts)}, cluster, checker.PhasePre))
+}
+
 func (c *CreateProcessor) PreProcess(cluster *v2.Cluster) error {
 	logger.Info("Executing pipeline PreProcess in CreateProcessor.")
 	return NewPreProcessError(c.preProcess(cluster))
@@ -190,5 +196,7 @@ func NewCreateProcessor(ctx context.Context, name string, clusterFile clusterfil
 		Buildah:     bder,
 		Guest:       gs,
 		ExtraEnvs:   GetEnvs(ctx),
+		Phases:      GetPhaseOptions(ctx),
+		ctx:         ctx,
 	}, nil
 }
@@ -17,6 +17,8 @@ package processor
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"golang.org/x/sync/errgroup"
 
@@ -25,6 +27,7 @@ import (
 	"github.com/labring/sealos/pkg/clusterfile"
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/filesystem/rootfs"
+	"github.com/labring/sealos/pkg/plugin"
 	"github.com/labring/sealos/pkg/runtime/factory"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	fileutil "github.com/labring/sealos/pkg/utils/file"
@@ -46,27 +49,37 @@ func (d DeleteProcessor) Execute(cluster *v2.Cluster) (err error) {
 		return err
 	}
 	// TODO if error is exec net process ???
-	for _, f := range pipLine {
-		if err = f(cluster); err != nil {
+	for _, p := range pipLine {
+		if err = runPhase("DeleteProcessor", p, cluster); err != nil {
 			logger.Warn("failed to exec delete process, %s", err.Error())
 		}
 	}
 
 	return nil
 }
-func (d DeleteProcessor) GetPipeLine() ([]func(cluster *v2.Cluster) error, error) {
-	var todoList []func(cluster *v2.Cluster) error
+func (d DeleteProcessor) GetPipeLine() ([]Phase, error) {
+	var todoList []Phase
 	todoList = append(todoList,
-		d.PreProcess,
-		d.Reset,
-		d.UndoBootstrap,
-		d.UnMountRootfs,
-		d.UnMountImage,
-		d.CleanFS,
+		Phase{Name: PhasePreProcess, Run: d.PreProcess},
+		Phase{Name: plugin.PhasePreReset, Run: d.GetPhasePluginFunc(plugin.PhasePreReset)},
+		Phase{Name: "Reset", Run: d.Reset},
+		Phase{Name: "UndoBootstrap", Run: d.UndoBootstrap},
+		Phase{Name: plugin.PhasePostReset, Run: d.GetPhasePluginFunc(plugin.PhasePostReset)},
+		Phase{Name: "UnMountRootfs", Run: d.UnMountRootfs},
+		Phase{Name: "UnMountImage", Run: d.UnMountImage},
+		Phase{Name: "CleanFS", Run: d.CleanFS},
 	)
 	return todoList, nil
 }
 
+func (d DeleteProcessor) GetPhasePluginFunc(phase string) func(cluster *v2.Cluster) error {
+	return func(cluster *v2.Cluster) error {
+		logger.Info("Executing pipeline %s plugins in DeleteProcessor.", phase)
+		hosts := append(cluster.GetMasterIPAndPortList(), cluster.GetNodeIPAndPortList()...)
+		return RunPlugins(cluster, d.ClusterFile.GetPlugins(), phase, hosts)
+	}
+}
+
 func (d *DeleteProcessor) PreProcess(cluster *v2.Cluster) error {
 	return NewPreProcessError(SyncClusterStatus(cluster, d.Buildah, true))
 }
@@ -126,7 +139,18 @@ func (d *DeleteProcessor) UnMountImage(cluster *v2.Cluster) error {
 func (d *DeleteProcessor) CleanFS(cluster *v2.Cluster) error {
 	workDir := constants.ClusterDir(cluster.Name)
 	dataDir := constants.NewPathResolver(cluster.Name).Root()
-	return fileutil.CleanFiles(workDir, dataDir)
+	// keep the run logs, so that the failures of previous runs and this reset can still be inspected
+	entries, err := os.ReadDir(workDir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	files := []string{dataDir}
+	for _, entry := range entries {
+		if path := filepath.Join(workDir, entry.Name()); path != constants.RunLogsDir(cluster.Name) {
+			files = append(files, path)
+		}
+	}
+	return fileutil.CleanFiles(files...)
 }
 
 func NewDeleteProcessor(name string, clusterFile clusterfile.Interface) (Interface, error) {
@@ -57,8 +57,8 @@ func (c *InstallProcessor) Execute(cluster *v2.Cluster) error {
 		return err
 	}
 
-	for _, f := range pipLine {
-		if err = f(cluster); err != nil {
+	for _, p := range pipLine {
+		if err = runPhase("InstallProcessor", p, cluster); err != nil {
 			return err
 		}
 	}
@@ -66,19 +66,19 @@ func (c *InstallProcessor) Execute(cluster *v2.Cluster) error {
 	return nil
 }
 
-func (c *InstallProcessor) GetPipeLine() ([]func(cluster *v2.Cluster) error, error) {
-	var todoList []func(cluster *v2.Cluster) error
+func (c *InstallProcessor) GetPipeLine() ([]Phase, error) {
+	var todoList []Phase
 	todoList = append(todoList,
-		c.SyncStatusAndCheck,
-		c.ConfirmOverrideApps,
-		c.PreProcess,
-		c.RunConfig,
-		c.MountRootfs,
-		c.MirrorRegistry,
-		c.UpgradeIfNeed,
+		Phase{Name: "SyncStatusAndCheck", Run: c.SyncStatusAndCheck},
+		Phase{Name: "ConfirmOverrideApps", Run: c.ConfirmOverrideApps},
+		Phase{Name: PhasePreProcess, Run: c.PreProcess},
+		Phase{Name: PhaseRunConfig, Run: c.RunConfig},
+		Phase{Name: PhaseMountRootfs, Run: c.MountRootfs},
+		Phase{Name: PhaseMirrorRegistry, Run: c.MirrorRegistry},
+		Phase{Name: "UpgradeIfNeed", Run: c.UpgradeIfNeed},
 		// i.GetPhasePluginFunc(plugin.PhasePreGuest),
-		c.RunGuest,
-		c.PostProcess,
+		Phase{Name: PhaseRunGuest, Run: c.RunGuest},
+		Phase{Name: "PostProcess", Run: c.PostProcess},
 		// i.GetPhasePluginFunc(plugin.PhasePostInstall),
 	)
 	return todoList, nil
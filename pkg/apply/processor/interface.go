@@ -27,12 +27,18 @@ import (
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/filesystem/registry"
+	"github.com/labring/sealos/pkg/plugin"
+	"github.com/labring/sealos/pkg/registry/helpers"
+	"github.com/labring/sealos/pkg/runtime"
+	"github.com/labring/sealos/pkg/runtime/factory"
+	runtimeutils "github.com/labring/sealos/pkg/runtime/utils"
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/confirm"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
 	"github.com/labring/sealos/pkg/utils/maps"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/rand"
 )
 
@@ -153,7 +159,11 @@ func ConfirmDeleteNodes() error {
 }
 
 func MirrorRegistry(cluster *v2.Cluster, mounts []v2.MountImage) error {
-	registries := cluster.GetRegistryIPAndPortList()
+	return mirrorRegistry(cluster, mounts, cluster.GetRegistryIPAndPortList())
+}
+
+// mirrorRegistry syncs the images of mounts to the registries.
+func mirrorRegistry(cluster *v2.Cluster, mounts []v2.MountImage, registries []string) error {
 	logger.Debug("registry nodes is: %+v", registries)
 	sshClient := ssh.NewCacheClientFromCluster(cluster, true)
 	execer, err := exec.New(sshClient)
@@ -164,6 +174,65 @@ func MirrorRegistry(cluster *v2.Cluster, mounts []v2.MountImage) error {
 	return syncer.Sync(context.Background(), registries...)
 }
 
+// SyncRegistryReplicas updates the registry replicas of image-cri-shim on hosts, so that
+// image-cri-shim can fail over to the joined registries and stops probing the deleted ones.
+func SyncRegistryReplicas(cluster *v2.Cluster, hosts []string) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+	execer, err := exec.New(ssh.NewCacheClientFromCluster(cluster, true))
+	if err != nil {
+		return err
+	}
+	registries := cluster.GetRegistryIPAndPortList()
+	return parallel.ForEach(hosts, func(host string) error {
+		return helpers.SetImageCRIShimReplicas(execer, host, registries)
+	})
+}
+
+// RunPlugins executes the plugins declared in Clusterfile at the phase on the hosts.
+func RunPlugins(cluster *v2.Cluster, plugins []v2.Plugin, phase string, hosts []string) error {
+	if len(plugins) == 0 {
+		return nil
+	}
+	p, err := plugin.New(cluster, plugins)
+	if err != nil {
+		return err
+	}
+	return p.Run(phase, hosts...)
+}
+
+// SyncNodeLabels applies the changed labels and taints of hosts in both current and desired cluster,
+// the joining hosts are labeled by the runtime while they are joined.
+func SyncNodeLabels(current, desired *v2.Cluster, runtimeConfig runtime.Config) error {
+	var hosts []string
+	for _, ip := range desired.GetAllIPS() {
+		previous := current.GetHostByIP(ip)
+		if previous == nil {
+			continue
+		}
+		labels, taints, removedTaints, err := runtimeutils.NodeLabelArgs(desired.GetHostByIP(ip), previous)
+		if err != nil {
+			return err
+		}
+		if len(labels)+len(taints)+len(removedTaints) > 0 {
+			hosts = append(hosts, ip)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	cluster := desired.DeepCopy()
+	if cluster.Status.Mounts == nil {
+		cluster.Status.Mounts = current.Status.Mounts
+	}
+	rt, err := factory.New(cluster, runtimeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to init runtime, %v", err)
+	}
+	return rt.SyncNodeLabels(current.Spec.Hosts, hosts...)
+}
+
 func getIndexOfContainerInMounts(mounts []v2.MountImage, imageName string) int {
 	for idx, m := range mounts {
 		if m.ImageName == imageName {
@@ -0,0 +1,163 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/events"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	PhaseCheck          = "Check"
+	PhasePreflight      = "Preflight"
+	PhasePreProcess     = "PreProcess"
+	PhaseRunConfig      = "RunConfig"
+	PhaseMountRootfs    = "MountRootfs"
+	PhaseMirrorRegistry = "MirrorRegistry"
+	PhaseBootstrap      = "Bootstrap"
+	PhaseInit           = "Init"
+	PhaseJoin           = "Join"
+	PhaseRunGuest       = "RunGuest"
+)
+
+// Phase is a named step of a pipeline.
+type Phase struct {
+	Name string
+	Run  func(cluster *v2.Cluster) error
+	// Required phases only prepare the local state for the following phases,
+	// they are always executed and never recorded in the cluster status.
+	Required bool
+}
+
+type checkpointKey struct{}
+
+// WithCheckpoint sets the function which persists the cluster after each phase is completed.
+func WithCheckpoint(ctx context.Context, fn func(cluster *v2.Cluster)) context.Context {
+	return context.WithValue(ctx, checkpointKey{}, fn)
+}
+
+func GetCheckpoint(ctx context.Context) func(cluster *v2.Cluster) {
+	v := ctx.Value(checkpointKey{})
+	if v != nil {
+		return v.(func(cluster *v2.Cluster))
+	}
+	return nil
+}
+
+// PhaseOptions selects the phases of create pipeline to execute.
+type PhaseOptions struct {
+	// From is the phase to start from, the phases before it are skipped.
+	From string
+	// Skips are the phases that will not be executed, they are recorded as completed.
+	Skips []string
+}
+
+type phaseOptionsKey struct{}
+
+func WithPhaseOptions(ctx context.Context, opts PhaseOptions) context.Context {
+	return context.WithValue(ctx, phaseOptionsKey{}, opts)
+}
+
+func GetPhaseOptions(ctx context.Context) PhaseOptions {
+	v, _ := ctx.Value(phaseOptionsKey{}).(PhaseOptions)
+	return v
+}
+
+func validatePhases(phases []Phase, from string, skips []string) error {
+	names := make([]string, 0, len(phases))
+	for _, p := range phases {
+		names = append(names, p.Name)
+	}
+	for _, name := range append([]string{from}, skips...) {
+		if name == "" {
+			continue
+		}
+		idx := slices.Index(names, name)
+		if idx < 0 {
+			return fmt.Errorf("unknown phase %s, available phases are: %s", name, strings.Join(names, ","))
+		}
+		if phases[idx].Required && slices.Contains(skips, name) {
+			return fmt.Errorf("phase %s is required and cannot be skipped", name)
+		}
+	}
+	return nil
+}
+
+// runPhase executes the phase and emits its events, source is the name of the processor.
+func runPhase(source string, p Phase, cluster *v2.Cluster) error {
+	finish := events.StartPhase(source, p.Name, "")
+	err := p.Run(cluster)
+	finish(err)
+	return err
+}
+
+// runPhases executes the phases in order and records the completed ones into cluster status.
+// If from is empty, phases that have been completed in the previous execution are skipped.
+func runPhases(ctx context.Context, source string, cluster *v2.Cluster, phases []Phase, from string, skips []string) error {
+	if err := validatePhases(phases, from, skips); err != nil {
+		return err
+	}
+	var checkpoint func(cluster *v2.Cluster)
+	if ctx != nil {
+		checkpoint = GetCheckpoint(ctx)
+	}
+	reached := from == ""
+	for _, p := range phases {
+		if p.Name == from {
+			reached = true
+		}
+		if !p.Required {
+			var reason string
+			switch {
+			case !reached:
+				reason = fmt.Sprintf("it is before phase %s", from)
+			case slices.Contains(skips, p.Name):
+				reason = "it is specified to be skipped"
+				markPhaseCompleted(cluster, p.Name)
+			case from == "" && slices.Contains(cluster.Status.CompletedPhases, p.Name):
+				reason = "it has been completed"
+			}
+			if reason != "" {
+				logger.Info("Skip phase %s because %s.", p.Name, reason)
+				events.SkipPhase(source, p.Name, reason)
+				continue
+			}
+		}
+		if err := runPhase(source, p, cluster); err != nil {
+			return err
+		}
+		if p.Required {
+			continue
+		}
+		markPhaseCompleted(cluster, p.Name)
+		if checkpoint != nil {
+			checkpoint(cluster)
+		}
+	}
+	return nil
+}
+
+func markPhaseCompleted(cluster *v2.Cluster, name string) {
+	if !slices.Contains(cluster.Status.CompletedPhases, name) {
+		cluster.Status.CompletedPhases = append(cluster.Status.CompletedPhases, name)
+	}
+}
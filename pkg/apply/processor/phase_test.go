@@ -0,0 +1,182 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"golang.org/x/exp/slices"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func Test_runPhases(t *testing.T) {
+	tests := []struct {
+		name          string
+		completed     []string
+		from          string
+		skips         []string
+		failAt        string
+		wantExecuted  []string
+		wantCompleted []string
+		wantErr       bool
+	}{
+		{
+			name:          "fresh run",
+			wantExecuted:  []string{"Check", "Init", "Join", "RunGuest"},
+			wantCompleted: []string{"Init", "Join", "RunGuest"},
+		},
+		{
+			name:          "failed at join",
+			failAt:        "Join",
+			wantExecuted:  []string{"Check", "Init", "Join"},
+			wantCompleted: []string{"Init"},
+			wantErr:       true,
+		},
+		{
+			name:          "resume from completed phases",
+			completed:     []string{"Init"},
+			wantExecuted:  []string{"Check", "Join", "RunGuest"},
+			wantCompleted: []string{"Init", "Join", "RunGuest"},
+		},
+		{
+			name:          "from phase reruns completed phases",
+			completed:     []string{"Init", "Join"},
+			from:          "Init",
+			wantExecuted:  []string{"Check", "Init", "Join", "RunGuest"},
+			wantCompleted: []string{"Init", "Join", "RunGuest"},
+		},
+		{
+			name:          "from phase skips previous phases",
+			from:          "Join",
+			wantExecuted:  []string{"Check", "Join", "RunGuest"},
+			wantCompleted: []string{"Join", "RunGuest"},
+		},
+		{
+			name:          "skip phase",
+			skips:         []string{"Join"},
+			wantExecuted:  []string{"Check", "Init", "RunGuest"},
+			wantCompleted: []string{"Init", "Join", "RunGuest"},
+		},
+		{
+			name:    "skip required phase",
+			skips:   []string{"Check"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown phase",
+			from:    "Unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			newPhase := func(name string, required bool) Phase {
+				return Phase{Name: name, Required: required, Run: func(*v2.Cluster) error {
+					executed = append(executed, name)
+					if name == tt.failAt {
+						return errors.New("failed")
+					}
+					return nil
+				}}
+			}
+			phases := []Phase{
+				newPhase("Check", true),
+				newPhase("Init", false),
+				newPhase("Join", false),
+				newPhase("RunGuest", false),
+			}
+			checkpoints := 0
+			ctx := WithCheckpoint(context.Background(), func(*v2.Cluster) { checkpoints++ })
+			cluster := &v2.Cluster{}
+			cluster.Status.CompletedPhases = tt.completed
+
+			err := runPhases(ctx, "CreateProcessor", cluster, phases, tt.from, tt.skips)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("runPhases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(executed, tt.wantExecuted) {
+				t.Errorf("runPhases() executed = %v, want %v", executed, tt.wantExecuted)
+			}
+			if !reflect.DeepEqual(cluster.Status.CompletedPhases, tt.wantCompleted) {
+				t.Errorf("runPhases() completed = %v, want %v", cluster.Status.CompletedPhases, tt.wantCompleted)
+			}
+			if tt.wantErr && checkpoints != len(tt.wantCompleted) {
+				t.Errorf("runPhases() checkpoints = %d, want %d", checkpoints, len(tt.wantCompleted))
+			}
+		})
+	}
+}
+
+func TestGetPhaseOptions(t *testing.T) {
+	if got := GetPhaseOptions(context.Background()); !reflect.DeepEqual(got, PhaseOptions{}) {
+		t.Errorf("GetPhaseOptions() = %+v, want empty", got)
+	}
+	want := PhaseOptions{From: PhaseJoin, Skips: []string{PhaseMirrorRegistry}}
+	if got := GetPhaseOptions(WithPhaseOptions(context.Background(), want)); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetPhaseOptions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCreateProcessor_resumeAfterInit(t *testing.T) {
+	phases := (&CreateProcessor{}).GetPhases()
+	var completed []string
+	for _, p := range phases {
+		if !p.Required {
+			completed = append(completed, p.Name)
+		}
+		if p.Name == PhaseInit {
+			break
+		}
+	}
+	tests := []struct {
+		name          string
+		completed     []string
+		wantPreflight bool
+	}{
+		{name: "fresh create", wantPreflight: true},
+		{name: "resume after init", completed: completed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var executed []string
+			for i := range phases {
+				name := phases[i].Name
+				phases[i].Run = func(*v2.Cluster) error {
+					executed = append(executed, name)
+					return nil
+				}
+			}
+			cluster := &v2.Cluster{}
+			cluster.Status.CompletedPhases = append([]string{}, tt.completed...)
+			if err := runPhases(context.Background(), "CreateProcessor", cluster, phases, "", nil); err != nil {
+				t.Fatalf("runPhases() error = %v", err)
+			}
+			if got := slices.Contains(executed, PhasePreflight); got != tt.wantPreflight {
+				t.Errorf("runPhases() executed preflight = %v, want %v, executed %v", got, tt.wantPreflight, executed)
+			}
+			if !slices.Contains(executed, PhaseCheck) || !slices.Contains(executed, PhaseJoin) {
+				t.Errorf("runPhases() executed = %v, want %s and %s", executed, PhaseCheck, PhaseJoin)
+			}
+			if tt.completed != nil && slices.Contains(executed, PhaseInit) {
+				t.Errorf("runPhases() executed = %v, want %s skipped", executed, PhaseInit)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/bootstrap"
@@ -28,6 +29,7 @@ import (
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/filesystem/rootfs"
 	"github.com/labring/sealos/pkg/guest"
+	"github.com/labring/sealos/pkg/plugin"
 	"github.com/labring/sealos/pkg/runtime"
 	"github.com/labring/sealos/pkg/runtime/factory"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
@@ -55,8 +57,8 @@ func (c *ScaleProcessor) Execute(cluster *v2.Cluster) error {
 		return err
 	}
 
-	for _, f := range pipLine {
-		if err = f(cluster); err != nil {
+	for _, p := range pipLine {
+		if err = runPhase("ScaleProcessor", p, cluster); err != nil {
 			return err
 		}
 	}
@@ -64,35 +66,50 @@ func (c *ScaleProcessor) Execute(cluster *v2.Cluster) error {
 	return nil
 }
 
-func (c *ScaleProcessor) GetPipeLine() ([]func(cluster *v2.Cluster) error, error) {
-	var todoList []func(cluster *v2.Cluster) error
+func (c *ScaleProcessor) GetPipeLine() ([]Phase, error) {
+	var todoList []Phase
 	if c.IsScaleUp {
 		todoList = append(todoList,
-			c.JoinCheck,
-			c.PreProcess,
-			c.PreProcessImage,
-			c.RunConfig,
-			c.MountRootfs,
-			c.Bootstrap,
-			//s.GetPhasePluginFunc(plugin.PhasePreJoin),
-			c.Join,
-			c.RunGuest,
-			//s.GetPhasePluginFunc(plugin.PhasePostJoin),
+			Phase{Name: "JoinCheck", Run: c.JoinCheck},
+			Phase{Name: PhasePreProcess, Run: c.PreProcess},
+			Phase{Name: "PreProcessImage", Run: c.PreProcessImage},
+			Phase{Name: PhaseRunConfig, Run: c.RunConfig},
+			Phase{Name: PhaseMountRootfs, Run: c.MountRootfs},
+			Phase{Name: PhaseMirrorRegistry, Run: c.MirrorRegistry},
+			Phase{Name: PhaseBootstrap, Run: c.Bootstrap},
+			Phase{Name: "SyncRegistryReplicas", Run: c.SyncRegistryReplicas},
+			Phase{Name: plugin.PhasePreJoin, Run: c.GetPhasePluginFunc(plugin.PhasePreJoin)},
+			Phase{Name: PhaseJoin, Run: c.Join},
+			Phase{Name: PhaseRunGuest, Run: c.RunGuest},
+			Phase{Name: plugin.PhasePostJoin, Run: c.GetPhasePluginFunc(plugin.PhasePostJoin)},
 		)
 		return todoList, nil
 	}
 
 	todoList = append(todoList,
-		c.DeleteCheck,
-		c.PreProcess,
-		c.Delete,
-		c.UndoBootstrap,
-		//c.ApplyCleanPlugin,
-		c.UnMountRootfs,
+		Phase{Name: "DeleteCheck", Run: c.DeleteCheck},
+		Phase{Name: PhasePreProcess, Run: c.PreProcess},
+		Phase{Name: plugin.PhasePreReset, Run: c.GetPhasePluginFunc(plugin.PhasePreReset)},
+		Phase{Name: "Delete", Run: c.Delete},
+		Phase{Name: "UndoBootstrap", Run: c.UndoBootstrap},
+		Phase{Name: plugin.PhasePostReset, Run: c.GetPhasePluginFunc(plugin.PhasePostReset)},
+		Phase{Name: "UnMountRootfs", Run: c.UnMountRootfs},
+		Phase{Name: "SyncRegistryReplicas", Run: c.SyncRegistryReplicas},
 	)
 	return todoList, nil
 }
 
+func (c *ScaleProcessor) GetPhasePluginFunc(phase string) func(cluster *v2.Cluster) error {
+	return func(cluster *v2.Cluster) error {
+		logger.Info("Executing pipeline %s plugins in ScaleProcessor.", phase)
+		if c.IsScaleUp {
+			return RunPlugins(cluster, c.ClusterFile.GetPlugins(), phase, append(c.MastersToJoin, c.NodesToJoin...))
+		}
+		// the deleting hosts are not in the desired cluster any more
+		return RunPlugins(c.ClusterFile.GetCluster(), c.ClusterFile.GetPlugins(), phase, append(c.MastersToDelete, c.NodesToDelete...))
+	}
+}
+
 func (c *ScaleProcessor) skipAppMounts(allMount []v2.MountImage) []v2.MountImage {
 	mounts := make([]v2.MountImage, 0)
 	for _, m := range allMount {
@@ -158,7 +175,9 @@ func (c *ScaleProcessor) JoinCheck(cluster *v2.Cluster) error {
 	ips = append(ips, cluster.GetMaster0IPAndPort())
 	ips = append(ips, c.MastersToJoin...)
 	ips = append(ips, c.NodesToJoin...)
-	return NewCheckError(checker.RunCheckList([]checker.Interface{checker.NewIPsHostChecker(ips)}, cluster, checker.PhasePre))
+	// master0 has been initialized, the ports of it are in use
+	preflight := checker.NewPreflightChecker(append(append([]string{}, c.MastersToJoin...), c.NodesToJoin...))
+	return NewCheckError(checker.RunCheckList([]checker.Interface{checker.NewIPsHostChecker(ips), preflight}, cluster, checker.PhasePre))
 }
 
 func (c *ScaleProcessor) DeleteCheck(cluster *v2.Cluster) error {
@@ -195,6 +214,11 @@ func (c *ScaleProcessor) preProcess(cluster *v2.Cluster) error {
 				obj = append(obj, configs[i])
 			}
 		}
+		if plugins := c.ClusterFile.GetPlugins(); len(plugins) > 0 {
+			for i := range plugins {
+				obj = append(obj, plugins[i])
+			}
+		}
 		if err = yaml.MarshalFile(clusterPath, obj...); err != nil {
 			return err
 		}
@@ -275,6 +299,50 @@ func filterNoneApplicationMounts(images []v2.MountImage) []v2.MountImage {
 	return ret
 }
 
+// MirrorRegistry syncs all images to the joining registries, the existing registries have them already.
+func (c *ScaleProcessor) MirrorRegistry(cluster *v2.Cluster) error {
+	logger.Info("Executing pipeline MirrorRegistry in ScaleProcessor.")
+	var registries []string
+	for _, host := range append(c.MastersToJoin, c.NodesToJoin...) {
+		if slices.Contains(cluster.GetRegistryIPAndPortList(), host) {
+			registries = append(registries, host)
+		}
+	}
+	if len(registries) == 0 {
+		return nil
+	}
+	return mirrorRegistry(cluster, cluster.Status.Mounts, registries)
+}
+
+// SyncRegistryReplicas updates the registry replicas on the existing hosts if any registry is joined or deleted,
+// the joining hosts are configured by the bootstrap.
+func (c *ScaleProcessor) SyncRegistryReplicas(cluster *v2.Cluster) error {
+	logger.Info("Executing pipeline SyncRegistryReplicas in ScaleProcessor.")
+	registries := cluster.GetRegistryIPAndPortList()
+	scaling := append(c.MastersToJoin, c.NodesToJoin...)
+	if !c.IsScaleUp {
+		registries = c.ClusterFile.GetCluster().GetRegistryIPAndPortList()
+		scaling = append(c.MastersToDelete, c.NodesToDelete...)
+	}
+	changed := false
+	for _, host := range scaling {
+		if slices.Contains(registries, host) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		return nil
+	}
+	var hosts []string
+	for _, host := range cluster.GetAllIPS() {
+		if !slices.Contains(scaling, host) {
+			hosts = append(hosts, host)
+		}
+	}
+	return SyncRegistryReplicas(cluster, hosts)
+}
+
 func (c *ScaleProcessor) Bootstrap(cluster *v2.Cluster) error {
 	logger.Info("Executing pipeline Bootstrap in ScaleProcessor")
 	hosts := append(c.MastersToJoin, c.NodesToJoin...)
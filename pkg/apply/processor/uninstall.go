@@ -0,0 +1,168 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/buildah"
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/guest"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/confirm"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var ForceUninstall bool
+
+type UninstallProcessor struct {
+	ClusterFile clusterfile.Interface
+	Buildah     buildah.Interface
+	Guest       guest.Interface
+	Images      []string
+	mounts      []v2.MountImage
+}
+
+func (c *UninstallProcessor) Execute(cluster *v2.Cluster) error {
+	pipLine, err := c.GetPipeLine()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range pipLine {
+		if err = runPhase("UninstallProcessor", p, cluster); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *UninstallProcessor) GetPipeLine() ([]Phase, error) {
+	var todoList []Phase
+	todoList = append(todoList,
+		Phase{Name: "SyncStatusAndCheck", Run: c.SyncStatusAndCheck},
+		Phase{Name: "ConfirmUninstallApps", Run: c.ConfirmUninstallApps},
+		Phase{Name: PhaseRunGuest, Run: c.RunGuest},
+		Phase{Name: "UnMountImage", Run: c.UnMountImage},
+	)
+	return todoList, nil
+}
+
+func (c *UninstallProcessor) SyncStatusAndCheck(cluster *v2.Cluster) error {
+	logger.Info("Executing SyncStatusAndCheck Pipeline in UninstallProcessor")
+	if err := SyncClusterStatus(cluster, c.Buildah, false); err != nil {
+		return err
+	}
+	mounts, err := findUninstallMounts(cluster, c.Images)
+	if err != nil {
+		return err
+	}
+	c.mounts = mounts
+	return nil
+}
+
+// findUninstallMounts returns the mounts of images in the order of installation.
+func findUninstallMounts(cluster *v2.Cluster, images []string) ([]v2.MountImage, error) {
+	for _, img := range images {
+		_, mount := cluster.FindImage(img)
+		if mount == nil {
+			return nil, fmt.Errorf("image %s is not installed in cluster %s", img, cluster.Name)
+		}
+		if mount.UninstallCommand() == "" {
+			return nil, fmt.Errorf("image %s has no uninstall command, it should be set by label %s", img, v2.ImageUninstallKeys[0])
+		}
+	}
+	mounts := make([]v2.MountImage, 0, len(images))
+	for _, m := range cluster.Status.Mounts {
+		if slices.Contains(images, m.ImageName) {
+			mounts = append(mounts, m)
+		}
+	}
+	return mounts, nil
+}
+
+func (c *UninstallProcessor) ConfirmUninstallApps(_ *v2.Cluster) error {
+	logger.Info("Executing ConfirmUninstallApps Pipeline in UninstallProcessor")
+	if ForceUninstall {
+		return nil
+	}
+	prompt := fmt.Sprintf("are you sure to uninstall these following apps? \n%s\t", strings.Join(c.Images, "\n"))
+	cancelledMsg := "you have canceled to uninstall these apps"
+	pass, err := confirm.Confirm(prompt, cancelledMsg)
+	if err != nil {
+		return err
+	}
+	if !pass {
+		return ErrCancelled
+	}
+	return nil
+}
+
+func (c *UninstallProcessor) RunGuest(cluster *v2.Cluster) error {
+	logger.Info("Executing RunGuest Pipeline in UninstallProcessor")
+	hosts := append(cluster.GetMasterIPAndPortList(), cluster.GetNodeIPAndPortList()...)
+	return c.Guest.Delete(cluster, c.mounts, hosts)
+}
+
+func (c *UninstallProcessor) UnMountImage(cluster *v2.Cluster) error {
+	logger.Info("Executing UnMountImage Pipeline in UninstallProcessor")
+	for _, m := range c.mounts {
+		if err := c.Buildah.Delete(m.Name); err != nil {
+			return err
+		}
+	}
+	removeUninstalledImages(cluster, c.Images)
+	return nil
+}
+
+func removeUninstalledImages(cluster *v2.Cluster, images []string) {
+	mounts := make([]v2.MountImage, 0, len(cluster.Status.Mounts))
+	for _, m := range cluster.Status.Mounts {
+		if !slices.Contains(images, m.ImageName) {
+			mounts = append(mounts, m)
+		}
+	}
+	cluster.Status.Mounts = mounts
+	specImages := make(v2.ImageList, 0, len(cluster.Spec.Image))
+	for _, img := range cluster.Spec.Image {
+		if !slices.Contains(images, img) {
+			specImages = append(specImages, img)
+		}
+	}
+	cluster.Spec.Image = specImages
+}
+
+func NewUninstallProcessor(clusterFile clusterfile.Interface, name string, images []string) (Interface, error) {
+	bder, err := buildah.New(name)
+	if err != nil {
+		return nil, err
+	}
+
+	gs, err := guest.NewGuestManager()
+	if err != nil {
+		return nil, err
+	}
+
+	return &UninstallProcessor{
+		ClusterFile: clusterFile,
+		Buildah:     bder,
+		Guest:       gs,
+		Images:      images,
+	}, nil
+}
@@ -0,0 +1,76 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package processor
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func newUninstallTestCluster() *v2.Cluster {
+	cluster := &v2.Cluster{}
+	cluster.Name = "default"
+	cluster.Spec.Image = v2.ImageList{"labring/kubernetes:v1.25.0", "labring/helm:v3.8.2", "labring/app:v1.0.0"}
+	cluster.Status.Mounts = []v2.MountImage{
+		{Name: "rootfs", ImageName: "labring/kubernetes:v1.25.0", Type: v2.RootfsImage},
+		{Name: "helm", ImageName: "labring/helm:v3.8.2", Type: v2.AppImage,
+			Labels: map[string]string{"sealos.io.uninstall": "rm -f /usr/bin/helm"}},
+		{Name: "app", ImageName: "labring/app:v1.0.0", Type: v2.AppImage,
+			Labels: map[string]string{"apps.sealos.io/uninstall": "helm uninstall app"}},
+	}
+	return cluster
+}
+
+func Test_findUninstallMounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "in the order of installation", images: []string{"labring/app:v1.0.0", "labring/helm:v3.8.2"}, want: []string{"helm", "app"}},
+		{name: "not installed", images: []string{"labring/calico:v3.24.1"}, wantErr: true},
+		{name: "no uninstall command", images: []string{"labring/kubernetes:v1.25.0"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mounts, err := findUninstallMounts(newUninstallTestCluster(), tt.images)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("findUninstallMounts() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			var got []string
+			for _, m := range mounts {
+				got = append(got, m.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findUninstallMounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_removeUninstalledImages(t *testing.T) {
+	cluster := newUninstallTestCluster()
+	removeUninstalledImages(cluster, []string{"labring/helm:v3.8.2"})
+	if want := (v2.ImageList{"labring/kubernetes:v1.25.0", "labring/app:v1.0.0"}); !reflect.DeepEqual(cluster.Spec.Image, want) {
+		t.Errorf("removeUninstalledImages() images = %v, want %v", cluster.Spec.Image, want)
+	}
+	if len(cluster.Status.Mounts) != 2 || cluster.Status.Mounts[1].Name != "app" {
+		t.Errorf("removeUninstalledImages() mounts = %+v", cluster.Status.Mounts)
+	}
+}
@@ -87,6 +87,16 @@ func withCommonContext(ctx context.Context, cmd *cobra.Command) context.Context
 		v, _ := cmd.Flags().GetStringSlice("env")
 		ctx = processor.WithEnvs(ctx, maps.FromSlice(v))
 	}
+	if flagChanged(cmd, "from-phase") || flagChanged(cmd, "skip-phase") {
+		from, _ := cmd.Flags().GetString("from-phase")
+		skips, _ := cmd.Flags().GetStringSlice("skip-phase")
+		ctx = processor.WithPhaseOptions(ctx, processor.PhaseOptions{From: from, Skips: skips})
+	}
+	if flagChanged(cmd, "dry-run") {
+		if v, _ := cmd.Flags().GetBool("dry-run"); v {
+			ctx = applydrivers.WithDryRun(ctx)
+		}
+	}
 	return ctx
 }
 
@@ -129,17 +139,27 @@ func (r *ClusterArgs) runArgs(cmd *cobra.Command, args *RunArgs, imageList []str
 	if err != nil {
 		return err
 	}
+	hostArch := func(addr string) string { return GetHostArch(execer, addr) }
+	if args.DryRun {
+		// the plan does not depend on the arch, do not connect to any host
+		hostArch = func(string) string { return string(v2.AMD64) }
+	}
 	if len(masters) > 0 {
 		host, port := iputils.GetHostIPAndPortOrDefault(masters[0], defaultPort)
 		master0addr := net.JoinHostPort(host, port)
-		r.setHostWithIpsPort(masters, []string{v2.MASTER, GetHostArch(execer, master0addr)})
+		r.setHostWithIpsPort(masters, []string{v2.MASTER, hostArch(master0addr)})
 	}
 	if len(nodes) > 0 {
 		host, port := iputils.GetHostIPAndPortOrDefault(nodes[0], defaultPort)
 		node0addr := net.JoinHostPort(host, port)
-		r.setHostWithIpsPort(nodes, []string{v2.NODE, GetHostArch(execer, node0addr)})
+		r.setHostWithIpsPort(nodes, []string{v2.NODE, hostArch(node0addr)})
+	}
+	if r.cluster.CreationTimestamp.IsZero() {
+		// the hosts of an unfinished creation are specified by the arguments again
+		r.cluster.Spec.Hosts = r.hosts
+	} else {
+		r.cluster.Spec.Hosts = append(r.cluster.Spec.Hosts, r.hosts...)
 	}
-	r.cluster.Spec.Hosts = append(r.cluster.Spec.Hosts, r.hosts...)
 
 	return nil
 }
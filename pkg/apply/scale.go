@@ -94,6 +94,18 @@ func getSSHFromCommand(cmd *cobra.Command) *v2.SSH {
 		ret.Port, _ = fs.GetUint16("port")
 		changed = true
 	}
+	if flagChanged(cmd, "proxy-jump") {
+		ret.ProxyJump, _ = fs.GetString("proxy-jump")
+		changed = true
+	}
+	if flagChanged(cmd, "ssh-agent") {
+		ret.Agent, _ = fs.GetString("ssh-agent")
+		changed = true
+	}
+	if flagChanged(cmd, "host-key-checking") {
+		ret.HostKeyChecking, _ = fs.GetString("host-key-checking")
+		changed = true
+	}
 	if changed {
 		return ret
 	}
@@ -172,7 +184,8 @@ func verifyAndSetNodes(cmd *cobra.Command, cluster *v2.Cluster, scaleArgs *Scale
 			global := cluster.Spec.SSH.DeepCopy()
 			ssh.OverSSHConfig(global, override)
 
-			sshClient := ssh.MustNewClient(global, true)
+			sshClient := ssh.MustNewClient(global, true,
+				ssh.WithKnownHosts(constants.KnownHostsFile(cluster.Name), global.HostKeyChecking))
 			execer, err := exec.New(sshClient)
 			if err != nil {
 				return nil, err
@@ -0,0 +1,41 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/apply/applydrivers"
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func NewApplierFromUninstallArgs(cmd *cobra.Command, args *UninstallArgs) (applydrivers.Interface, error) {
+	if args.ClusterName == "" {
+		return nil, fmt.Errorf("cluster name can not be empty")
+	}
+	cf := clusterfile.NewClusterFile(constants.Clusterfile(args.ClusterName))
+	if err := cf.Process(); err != nil {
+		return nil, err
+	}
+	cluster := cf.GetCluster()
+	if cluster.Status.Phase != v2.ClusterSuccess {
+		return nil, fmt.Errorf("cluster status is not %s", v2.ClusterSuccess)
+	}
+	return applydrivers.NewDefaultApplier(cmd.Context(), cluster.DeepCopy(), cf, nil)
+}
@@ -137,15 +137,20 @@ func GetNewImages(currentCluster, desiredCluster *v2.Cluster) []string {
 	return nil
 }
 
-func CheckAndInitialize(cluster *v2.Cluster) error {
+func setSSHDefaults(cluster *v2.Cluster) {
 	cluster.Spec.SSH.Port = cluster.Spec.SSH.DefaultPort()
 
 	if cluster.Spec.SSH.Pk == "" {
 		cluster.Spec.SSH.Pk = filepath.Join(constants.GetHomeDir(), ".ssh", "id_rsa")
 	}
+}
+
+func CheckAndInitialize(cluster *v2.Cluster) error {
+	setSSHDefaults(cluster)
 
 	if len(cluster.Spec.Hosts) == 0 {
-		sshClient := ssh.MustNewClient(cluster.Spec.SSH.DeepCopy(), true)
+		sshClient := ssh.MustNewClient(cluster.Spec.SSH.DeepCopy(), true,
+			ssh.WithKnownHosts(constants.KnownHostsFile(cluster.Name), cluster.Spec.SSH.HostKeyChecking))
 		execer, err := exec.New(sshClient)
 		if err != nil {
 			return err
@@ -18,14 +18,17 @@ import (
 	"context"
 	"fmt"
 
-	"golang.org/x/sync/errgroup"
-
+	"github.com/labring/sealos/pkg/events"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 type Phase string
 
+// eventSource is the source of events emitted by appliers.
+const eventSource = "Bootstrap"
+
 const (
 	Preflight  Phase = "preflight"
 	Init       Phase = "init"
@@ -73,7 +76,10 @@ func (bs *realBootstrap) Apply(hosts ...string) error {
 				return nil
 			}
 			logger.Debug("apply %s on host %s", applier, host)
-			return applier.Apply(bs.ctx, host)
+			finish := events.StartPhase(eventSource, fmt.Sprint(applier), host)
+			err := applier.Apply(bs.ctx, host)
+			finish(err)
+			return err
 		}); err != nil {
 			return err
 		}
@@ -108,7 +114,10 @@ func (bs *realBootstrap) Delete(hosts ...string) error {
 				continue
 			}
 			logger.Debug("undo %s on host %s", applier, host)
-			if err := applier.Undo(bs.ctx, host); err != nil {
+			finish := events.StartPhase(eventSource, fmt.Sprintf("undo_%s", applier), host)
+			err := applier.Undo(bs.ctx, host)
+			finish(err)
+			if err != nil {
 				return err
 			}
 		}
@@ -117,7 +126,7 @@ func (bs *realBootstrap) Delete(hosts ...string) error {
 }
 
 func runParallel(hosts []string, fn func(string) error) error {
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for i := range hosts {
 		host := hosts[i]
 		eg.Go(func() error {
@@ -154,7 +163,7 @@ func (initializer *defaultInitializer) Undo(ctx Context, host string) error {
 
 func init() {
 	defaultPreflights = append(defaultPreflights, &defaultChecker{})
-	defaultInitializers = append(defaultInitializers, &registryHostApplier{}, &registryApplier{}, &defaultCRIInitializer{}, &apiServerHostApplier{}, &lvscareHostApplier{}, &defaultInitializer{})
+	defaultInitializers = append(defaultInitializers, &registryHostApplier{}, &registryApplier{}, &defaultCRIInitializer{}, &apiServerHostApplier{}, &lvscareHostApplier{}, &defaultInitializer{}, &registryReplicasApplier{})
 }
 
 func RegisterApplier(phase Phase, appliers ...Applier) error {
@@ -17,6 +17,7 @@ package bootstrap
 import (
 	"fmt"
 
+	"golang.org/x/exp/slices"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/labring/sealos/pkg/constants"
@@ -40,7 +41,7 @@ func (a *registryApplier) Apply(ctx Context, host string) error {
 	if a.upgrade == nil {
 		a.upgrade = password.NewUpgrade(ctx.GetCluster().GetName(), ctx.GetExecer())
 	}
-	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), ctx.GetCluster().GetRegistryIPAndPort())
+	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), ctx.GetCluster().GetRegistryIPAndPortList()...)
 	lnCmd := fmt.Sprintf(constants.DefaultLnFmt, ctx.GetPathResolver().RootFSRegistryPath(), rc.Data)
 	logger.Debug("make soft link: %s", lnCmd)
 	if err := ctx.GetExecer().CmdAsync(host, lnCmd); err != nil {
@@ -62,16 +63,35 @@ type registryHostApplier struct{ common }
 func (*registryHostApplier) String() string { return "registry_host_applier" }
 
 func (*registryHostApplier) Undo(ctx Context, host string) error {
-	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), ctx.GetCluster().GetRegistryIPAndPort())
+	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), ctx.GetCluster().GetRegistryIPAndPortList()...)
 	return ctx.GetRemoter().HostsDelete(host, rc.Domain)
 }
 
 func (a *registryHostApplier) Apply(ctx Context, host string) error {
-	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), ctx.GetCluster().GetRegistryIPAndPort())
-
-	if err := ctx.GetRemoter().HostsAdd(host, iputils.GetHostIP(rc.IP), rc.Domain); err != nil {
+	registries := ctx.GetCluster().GetRegistryIPAndPortList()
+	rc := helpers.GetRegistryInfo(ctx.GetExecer(), ctx.GetPathResolver().RootFSPath(), registries...)
+	ip := iputils.GetHostIP(rc.IP)
+	// pull from the local replica, image-cri-shim fails over to other replicas if it is down
+	if len(registries) > 1 && slices.Contains(registries, host) {
+		ip = iputils.GetHostIP(host)
+	}
+	if err := ctx.GetRemoter().HostsAdd(host, ip, rc.Domain); err != nil {
 		return fmt.Errorf("failed to add hosts: %v", err)
 	}
 
 	return nil
 }
+
+// registryReplicasApplier configures image-cri-shim with the registry replicas, so that the registry
+// domain is resolved to another replica once the current one is down.
+type registryReplicasApplier struct{ common }
+
+func (*registryReplicasApplier) String() string { return "registry_replicas_applier" }
+
+func (*registryReplicasApplier) Filter(ctx Context, _ string) bool {
+	return len(ctx.GetCluster().GetRegistryIPAndPortList()) > 1
+}
+
+func (*registryReplicasApplier) Apply(ctx Context, host string) error {
+	return helpers.SetImageCRIShimReplicas(ctx.GetExecer(), host, ctx.GetCluster().GetRegistryIPAndPortList())
+}
@@ -0,0 +1,81 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+)
+
+// Expiration describes the expiry of a certificate found on a host.
+type Expiration struct {
+	Host     string
+	Name     string
+	Subject  string
+	SANs     []string
+	IsCA     bool
+	NotAfter time.Time
+}
+
+// DaysRemaining returns the whole days left before the certificate expires, negative if already expired.
+func (e Expiration) DaysRemaining(now time.Time) int {
+	return int(e.NotAfter.Sub(now).Hours() / 24)
+}
+
+// ParseCertExpiration parses the first certificate of PEM encoded data.
+func ParseCertExpiration(host, name string, data []byte) (*Expiration, error) {
+	certs, err := certutil.ParseCertsPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %v", name, err)
+	}
+	return newExpiration(host, name, certs[0]), nil
+}
+
+// ParseKubeConfigExpiration parses the embedded client certificate of the current context in a kubeconfig file.
+func ParseKubeConfigExpiration(host, name string, data []byte) (*Expiration, error) {
+	cfg, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig %s: %v", name, err)
+	}
+	ctx, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("failed to find current context in kubeconfig %s", name)
+	}
+	authInfo, ok := cfg.AuthInfos[ctx.AuthInfo]
+	if !ok || len(authInfo.ClientCertificateData) == 0 {
+		return nil, fmt.Errorf("no embedded client certificate in kubeconfig %s", name)
+	}
+	return ParseCertExpiration(host, name, authInfo.ClientCertificateData)
+}
+
+func newExpiration(host, name string, cert *x509.Certificate) *Expiration {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	return &Expiration{
+		Host:     host,
+		Name:     name,
+		Subject:  cert.Subject.String(),
+		SANs:     sans,
+		IsCA:     cert.IsCA,
+		NotAfter: cert.NotAfter,
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cert
+
+import (
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/keyutil"
+)
+
+func TestParseExpiration(t *testing.T) {
+	caCert, caKey, err := NewCaCertAndKey(Config{CommonName: "kubernetes", Year: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		CommonName: "kube-apiserver",
+		Year:       1,
+		AltNames: AltNames{
+			DNSNames: map[string]string{"localhost": "localhost"},
+			IPs:      map[string]net.IP{"127.0.0.1": net.IPv4(127, 0, 0, 1)},
+		},
+		Usages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, leafKey, err := NewCaCertAndKeyFromRoot(cfg, caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := ParseCertExpiration("192.168.0.2", "apiserver.crt", EncodeCertPEM(leaf))
+	if err != nil {
+		t.Fatalf("ParseCertExpiration() error = %v", err)
+	}
+	if exp.Subject != "CN=kube-apiserver" || exp.IsCA || len(exp.SANs) != 2 {
+		t.Errorf("ParseCertExpiration() = %+v", exp)
+	}
+	if days := exp.DaysRemaining(time.Now()); days < 363 || days > 365 {
+		t.Errorf("DaysRemaining() = %d, want about 365", days)
+	}
+
+	keyData, err := keyutil.MarshalPrivateKeyToPEM(leafKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kubeconfig := CreateWithCerts("https://apiserver.cluster.local:6443", "kubernetes", "kubernetes-admin",
+		EncodeCertPEM(caCert), keyData, EncodeCertPEM(leaf))
+	data, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp, err = ParseKubeConfigExpiration("192.168.0.2", "admin.conf", data)
+	if err != nil {
+		t.Fatalf("ParseKubeConfigExpiration() error = %v", err)
+	}
+	if !exp.NotAfter.Equal(leaf.NotAfter) {
+		t.Errorf("ParseKubeConfigExpiration() NotAfter = %v, want %v", exp.NotAfter, leaf.NotAfter)
+	}
+
+	if _, err = ParseCertExpiration("192.168.0.2", "broken.crt", []byte("broken")); err == nil {
+		t.Errorf("ParseCertExpiration() expected error for invalid data")
+	}
+}
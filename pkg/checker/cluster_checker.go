@@ -21,6 +21,7 @@ import (
 	"os"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/labring/sealos/pkg/client-go/kubernetes"
@@ -119,6 +120,47 @@ Cluster Status
 	return tpl.Execute(os.Stdout, map[string][]ClusterStatus{"ClusterStatusList": clusterStatus})
 }
 
-func NewClusterChecker() Interface {
+func (n *ClusterChecker) Name() string {
+	return "control-plane"
+}
+
+// Report returns the status of the control plane components of each master, the kubelet is
+// reported by the node checker with the ready condition of node.
+func (n *ClusterChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	c, err := newKubernetesClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	ke := kubernetes.NewKubeExpansion(c.Kubernetes())
+	healthyClient := kubernetes.NewKubeHealthy(c.Kubernetes(), 30*time.Second)
+	nodes, err := c.Kubernetes().CoreV1().Nodes().List(context.Background(), v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	var results []Result
+	for _, node := range nodes.Items {
+		for _, component := range kubernetes.ControlPlaneComponents {
+			pod, err := ke.FetchStaticPod(ctx, node.Name, component)
+			if apierrors.IsNotFound(err) {
+				// not a master
+				continue
+			}
+			r := Result{Node: node.Name, Component: component, Status: StatusOK}
+			switch {
+			case err != nil:
+				r.Status, r.Message = StatusError, err.Error()
+			case getPodReadyStatus(*pod) != nil:
+				r.Status, r.Message = StatusError, healthyClient.ForHealthyPod(pod)
+			default:
+				r.Message = healthyClient.ForHealthyPod(pod)
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+func NewClusterChecker() Reporter {
 	return &ClusterChecker{}
 }
@@ -43,14 +43,27 @@ func (n *CRIShimChecker) Check(_ *v2.Cluster, phase string) error {
 	if phase != PhasePost {
 		return nil
 	}
-	status := &CRIShimStatus{}
-	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
-	}()
+	if err := n.Output(n.status()); err != nil {
+		logger.Error("error output: %+v", err)
+	}
+	return nil
+}
+
+func (n *CRIShimChecker) Name() string {
+	return "image-cri-shim"
+}
+
+func (n *CRIShimChecker) Report(_ *v2.Cluster) ([]Result, error) {
+	status := n.status()
+	r := Result{Node: localNode(), Component: n.Name(), Status: StatusOK, Message: fmt.Sprintf("registry address %s", status.Config["RegistryAddress"])}
+	if status.Error != Nil {
+		r.Status, r.Message = StatusError, status.Error
+	}
+	return []Result{r}, nil
+}
 
+func (n *CRIShimChecker) status() *CRIShimStatus {
+	status := &CRIShimStatus{}
 	if shimCfg, err := types.Unmarshal(types.DefaultImageCRIShimConfig); err != nil {
 		status.Error = fmt.Errorf("read image-cri-shim config error: %w", err).Error()
 	} else {
@@ -74,8 +87,10 @@ func (n *CRIShimChecker) Check(_ *v2.Cluster, phase string) error {
 		}
 	}
 
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return status
 }
 
 func (n *CRIShimChecker) Output(status *CRIShimStatus) error {
@@ -103,6 +118,6 @@ CRIShim Service Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewCRIShimChecker() Interface {
+func NewCRIShimChecker() Reporter {
 	return &CRIShimChecker{}
 }
@@ -62,14 +62,52 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 	if phase != PhasePost {
 		return nil
 	}
-	status := &CRICtlStatus{}
-	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
+	status, err := n.status(cluster)
+	if err != nil {
+		return err
+	}
+	if err = n.Output(status); err != nil {
+		logger.Error("error output: %+v", err)
+	}
+	return nil
+}
+
+func (n *CRICtlChecker) Name() string {
+	return "cri"
+}
+
+func (n *CRICtlChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	status, err := n.status(cluster)
+	if err != nil {
+		return nil, err
+	}
+	node := localNode()
+	r := Result{Node: node, Component: n.Name(), Status: StatusOK,
+		Message: fmt.Sprintf("%d images, %d containers", len(status.ImageList), len(status.ContainerList))}
+	if status.Error != Nil {
+		r.Status, r.Message = StatusError, status.Error
+	}
+	results := []Result{r}
+	for _, pull := range []struct {
+		component, status string
+	}{
+		{"registry-pull", status.RegistryPullStatus},
+		{"image-cri-shim-pull", status.ImageShimPullStatus},
+	} {
+		if pull.status == "" {
+			continue
 		}
-	}()
+		r := Result{Node: node, Component: pull.component, Status: StatusOK, Message: strings.TrimPrefix(pull.status, "ok:")}
+		if !strings.HasPrefix(pull.status, "ok:") {
+			r.Status, r.Message = StatusError, strings.TrimPrefix(pull.status, "unknown:")
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
 
+func (n *CRICtlChecker) status(cluster *v2.Cluster) (*CRICtlStatus, error) {
+	status := &CRICtlStatus{}
 	criShimConfig := "/etc/crictl.yaml"
 	if cfg, err := fileutil.ReadAll(criShimConfig); err != nil {
 		status.Error = fmt.Errorf("read crictl config error: %w", err).Error()
@@ -83,7 +121,7 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 	crictlPath, err := execer.LookPath("crictl")
 	if err != nil {
 		status.Error = fmt.Errorf("error looking for path of crictl: %w", err).Error()
-		return nil
+		return status, nil
 	}
 
 	imageList, err := n.getCRICtlImageList(crictlPath)
@@ -110,10 +148,10 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 	sshCtx := ssh.NewCacheClientFromCluster(cluster, false)
 	sshCtx, err = exec.New(sshCtx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	root := constants.NewPathResolver(cluster.Name).RootFSPath()
-	regInfo := helpers.GetRegistryInfo(sshCtx, root, cluster.GetRegistryIPAndPort())
+	regInfo := helpers.GetRegistryInfo(sshCtx, root, cluster.GetRegistryIPAndPortList()...)
 
 	regStatus, err := n.getRegistryStatus(crictlPath, pauseImage, fmt.Sprintf("%s:%s", regInfo.Domain, regInfo.Port))
 	if err != nil {
@@ -126,8 +164,10 @@ func (n *CRICtlChecker) Check(cluster *v2.Cluster, phase string) error {
 		status.Error = fmt.Errorf("pull shim image error: %w", err).Error()
 	}
 	status.ImageShimPullStatus = shimStatus
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return status, nil
 }
 
 func (n *CRICtlChecker) Output(status *CRICtlStatus) error {
@@ -160,7 +200,7 @@ CRI Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewCRICtlChecker() Interface {
+func NewCRICtlChecker() Reporter {
 	return &CRICtlChecker{}
 }
 
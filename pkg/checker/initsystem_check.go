@@ -40,6 +40,8 @@ type InitSystemStatus struct {
 	ServiceList []systemStatus
 }
 
+var systemServices = []string{"kubelet", "containerd", "cri-docker", "docker", "registry", "image-cri-shim"}
+
 func (n *InitSystemChecker) Check(_ *v2.Cluster, phase string) error {
 	if phase != PhasePost {
 		return nil
@@ -58,9 +60,8 @@ func (n *InitSystemChecker) Check(_ *v2.Cluster, phase string) error {
 		return nil
 	}
 
-	serviceNames := []string{"kubelet", "containerd", "cri-docker", "docker", "registry", "image-cri-shim"}
 	status.ServiceList = make([]systemStatus, 0)
-	for _, sn := range serviceNames {
+	for _, sn := range systemServices {
 		status.ServiceList = append(status.ServiceList, systemStatus{
 			Name:   sn,
 			Status: n.checkInitSystem(initsystemvar, sn),
@@ -71,6 +72,35 @@ func (n *InitSystemChecker) Check(_ *v2.Cluster, phase string) error {
 	return nil
 }
 
+func (n *InitSystemChecker) Name() string {
+	return "initsystem"
+}
+
+// Report returns the status of the services existing on the local host, a service not
+// active is an error and a service not enabled is a warning.
+func (n *InitSystemChecker) Report(_ *v2.Cluster) ([]Result, error) {
+	system, err := initsystem.GetInitSystem()
+	if err != nil {
+		return nil, fmt.Errorf("get initsystem error: %w", err)
+	}
+	node := localNode()
+	var results []Result
+	for _, sn := range systemServices {
+		if !system.ServiceExists(sn) {
+			continue
+		}
+		r := Result{Node: node, Component: sn, Status: StatusOK, Message: "active"}
+		switch {
+		case !system.ServiceIsActive(sn):
+			r.Status, r.Message = StatusError, fmt.Sprintf("not active, see journalctl -xeu %s", sn)
+		case !system.ServiceIsEnabled(sn):
+			r.Status, r.Message = StatusWarning, "active but not enabled"
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
 func (n *InitSystemChecker) Output(status *InitSystemStatus) error {
 	tpl, isOk, err := template.TryParse(`
 Systemd Service Status
@@ -92,7 +122,7 @@ Systemd Service Status
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewInitSystemChecker() Interface {
+func NewInitSystemChecker() Reporter {
 	return &InitSystemChecker{}
 }
 
@@ -17,6 +17,7 @@ package checker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
@@ -102,6 +103,35 @@ Cluster Node Status
 	return tpl.Execute(os.Stdout, nodeCLusterStatus)
 }
 
+func (n *NodeChecker) Name() string {
+	return "node"
+}
+
+func (n *NodeChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	c, err := newKubernetesClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.Kubernetes().CoreV1().Nodes().List(context.Background(), v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return nodeResults(nodes.Items), nil
+}
+
+func nodeResults(nodes []corev1.Node) []Result {
+	var results []Result
+	for _, node := range nodes {
+		ip, phase := getNodeStatus(node)
+		r := Result{Node: node.Name, Component: "node", Status: StatusOK, Message: fmt.Sprintf("%s is %s", ip, phase)}
+		if phase != ReadyNodeStatus {
+			r.Status = StatusError
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
 func getNodeStatus(node corev1.Node) (IP string, Phase string) {
 	if len(node.Status.Addresses) < 1 {
 		return "", ""
@@ -127,6 +157,6 @@ func getNodeStatus(node corev1.Node) (IP string, Phase string) {
 	return IP, Phase
 }
 
-func NewNodeChecker() Interface {
+func NewNodeChecker() Reporter {
 	return &NodeChecker{}
 }
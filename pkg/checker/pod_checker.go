@@ -17,6 +17,7 @@ package checker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	corev1 "k8s.io/api/core/v1"
@@ -116,6 +117,47 @@ func (n *PodChecker) Output(podNamespaceStatusList []PodNamespaceStatus) error {
 	return tpl.Execute(os.Stdout, podNamespaceStatusList)
 }
 
+func (n *PodChecker) Name() string {
+	return "pod"
+}
+
+func (n *PodChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	c, err := newKubernetesClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := c.Kubernetes().CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return podResults(pods.Items), nil
+}
+
+// podResults returns the summary of pods and a warning for each pod not ready, completed pods
+// are skipped.
+func podResults(pods []corev1.Pod) []Result {
+	var (
+		results []Result
+		total   int
+	)
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		total++
+		if getPodReadyStatus(pod) == nil {
+			continue
+		}
+		results = append(results, Result{Node: pod.Spec.NodeName, Component: "pod", Status: StatusWarning,
+			Message: fmt.Sprintf("%s/%s is not ready, phase is %s", pod.Namespace, pod.Name, pod.Status.Phase)})
+	}
+	summary := Result{Component: "pod", Status: StatusOK, Message: fmt.Sprintf("%d/%d pods are ready", total-len(results), total)}
+	if len(results) > 0 {
+		summary.Status = StatusWarning
+	}
+	return append([]Result{summary}, results...)
+}
+
 func getPodReadyStatus(pod corev1.Pod) error {
 	for _, condition := range pod.Status.Conditions {
 		if condition.Type == "Ready" {
@@ -127,6 +169,6 @@ func getPodReadyStatus(pod corev1.Pod) error {
 	return &NotFindReadyTypeError{}
 }
 
-func NewPodChecker() Interface {
+func NewPodChecker() Reporter {
 	return &PodChecker{}
 }
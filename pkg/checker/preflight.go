@@ -0,0 +1,203 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
+)
+
+const (
+	LevelPass = "pass"
+	LevelWarn = "warn"
+	LevelFail = "fail"
+)
+
+// HostCheck is a preflight check run on each host.
+type HostCheck interface {
+	// Name is the name to configure the check in Clusterfile.
+	Name() string
+	// Level is the result of a host not passing the check if it is not configured.
+	Level() string
+	// CheckHost returns the reason why the host does not pass the check, it is empty if passed.
+	CheckHost(execer exec.Interface, cluster *v2.Cluster, host string, args map[string]string) (string, error)
+}
+
+var (
+	hostChecksMu sync.Mutex
+	hostChecks   []HostCheck
+)
+
+// RegisterHostCheck adds a preflight check which runs by default, checks run in the order
+// of registration.
+func RegisterHostCheck(c HostCheck) {
+	hostChecksMu.Lock()
+	defer hostChecksMu.Unlock()
+	for i := range hostChecks {
+		if hostChecks[i].Name() == c.Name() {
+			hostChecks[i] = c
+			return
+		}
+	}
+	hostChecks = append(hostChecks, c)
+}
+
+// configuredCheck is a registered check with the configuration of Clusterfile applied.
+type configuredCheck struct {
+	HostCheck
+	level string
+	args  map[string]string
+}
+
+// configuredChecks returns the enabled checks with their levels and arguments.
+func configuredChecks(configs []v2.PreflightCheck) ([]configuredCheck, error) {
+	hostChecksMu.Lock()
+	defer hostChecksMu.Unlock()
+	byName := make(map[string]v2.PreflightCheck, len(configs))
+	for _, cfg := range configs {
+		found := false
+		for _, c := range hostChecks {
+			found = found || c.Name() == cfg.Name
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown preflight check %s", cfg.Name)
+		}
+		if cfg.Level != "" && cfg.Level != LevelWarn && cfg.Level != LevelFail {
+			return nil, fmt.Errorf("invalid level %s of preflight check %s, must be %s or %s", cfg.Level, cfg.Name, LevelWarn, LevelFail)
+		}
+		byName[cfg.Name] = cfg
+	}
+	var ret []configuredCheck
+	for _, c := range hostChecks {
+		cfg := byName[c.Name()]
+		if cfg.Disabled {
+			continue
+		}
+		level := c.Level()
+		if cfg.Level != "" {
+			level = cfg.Level
+		}
+		ret = append(ret, configuredCheck{HostCheck: c, level: level, args: cfg.Args})
+	}
+	return ret, nil
+}
+
+// PreflightResult is the result of a check on a host.
+type PreflightResult struct {
+	Host    string
+	Check   string
+	Level   string
+	Message string
+}
+
+// RunPreflight runs the enabled preflight checks on hosts, the results are sorted by host.
+func RunPreflight(execer exec.Interface, cluster *v2.Cluster, hosts []string) ([]PreflightResult, error) {
+	checks, err := configuredChecks(cluster.Spec.Preflight)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		mu      sync.Mutex
+		results []PreflightResult
+	)
+	err = parallel.ForEach(hosts, func(host string) error {
+		for _, c := range checks {
+			r := PreflightResult{Host: host, Check: c.Name(), Level: LevelPass}
+			reason, err := c.CheckHost(execer, cluster, host, c.args)
+			if err != nil {
+				reason = err.Error()
+			}
+			if reason != "" {
+				r.Level, r.Message = c.level, reason
+			}
+			mu.Lock()
+			results = append(results, r)
+			mu.Unlock()
+		}
+		return nil
+	})
+	sort.SliceStable(results, func(i, j int) bool {
+		return indexOf(hosts, results[i].Host) < indexOf(hosts, results[j].Host)
+	})
+	return results, err
+}
+
+func indexOf(list []string, s string) int {
+	for i := range list {
+		if list[i] == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func PrintPreflightResults(out io.Writer, results []PreflightResult) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "HOST\tCHECK\tRESULT\tMESSAGE")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Host, r.Check, strings.ToUpper(r.Level), r.Message)
+	}
+	return w.Flush()
+}
+
+// PreflightChecker runs the preflight checks on hosts before they are initialized, the
+// results are printed as a table and it fails if any host fails a check.
+type PreflightChecker struct {
+	IPs    []string
+	Output io.Writer
+}
+
+func NewPreflightChecker(ips []string) Interface {
+	return &PreflightChecker{IPs: ips, Output: os.Stdout}
+}
+
+func (p *PreflightChecker) Check(cluster *v2.Cluster, phase string) error {
+	if phase != PhasePre || len(p.IPs) == 0 {
+		return nil
+	}
+	logger.Info("checker:preflight %v", p.IPs)
+	execer, err := exec.New(ssh.NewCacheClientFromCluster(cluster, false))
+	if err != nil {
+		return err
+	}
+	results, err := RunPreflight(execer, cluster, p.IPs)
+	if err != nil {
+		return err
+	}
+	if err = PrintPreflightResults(p.Output, results); err != nil {
+		return err
+	}
+	var failed []string
+	for _, r := range results {
+		if r.Level == LevelFail {
+			failed = append(failed, fmt.Sprintf("%s on %s", r.Check, r.Host))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("preflight checks failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
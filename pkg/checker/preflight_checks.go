@@ -0,0 +1,254 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/labring/sealos/pkg/exec"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+)
+
+func init() {
+	RegisterHostCheck(SwapCheck{})
+	RegisterHostCheck(KernelModulesCheck{})
+	RegisterHostCheck(DiskCheck{})
+	RegisterHostCheck(PortsCheck{})
+	RegisterHostCheck(CgroupCheck{})
+}
+
+func argOrDefault(args map[string]string, key, def string) string {
+	if v, ok := args[key]; ok {
+		return v
+	}
+	return def
+}
+
+func splitList(s string) []string {
+	var ret []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			ret = append(ret, v)
+		}
+	}
+	return ret
+}
+
+func isMaster(cluster *v2.Cluster, host string) bool {
+	ip := iputils.GetHostIP(host)
+	for _, m := range cluster.GetMasterIPAndPortList() {
+		if iputils.GetHostIP(m) == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// SwapCheck checks that swap is disabled, which kubelet requires by default.
+type SwapCheck struct{}
+
+func (SwapCheck) Name() string  { return "swap" }
+func (SwapCheck) Level() string { return LevelWarn }
+
+func (SwapCheck) CheckHost(execer exec.Interface, _ *v2.Cluster, host string, _ map[string]string) (string, error) {
+	out, err := execer.Cmd(host, "cat /proc/swaps")
+	if err != nil {
+		return "", err
+	}
+	if devices := swapDevices(string(out)); len(devices) > 0 {
+		return fmt.Sprintf("swap is enabled on %s", strings.Join(devices, ",")), nil
+	}
+	return "", nil
+}
+
+func swapDevices(procSwaps string) []string {
+	var ret []string
+	for i, line := range strings.Split(strings.TrimSpace(procSwaps), "\n") {
+		if fields := strings.Fields(line); i > 0 && len(fields) > 0 {
+			ret = append(ret, fields[0])
+		}
+	}
+	return ret
+}
+
+// KernelModulesCheck checks that the kernel modules are loaded or can be loaded, the comma
+// separated modules are set by arg modules.
+type KernelModulesCheck struct{}
+
+const defaultKernelModules = "br_netfilter,overlay,ip_vs,ip_vs_rr,nf_conntrack"
+
+func (KernelModulesCheck) Name() string  { return "kernel-modules" }
+func (KernelModulesCheck) Level() string { return LevelFail }
+
+func (KernelModulesCheck) CheckHost(execer exec.Interface, _ *v2.Cluster, host string, args map[string]string) (string, error) {
+	modules := splitList(argOrDefault(args, "modules", defaultKernelModules))
+	if len(modules) == 0 {
+		return "", nil
+	}
+	// built-in modules are in /sys/module as well, modprobe -n checks if a module can be loaded
+	out, err := execer.Cmd(host, fmt.Sprintf("for m in %s; do [ -d /sys/module/$m ] || modprobe -n $m >/dev/null 2>&1 || echo $m; done", strings.Join(modules, " ")))
+	if err != nil {
+		return "", err
+	}
+	if missing := strings.Fields(string(out)); len(missing) > 0 {
+		return fmt.Sprintf("kernel modules %s are not available", strings.Join(missing, ",")), nil
+	}
+	return "", nil
+}
+
+// DiskCheck checks the available space of the filesystem of arg path is at least arg minimum.
+type DiskCheck struct{}
+
+func (DiskCheck) Name() string  { return "disk" }
+func (DiskCheck) Level() string { return LevelWarn }
+
+func (DiskCheck) CheckHost(execer exec.Interface, _ *v2.Cluster, host string, args map[string]string) (string, error) {
+	path := argOrDefault(args, "path", "/var/lib")
+	minimum, err := resource.ParseQuantity(argOrDefault(args, "minimum", "20Gi"))
+	if err != nil {
+		return "", fmt.Errorf("invalid minimum of disk check: %v", err)
+	}
+	out, err := execer.Cmd(host, fmt.Sprintf("df -Pk %s", path))
+	if err != nil {
+		return "", err
+	}
+	available, err := availableBytes(string(out))
+	if err != nil {
+		return "", err
+	}
+	if available < minimum.Value() {
+		return fmt.Sprintf("%s available on %s, less than %s", resource.NewQuantity(available, resource.BinarySI), path, minimum.String()), nil
+	}
+	return "", nil
+}
+
+// availableBytes parses the available size of df -Pk.
+func availableBytes(df string) (int64, error) {
+	lines := strings.Split(strings.TrimSpace(df), "\n")
+	if len(lines) < 2 {
+		return 0, fmt.Errorf("unexpected output of df: %s", df)
+	}
+	fields := strings.Fields(lines[len(lines)-1])
+	if len(fields) < 4 {
+		return 0, fmt.Errorf("unexpected output of df: %s", df)
+	}
+	kb, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output of df: %s", df)
+	}
+	return kb * 1024, nil
+}
+
+// PortsCheck checks that the ports of kubernetes components are not in use, the comma
+// separated ports are set by args masterPorts and nodePorts.
+type PortsCheck struct{}
+
+func (PortsCheck) Name() string  { return "ports" }
+func (PortsCheck) Level() string { return LevelFail }
+
+func (PortsCheck) CheckHost(execer exec.Interface, cluster *v2.Cluster, host string, args map[string]string) (string, error) {
+	ports := splitList(argOrDefault(args, "nodePorts", "10250"))
+	if isMaster(cluster, host) {
+		ports = splitList(argOrDefault(args, "masterPorts", "6443,2379,2380,10250,10257,10259"))
+	}
+	if len(ports) == 0 {
+		return "", nil
+	}
+	out, err := execer.Cmd(host, "ss -Htln 2>/dev/null || netstat -tln")
+	if err != nil {
+		return "", err
+	}
+	listening := listeningPorts(string(out))
+	var inUse []string
+	for _, p := range ports {
+		if listening.Has(p) {
+			inUse = append(inUse, p)
+		}
+	}
+	if len(inUse) > 0 {
+		return fmt.Sprintf("ports %s are in use", strings.Join(inUse, ",")), nil
+	}
+	return "", nil
+}
+
+// listeningPorts parses the ports of local addresses of ss or netstat, e.g. 0.0.0.0:22 and [::]:22.
+func listeningPorts(out string) sets.String {
+	ret := sets.NewString()
+	for _, line := range strings.Split(out, "\n") {
+		for _, field := range strings.Fields(line) {
+			idx := strings.LastIndex(field, ":")
+			if idx < 0 || idx == len(field)-1 {
+				continue
+			}
+			if _, err := strconv.Atoi(field[idx+1:]); err == nil {
+				// the local address goes before the peer address
+				ret.Insert(field[idx+1:])
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// CgroupCheck checks the cgroup version is arg version if set, and the controllers kubelet
+// requires are enabled with cgroup v2.
+type CgroupCheck struct{}
+
+func (CgroupCheck) Name() string  { return "cgroup" }
+func (CgroupCheck) Level() string { return LevelFail }
+
+func (CgroupCheck) CheckHost(execer exec.Interface, _ *v2.Cluster, host string, args map[string]string) (string, error) {
+	out, err := execer.Cmd(host, "stat -fc %T /sys/fs/cgroup; cat /sys/fs/cgroup/cgroup.controllers 2>/dev/null || true")
+	if err != nil {
+		return "", err
+	}
+	version, controllers := parseCgroup(string(out))
+	if want := argOrDefault(args, "version", ""); want != "" && want != version {
+		return fmt.Sprintf("cgroup version is %s, want %s", version, want), nil
+	}
+	if version != "v2" {
+		return "", nil
+	}
+	var missing []string
+	for _, c := range []string{"cpu", "memory", "pids"} {
+		if !controllers.Has(c) {
+			missing = append(missing, c)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Sprintf("cgroup controllers %s are not enabled", strings.Join(missing, ",")), nil
+	}
+	return "", nil
+}
+
+// parseCgroup parses the filesystem type of /sys/fs/cgroup and the controllers of cgroup v2.
+func parseCgroup(out string) (string, sets.String) {
+	lines := strings.SplitN(strings.TrimSpace(out), "\n", 2)
+	version := "v1"
+	if strings.TrimSpace(lines[0]) == "cgroup2fs" {
+		version = "v2"
+	}
+	controllers := sets.NewString()
+	if len(lines) > 1 {
+		controllers.Insert(strings.Fields(lines[1])...)
+	}
+	return version, controllers
+}
@@ -0,0 +1,178 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/pkg/exec"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type fakeCheck struct {
+	name    string
+	level   string
+	reasons map[string]string
+}
+
+func (c fakeCheck) Name() string  { return c.name }
+func (c fakeCheck) Level() string { return c.level }
+
+func (c fakeCheck) CheckHost(_ exec.Interface, _ *v2.Cluster, host string, args map[string]string) (string, error) {
+	if args["error"] != "" {
+		return "", errors.New(args["error"])
+	}
+	return c.reasons[host], nil
+}
+
+func withHostChecks(t *testing.T, checks ...HostCheck) {
+	saved := hostChecks
+	hostChecks = nil
+	t.Cleanup(func() { hostChecks = saved })
+	for _, c := range checks {
+		RegisterHostCheck(c)
+	}
+}
+
+func TestRunPreflight(t *testing.T) {
+	withHostChecks(t,
+		fakeCheck{name: "swap", level: LevelWarn, reasons: map[string]string{"192.168.0.2:22": "swap is enabled"}},
+		fakeCheck{name: "ports", level: LevelFail, reasons: map[string]string{"192.168.0.3:22": "ports 6443 are in use"}},
+		fakeCheck{name: "disk", level: LevelWarn},
+	)
+	tests := []struct {
+		name    string
+		configs []v2.PreflightCheck
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			want: []string{
+				"192.168.0.2:22 swap warn", "192.168.0.2:22 ports pass", "192.168.0.2:22 disk pass",
+				"192.168.0.3:22 swap pass", "192.168.0.3:22 ports fail", "192.168.0.3:22 disk pass",
+			},
+		},
+		{
+			name: "configured",
+			configs: []v2.PreflightCheck{
+				{Name: "swap", Level: LevelFail},
+				{Name: "ports", Disabled: true},
+				{Name: "disk", Args: map[string]string{"error": "df not found"}},
+			},
+			want: []string{
+				"192.168.0.2:22 swap fail", "192.168.0.2:22 disk warn",
+				"192.168.0.3:22 swap pass", "192.168.0.3:22 disk warn",
+			},
+		},
+		{
+			name:    "unknown check",
+			configs: []v2.PreflightCheck{{Name: "not-exist"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid level",
+			configs: []v2.PreflightCheck{{Name: "swap", Level: "error"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &v2.Cluster{Spec: v2.ClusterSpec{Preflight: tt.configs}}
+			results, err := RunPreflight(nil, cluster, []string{"192.168.0.2:22", "192.168.0.3:22"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("RunPreflight() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var got []string
+			for _, r := range results {
+				got = append(got, r.Host+" "+r.Check+" "+r.Level)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("RunPreflight() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrintPreflightResults(t *testing.T) {
+	var buf bytes.Buffer
+	err := PrintPreflightResults(&buf, []PreflightResult{
+		{Host: "192.168.0.2:22", Check: "swap", Level: LevelWarn, Message: "swap is enabled on /dev/sda2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "WARN") || !strings.HasPrefix(buf.String(), "HOST") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestSwapDevices(t *testing.T) {
+	out := "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n/dev/sda2   partition\t8388604\t\t0\t\t-2\n"
+	if got := swapDevices(out); len(got) != 1 || got[0] != "/dev/sda2" {
+		t.Errorf("swapDevices() = %v, want [/dev/sda2]", got)
+	}
+	if got := swapDevices("Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"); len(got) != 0 {
+		t.Errorf("swapDevices() = %v, want none", got)
+	}
+}
+
+func TestAvailableBytes(t *testing.T) {
+	out := "Filesystem     1024-blocks     Used Available Capacity Mounted on\n/dev/vda1         41152736 12345678  26720972      32% /\n"
+	got, err := availableBytes(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 26720972*1024 {
+		t.Errorf("availableBytes() = %d, want %d", got, 26720972*1024)
+	}
+	if _, err = availableBytes("df: /var/lib: No such file or directory"); err == nil {
+		t.Error("availableBytes() should fail for unexpected output")
+	}
+}
+
+func TestListeningPorts(t *testing.T) {
+	ss := `LISTEN 0      4096       127.0.0.1:2379       0.0.0.0:*
+LISTEN 0      4096               *:10250            *:*
+LISTEN 0      128           [::]:22              [::]:*
+LISTEN 0      4096   127.0.0.53%lo:53         0.0.0.0:*`
+	netstat := `Active Internet connections (only servers)
+Proto Recv-Q Send-Q Local Address           Foreign Address         State
+tcp        0      0 0.0.0.0:6443            0.0.0.0:*               LISTEN`
+	for _, tt := range []struct {
+		out  string
+		want []string
+	}{
+		{out: ss, want: []string{"10250", "22", "2379", "53"}},
+		{out: netstat, want: []string{"6443"}},
+	} {
+		if got := listeningPorts(tt.out).List(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("listeningPorts() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestParseCgroup(t *testing.T) {
+	version, controllers := parseCgroup("cgroup2fs\ncpuset cpu io memory hugetlb pids rdma misc\n")
+	if version != "v2" || !controllers.HasAll("cpu", "memory", "pids") {
+		t.Errorf("parseCgroup() = %s, %v, want v2 with cpu, memory and pids", version, controllers.List())
+	}
+	if version, _ = parseCgroup("tmpfs\n"); version != "v1" {
+		t.Errorf("parseCgroup() = %s, want v1", version)
+	}
+}
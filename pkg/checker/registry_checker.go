@@ -59,19 +59,49 @@ func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
 	if phase != PhasePost {
 		return nil
 	}
+	if !n.isLocal(cluster) {
+		return nil
+	}
+	status, err := n.status(cluster)
+	if err != nil {
+		return err
+	}
+	if err = n.Output(status); err != nil {
+		logger.Error("error output: %+v", err)
+	}
+	return nil
+}
+
+func (n *RegistryChecker) Name() string {
+	return "registry"
+}
+
+func (n *RegistryChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	if !n.isLocal(cluster) {
+		return nil, nil
+	}
+	status, err := n.status(cluster)
+	if err != nil {
+		return nil, err
+	}
+	r := Result{Node: localNode(), Component: n.Name(), Status: StatusOK, Message: fmt.Sprintf("ping %s ok", status.RegistryDomain)}
+	if status.Error != Nil {
+		r.Status, r.Message = StatusError, status.Error
+	}
+	return []Result{r}, nil
+}
+
+func (n *RegistryChecker) isLocal(cluster *v2.Cluster) bool {
 	localAddr, _ := iputils.ListLocalHostAddrs()
 	if !iputils.IsLocalIP(cluster.GetRegistryIP(), localAddr) {
 		logger.Info("current registry ip is %s,not local addr,skip check.", cluster.GetRegistryIP())
-		return nil
+		return false
 	}
-	status := &RegistryStatus{}
-	defer func() {
-		err := n.Output(status)
-		if err != nil {
-			logger.Error("error output: %+v", err)
-		}
-	}()
+	return true
+}
 
+func (n *RegistryChecker) status(cluster *v2.Cluster) (*RegistryStatus, error) {
+	status := &RegistryStatus{}
 	registryConfig := "/etc/registry/registry_config.yml"
 	if cfg, err := fileutil.ReadAll(registryConfig); err != nil {
 		status.Error = fmt.Errorf("read registry config error: %w", err).Error()
@@ -91,10 +121,10 @@ func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
 	sshCtx := ssh.NewCacheClientFromCluster(cluster, false)
 	execer, err := exec.New(sshCtx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	root := constants.NewPathResolver(cluster.Name).RootFSPath()
-	regInfo := helpers.GetRegistryInfo(execer, root, cluster.GetRegistryIPAndPort())
+	regInfo := helpers.GetRegistryInfo(execer, root, cluster.GetRegistryIPAndPortList()...)
 	status.Auth = fmt.Sprintf("%s:%s", regInfo.Username, regInfo.Password)
 	status.RegistryDomain = fmt.Sprintf("%s:%s", regInfo.Domain, regInfo.Port)
 	cfg := types.AuthConfig{
@@ -104,11 +134,13 @@ func (n *RegistryChecker) Check(cluster *v2.Cluster, phase string) error {
 	_, err = crane.NewRegistry(status.RegistryDomain, cfg)
 	if err != nil {
 		status.Error = fmt.Errorf("get registry interface error: %w", err).Error()
-		return nil
+		return status, nil
 	}
 	status.Ping = "ok"
-	status.Error = Nil
-	return nil
+	if status.Error == "" {
+		status.Error = Nil
+	}
+	return status, nil
 }
 
 func (n *RegistryChecker) Output(status *RegistryStatus) error {
@@ -134,6 +166,6 @@ func (n *RegistryChecker) Output(status *RegistryStatus) error {
 	return tpl.Execute(os.Stdout, status)
 }
 
-func NewRegistryChecker() Interface {
+func NewRegistryChecker() Reporter {
 	return &RegistryChecker{}
 }
@@ -0,0 +1,146 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/labring/sealos/pkg/client-go/kubernetes"
+	"github.com/labring/sealos/pkg/constants"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusWarning = "warning"
+	StatusError   = "error"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// Result is the status of a component of cluster, node is empty if the component is not on a node.
+type Result struct {
+	Node      string `json:"node,omitempty"`
+	Component string `json:"component"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// Reporter is a checker of the status of cluster which returns structured results.
+type Reporter interface {
+	Interface
+	// Name is the component of the result if Report fails.
+	Name() string
+	Report(cluster *v2.Cluster) ([]Result, error)
+}
+
+// RunReports runs all the reporters even if some of them fail, a reporter failing is reported
+// as an error of its component.
+func RunReports(list []Reporter, cluster *v2.Cluster) []Result {
+	var results []Result
+	for _, r := range list {
+		logger.Debug("checker:%s", r.Name())
+		ret, err := r.Report(cluster)
+		if err != nil {
+			results = append(results, Result{Component: r.Name(), Status: StatusError, Message: err.Error()})
+			continue
+		}
+		results = append(results, ret...)
+	}
+	return results
+}
+
+func statusSeverity(status string) int {
+	switch status {
+	case StatusOK:
+		return 0
+	case StatusWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// WorstStatus returns the most severe status of results, it is ok if there is no result.
+func WorstStatus(results []Result) string {
+	worst := StatusOK
+	for _, r := range results {
+		if statusSeverity(r.Status) > statusSeverity(worst) {
+			worst = r.Status
+		}
+	}
+	return worst
+}
+
+// PrintResults prints results in format of table, json or yaml.
+func PrintResults(out io.Writer, format string, results []Result) error {
+	if results == nil {
+		results = []Result{}
+	}
+	switch format {
+	case "", OutputTable:
+		w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "NODE\tCOMPONENT\tSTATUS\tMESSAGE")
+		for _, r := range results {
+			node := r.Node
+			if node == "" {
+				node = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", node, r.Component, strings.ToUpper(r.Status), r.Message)
+		}
+		return w.Flush()
+	case OutputJSON:
+		b, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(b))
+		return err
+	case OutputYAML:
+		b, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(b)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %s, available formats are: %s, %s, %s", format, OutputTable, OutputJSON, OutputYAML)
+	}
+}
+
+func newKubernetesClient(cluster *v2.Cluster) (kubernetes.Client, error) {
+	return kubernetes.NewKubernetesClient(constants.NewPathResolver(cluster.Name).AdminFile(), "")
+}
+
+// localNode is the node of the components checked on the host running sealos.
+func localNode() string {
+	name, err := os.Hostname()
+	if err != nil {
+		logger.Debug("failed to get hostname: %v", err)
+	}
+	return name
+}
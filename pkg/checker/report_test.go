@@ -0,0 +1,170 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checker
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+type fakeReporter struct {
+	name    string
+	results []Result
+	err     error
+}
+
+func (f *fakeReporter) Check(_ *v2.Cluster, _ string) error { return nil }
+func (f *fakeReporter) Name() string                        { return f.name }
+func (f *fakeReporter) Report(_ *v2.Cluster) ([]Result, error) {
+	return f.results, f.err
+}
+
+func TestRunReports(t *testing.T) {
+	list := []Reporter{
+		&fakeReporter{name: "registry", err: errors.New("connection refused")},
+		&fakeReporter{name: "node", results: []Result{{Node: "master0", Component: "node", Status: StatusOK}}},
+	}
+	got := RunReports(list, &v2.Cluster{})
+	want := []Result{
+		{Component: "registry", Status: StatusError, Message: "connection refused"},
+		{Node: "master0", Component: "node", Status: StatusOK},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RunReports() = %v, want %v", got, want)
+	}
+	if s := WorstStatus(got); s != StatusError {
+		t.Errorf("WorstStatus() = %s, want %s", s, StatusError)
+	}
+	if s := WorstStatus(nil); s != StatusOK {
+		t.Errorf("WorstStatus(nil) = %s, want %s", s, StatusOK)
+	}
+}
+
+func TestPrintResults(t *testing.T) {
+	results := []Result{
+		{Node: "master0", Component: "kubelet", Status: StatusOK, Message: "active"},
+		{Component: "pod", Status: StatusWarning, Message: "1/2 pods are ready"},
+	}
+	var buf bytes.Buffer
+	if err := PrintResults(&buf, OutputTable, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NODE") || !strings.HasPrefix(lines[2], "-") || !strings.Contains(lines[2], "WARNING") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+
+	for _, format := range []string{OutputJSON, OutputYAML} {
+		buf.Reset()
+		if err := PrintResults(&buf, format, results); err != nil {
+			t.Fatal(err)
+		}
+		var got []Result
+		var err error
+		if format == OutputJSON {
+			err = json.Unmarshal(buf.Bytes(), &got)
+		} else {
+			err = yaml.Unmarshal(buf.Bytes(), &got)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, results) {
+			t.Errorf("%s: got %v, want %v", format, got, results)
+		}
+	}
+
+	buf.Reset()
+	if err := PrintResults(&buf, OutputJSON, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty json = %q, %v", buf.String(), err)
+	}
+	if err := PrintResults(&buf, "xml", results); err == nil {
+		t.Error("expected error of unsupported format")
+	}
+}
+
+func TestNodeResults(t *testing.T) {
+	node := func(name, ready string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: corev1.NodeStatus{
+				Addresses:  []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "192.168.0.2"}},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionStatus(ready)}},
+			},
+		}
+	}
+	got := nodeResults([]corev1.Node{node("master0", "True"), node("node0", "False")})
+	if len(got) != 2 || got[0].Status != StatusOK || got[1].Status != StatusError || got[1].Node != "node0" {
+		t.Errorf("nodeResults() = %v", got)
+	}
+}
+
+func TestPodResults(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "kube-system"},
+			Spec:       corev1.PodSpec{NodeName: "node0"},
+			Status: corev1.PodStatus{
+				Phase:      phase,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+			},
+		}
+	}
+	got := podResults([]corev1.Pod{
+		pod("coredns", corev1.PodRunning, corev1.ConditionTrue),
+		pod("job", corev1.PodSucceeded, corev1.ConditionFalse),
+		pod("cilium", corev1.PodPending, corev1.ConditionFalse),
+	})
+	want := []Result{
+		{Component: "pod", Status: StatusWarning, Message: "1/2 pods are ready"},
+		{Node: "node0", Component: "pod", Status: StatusWarning, Message: "kube-system/cilium is not ready, phase is Pending"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("podResults() = %v, want %v", got, want)
+	}
+	if got = podResults(nil); len(got) != 1 || got[0].Status != StatusOK {
+		t.Errorf("podResults(nil) = %v", got)
+	}
+}
+
+func TestServiceResults(t *testing.T) {
+	svc := func(name string, typ corev1.ServiceType) corev1.Service {
+		return corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}, Spec: corev1.ServiceSpec{Type: typ}}
+	}
+	got := serviceResults(
+		[]corev1.Service{svc("kubernetes", corev1.ServiceTypeClusterIP), svc("web", corev1.ServiceTypeNodePort), svc("external", corev1.ServiceTypeExternalName)},
+		[]corev1.Endpoints{
+			{ObjectMeta: metav1.ObjectMeta{Name: "kubernetes", Namespace: "default"}, Subsets: []corev1.EndpointSubset{{}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}},
+		},
+	)
+	want := []Result{
+		{Component: "service", Status: StatusWarning, Message: "1/2 services have endpoints"},
+		{Component: "service", Status: StatusWarning, Message: "default/web has no endpoints"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("serviceResults() = %v, want %v", got, want)
+	}
+}
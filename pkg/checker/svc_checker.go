@@ -17,6 +17,7 @@ package checker
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/labring/sealos/pkg/template"
@@ -124,6 +125,53 @@ func (n *SvcChecker) Output(svcNamespaceStatusList []*SvcNamespaceStatus) error
 	return tpl.Execute(os.Stdout, svcNamespaceStatusList)
 }
 
+func (n *SvcChecker) Name() string {
+	return "service"
+}
+
+func (n *SvcChecker) Report(cluster *v2.Cluster) ([]Result, error) {
+	c, err := newKubernetesClient(cluster)
+	if err != nil {
+		return nil, err
+	}
+	services, err := c.Kubernetes().CoreV1().Services(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	endpoints, err := c.Kubernetes().CoreV1().Endpoints(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return serviceResults(services.Items, endpoints.Items), nil
+}
+
+// serviceResults returns the summary of services and a warning for each service without
+// endpoints, ExternalName services are skipped.
+func serviceResults(services []corev1.Service, endpoints []corev1.Endpoints) []Result {
+	ready := make(map[string]bool, len(endpoints))
+	for _, ep := range endpoints {
+		ready[ep.Namespace+"/"+ep.Name] = len(ep.Subsets) > 0
+	}
+	var (
+		results []Result
+		total   int
+	)
+	for _, svc := range services {
+		if svc.Spec.Type == corev1.ServiceTypeExternalName {
+			continue
+		}
+		total++
+		if key := svc.Namespace + "/" + svc.Name; !ready[key] {
+			results = append(results, Result{Component: "service", Status: StatusWarning, Message: fmt.Sprintf("%s has no endpoints", key)})
+		}
+	}
+	summary := Result{Component: "service", Status: StatusOK, Message: fmt.Sprintf("%d/%d services have endpoints", total-len(results), total)}
+	if len(results) > 0 {
+		summary.Status = StatusWarning
+	}
+	return append([]Result{summary}, results...)
+}
+
 func IsExistEndpoint(endpointList *corev1.EndpointsList, serviceName string) bool {
 	for _, ep := range endpointList.Items {
 		if ep.Name == serviceName {
@@ -135,6 +183,6 @@ func IsExistEndpoint(endpointList *corev1.EndpointsList, serviceName string) boo
 	return false
 }
 
-func NewSvcChecker() Interface {
+func NewSvcChecker() Reporter {
 	return &SvcChecker{}
 }
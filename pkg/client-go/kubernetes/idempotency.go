@@ -47,6 +47,8 @@ const (
 	KubeControllerManager = "kube-controller-manager"
 	// KubeScheduler defines variable used internally when referring to kube-scheduler component
 	KubeScheduler = "kube-scheduler"
+	// Etcd defines variable used internally when referring to etcd component
+	Etcd = "etcd"
 )
 
 type Idempotency interface {
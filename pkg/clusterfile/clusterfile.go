@@ -34,6 +34,7 @@ type ClusterFile struct {
 
 	cluster       *v2.Cluster
 	configs       []v2.Config
+	plugins       []v2.Plugin
 	runtimeConfig runtime.Config
 
 	once sync.Once
@@ -43,6 +44,7 @@ type Interface interface {
 	PreProcessor
 	GetCluster() *v2.Cluster
 	GetConfigs() []v2.Config
+	GetPlugins() []v2.Plugin
 	GetRuntimeConfig() runtime.Config
 }
 
@@ -54,6 +56,10 @@ func (c *ClusterFile) GetConfigs() []v2.Config {
 	return c.configs
 }
 
+func (c *ClusterFile) GetPlugins() []v2.Plugin {
+	return c.plugins
+}
+
 func (c *ClusterFile) GetRuntimeConfig() runtime.Config {
 	return c.runtimeConfig
 }
@@ -276,3 +276,74 @@ func Test_NewClusterFileWithSingleSchedule(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterFile_DecodeCluster_hostKeyChecking(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "default",
+			data: `apiVersion: apps.sealos.io/v1beta1
+kind: Cluster
+metadata:
+  name: default
+spec:
+  hosts:
+  - ips: [192.168.0.2:22]
+    roles: [master, amd64]`,
+		},
+		{
+			name: "strict host",
+			data: `apiVersion: apps.sealos.io/v1beta1
+kind: Cluster
+metadata:
+  name: default
+spec:
+  ssh:
+    hostKeyChecking: accept-new
+  hosts:
+  - ips: [192.168.0.2:22]
+    roles: [master, amd64]
+    ssh:
+      hostKeyChecking: strict`,
+		},
+		{
+			name: "invalid global",
+			data: `apiVersion: apps.sealos.io/v1beta1
+kind: Cluster
+metadata:
+  name: default
+spec:
+  ssh:
+    hostKeyChecking: "no"
+  hosts:
+  - ips: [192.168.0.2:22]
+    roles: [master, amd64]`,
+			wantErr: true,
+		},
+		{
+			name: "invalid host",
+			data: `apiVersion: apps.sealos.io/v1beta1
+kind: Cluster
+metadata:
+  name: default
+spec:
+  hosts:
+  - ips: [192.168.0.2:22]
+    roles: [master, amd64]
+    ssh:
+      hostKeyChecking: ask`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &ClusterFile{}
+			if err := c.DecodeCluster([]byte(tt.data)); (err != nil) != tt.wantErr {
+				t.Errorf("DecodeCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -67,6 +67,7 @@ func CRDForBytes(data []byte, kind string) (out interface{}, err error) {
 	var (
 		clusters []v1beta1.Cluster
 		configs  []v1beta1.Config
+		plugins  []v1beta1.Plugin
 		tmp      = make(map[string]int)
 	)
 	r := bytes.NewReader(data)
@@ -124,6 +125,21 @@ func CRDForBytes(data []byte, kind string) (out interface{}, err error) {
 				configs[idx] = config
 			}
 			out = configs
+		case constants.Plugin:
+			plugin := v1beta1.Plugin{}
+			err = yaml.Unmarshal(ext.Raw, &plugin)
+			if err != nil {
+				return nil, fmt.Errorf("decode plugin failed %v", err)
+			}
+			k := keyFunc(&plugin)
+			if idx, ok := tmp[k]; !ok {
+				tmp[k] = len(tmp)
+				plugins = append(plugins, plugin)
+			} else {
+				logger.Warn("duplicate resource: %s, replace with new one", k)
+				plugins[idx] = plugin
+			}
+			out = plugins
 		}
 	}
 	return out, nil
@@ -0,0 +1,192 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterfile
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+// ExpandHostGroups appends the hosts of groups to cluster.Spec.Hosts and clears the groups.
+// The settings of a group are inherited by its children, roles, env and taints are accumulated
+// from the farthest ancestor to the group itself, while labels and ssh of nearer groups win.
+// Every host of groups must have the role master or node from its group or the ancestors.
+func ExpandHostGroups(cluster *v2.Cluster) error {
+	groups := cluster.Spec.HostGroups
+	if len(groups) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(groups))
+	for i, g := range groups {
+		if g.Name == "" {
+			return fmt.Errorf("name of host group cannot be empty")
+		}
+		if _, ok := index[g.Name]; ok {
+			return fmt.Errorf("host group %s is declared more than once", g.Name)
+		}
+		index[g.Name] = i
+	}
+	parents := make(map[string][]string)
+	for _, g := range groups {
+		for _, child := range g.Children {
+			if _, ok := index[child]; !ok {
+				return fmt.Errorf("child %s of host group %s is not found", child, g.Name)
+			}
+			parents[child] = append(parents[child], g.Name)
+		}
+	}
+
+	seen := make(map[string]struct{})
+	for _, host := range cluster.Spec.Hosts {
+		for _, ip := range host.IPS {
+			seen[ip] = struct{}{}
+		}
+	}
+	for _, g := range groups {
+		if len(g.Hosts) == 0 {
+			continue
+		}
+		ancestors, err := getAncestors(g.Name, parents, nil)
+		if err != nil {
+			return err
+		}
+		host := v2.Host{}
+		for _, name := range append(ancestors, g.Name) {
+			mergeHostGroup(&host, &groups[index[name]])
+		}
+		for _, h := range g.Hosts {
+			ips, err := ExpandHostRange(h)
+			if err != nil {
+				return fmt.Errorf("invalid hosts of host group %s: %v", g.Name, err)
+			}
+			for _, ip := range ips {
+				if _, ok := seen[ip]; ok {
+					return fmt.Errorf("host %s of host group %s is declared more than once", ip, g.Name)
+				}
+				seen[ip] = struct{}{}
+			}
+			host.IPS = append(host.IPS, ips...)
+		}
+		// hosts without these roles are not part of the cluster, it is mostly a missing role in groups
+		if !slices.Contains(host.Roles, v2.MASTER) && !slices.Contains(host.Roles, v2.NODE) {
+			return fmt.Errorf("hosts %s of host group %s have no role %s or %s, set it in the group or its parents",
+				strings.Join(host.IPS, ","), g.Name, v2.MASTER, v2.NODE)
+		}
+		cluster.Spec.Hosts = append(cluster.Spec.Hosts, host)
+	}
+	cluster.Spec.HostGroups = nil
+	return nil
+}
+
+// getAncestors returns the ancestors of group ordered from the farthest one.
+func getAncestors(group string, parents map[string][]string, visiting []string) ([]string, error) {
+	if slices.Contains(visiting, group) {
+		return nil, fmt.Errorf("host groups %s are in a cycle", strings.Join(append(visiting, group), " -> "))
+	}
+	visiting = append(visiting, group)
+	var ret []string
+	for _, p := range parents[group] {
+		ancestors, err := getAncestors(p, parents, visiting)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range append(ancestors, p) {
+			if !slices.Contains(ret, a) {
+				ret = append(ret, a)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func mergeHostGroup(host *v2.Host, g *v2.HostGroup) {
+	for _, role := range g.Roles {
+		if !slices.Contains(host.Roles, role) {
+			host.Roles = append(host.Roles, role)
+		}
+	}
+	// the group name is used as a role to select the hosts of group
+	if !slices.Contains(host.Roles, g.Name) {
+		host.Roles = append(host.Roles, g.Name)
+	}
+	host.Env = append(host.Env, g.Env...)
+	for k, v := range g.Labels {
+		if host.Labels == nil {
+			host.Labels = make(map[string]string)
+		}
+		host.Labels[k] = v
+	}
+	for _, taint := range g.Taints {
+		if !slices.Contains(host.Taints, taint) {
+			host.Taints = append(host.Taints, taint)
+		}
+	}
+	if g.SSH != nil {
+		host.SSH = g.SSH.DeepCopy()
+	}
+}
+
+var hostRangeRegex = regexp.MustCompile(`\[(\d+):(\d+)\]`)
+
+// maxHostRangeSize is the max number of hosts expanded from a host with ranges.
+const maxHostRangeSize = 1024
+
+// ExpandHostRange expands the ranges in brackets of host, e.g. 192.168.0.[10:12]:22 is expanded
+// into 192.168.0.10:22, 192.168.0.11:22 and 192.168.0.12:22, both ends are included.
+// Brackets not in format [start:end] are kept, so IPv6 addresses like [fe80::1]:22 are not changed.
+// A range must be a whole octet of IPv4 address, and a host expands into maxHostRangeSize hosts at most.
+func ExpandHostRange(host string) ([]string, error) {
+	loc := hostRangeRegex.FindStringSubmatchIndex(host)
+	if loc == nil {
+		return []string{host}, nil
+	}
+	from, err := strconv.Atoi(host[loc[2]:loc[3]])
+	if err != nil {
+		return nil, fmt.Errorf("invalid start of range in %s: %v", host, err)
+	}
+	to, err := strconv.Atoi(host[loc[4]:loc[5]])
+	if err != nil {
+		return nil, fmt.Errorf("invalid end of range in %s: %v", host, err)
+	}
+	if from > to {
+		return nil, fmt.Errorf("start %d of range cannot be greater than end %d in %s", from, to, host)
+	}
+	if to > 255 {
+		return nil, fmt.Errorf("end %d of range cannot be greater than 255 in %s", to, host)
+	}
+	if (loc[0] > 0 && host[loc[0]-1] != '.') || (loc[1] < len(host) && host[loc[1]] != '.' && host[loc[1]] != ':') {
+		return nil, fmt.Errorf("range must be a whole octet of address in %s", host)
+	}
+	suffixes, err := ExpandHostRange(host[loc[1]:])
+	if err != nil {
+		return nil, err
+	}
+	if n := (to - from + 1) * len(suffixes); n > maxHostRangeSize {
+		return nil, fmt.Errorf("%s expands into %d hosts, more than %d", host, n, maxHostRangeSize)
+	}
+	var ret []string
+	for i := from; i <= to; i++ {
+		for _, suffix := range suffixes {
+			ret = append(ret, host[:loc[0]]+strconv.Itoa(i)+suffix)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,146 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clusterfile
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestExpandHostRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "no range", host: "192.168.0.2:22", want: []string{"192.168.0.2:22"}},
+		{name: "range with port", host: "192.168.0.[10:12]:22", want: []string{"192.168.0.10:22", "192.168.0.11:22", "192.168.0.12:22"}},
+		{name: "multiple ranges", host: "10.0.[1:2].[5:6]", want: []string{"10.0.1.5", "10.0.1.6", "10.0.2.5", "10.0.2.6"}},
+		{name: "ipv6", host: "[fe80::1]:22", want: []string{"[fe80::1]:22"}},
+		{name: "reversed range", host: "192.168.0.[12:10]", wantErr: true},
+		{name: "octet out of range", host: "192.168.0.[250:256]", wantErr: true},
+		{name: "partial octet", host: "192.168.0.2[0:9]", wantErr: true},
+		{name: "too many hosts", host: "10.0.[0:255].[0:255]", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandHostRange(tt.host)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExpandHostRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExpandHostRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandHostGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   []v2.Host
+		groups  []v2.HostGroup
+		want    []v2.Host
+		wantErr bool
+	}{
+		{
+			name: "inheritance",
+			hosts: []v2.Host{
+				{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.MASTER}},
+			},
+			groups: []v2.HostGroup{
+				{
+					Name:     "workers",
+					Children: []string{"gpu"},
+					Roles:    []string{v2.NODE},
+					Env:      []string{"DISK=/dev/sdb"},
+					Labels:   map[string]string{"zone": "a", "tier": "worker"},
+					SSH:      &v2.SSH{User: "admin"},
+				},
+				{
+					Name:   "gpu",
+					Hosts:  []string{"192.168.0.[10:11]:22"},
+					Env:    []string{"DISK=/dev/nvme0n1"},
+					Labels: map[string]string{"tier": "gpu"},
+					Taints: []string{"nvidia.com/gpu=:NoSchedule"},
+				},
+			},
+			want: []v2.Host{
+				{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.MASTER}},
+				{
+					IPS:    []string{"192.168.0.10:22", "192.168.0.11:22"},
+					Roles:  []string{v2.NODE, "workers", "gpu"},
+					Env:    []string{"DISK=/dev/sdb", "DISK=/dev/nvme0n1"},
+					Labels: map[string]string{"zone": "a", "tier": "gpu"},
+					Taints: []string{"nvidia.com/gpu=:NoSchedule"},
+					SSH:    &v2.SSH{User: "admin"},
+				},
+			},
+		},
+		{
+			name:    "duplicated host",
+			hosts:   []v2.Host{{IPS: []string{"192.168.0.10:22"}, Roles: []string{v2.MASTER}}},
+			groups:  []v2.HostGroup{{Name: "workers", Hosts: []string{"192.168.0.[10:11]:22"}, Roles: []string{v2.NODE}}},
+			wantErr: true,
+		},
+		{
+			name: "no master or node role",
+			groups: []v2.HostGroup{
+				{Name: "workers", Children: []string{"gpu"}, Roles: []string{v2.NODE}, Hosts: []string{"192.168.0.10:22"}},
+				{Name: "gpu", Hosts: []string{"192.168.0.11:22"}},
+				{Name: "storage", Hosts: []string{"192.168.0.12:22"}, Roles: []string{"ceph"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "unknown child",
+			groups:  []v2.HostGroup{{Name: "workers", Children: []string{"gpu"}}},
+			wantErr: true,
+		},
+		{
+			name: "cycle",
+			groups: []v2.HostGroup{
+				{Name: "a", Children: []string{"b"}},
+				{Name: "b", Children: []string{"a"}, Hosts: []string{"192.168.0.10:22"}, Roles: []string{v2.NODE}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := &v2.Cluster{}
+			cluster.Spec.Hosts = tt.hosts
+			cluster.Spec.HostGroups = tt.groups
+			err := ExpandHostGroups(cluster)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExpandHostGroups() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cluster.Spec.Hosts, tt.want) {
+				t.Errorf("ExpandHostGroups() = %+v, want %+v", cluster.Spec.Hosts, tt.want)
+			}
+			if cluster.Spec.HostGroups != nil {
+				t.Errorf("ExpandHostGroups() groups are not cleared")
+			}
+		})
+	}
+}
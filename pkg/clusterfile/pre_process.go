@@ -113,7 +113,7 @@ func (c *ClusterFile) loadRenderValues() (map[string]interface{}, error) {
 
 func (c *ClusterFile) decode(data []byte) error {
 	for _, fn := range []func([]byte) error{
-		c.DecodeCluster, c.DecodeConfigs, c.DecodeRuntimeConfig,
+		c.DecodeCluster, c.DecodeConfigs, c.DecodePlugins, c.DecodeRuntimeConfig,
 	} {
 		if err := fn(data); err != nil && err != ErrTypeNotFound {
 			return err
@@ -130,6 +130,12 @@ func (c *ClusterFile) DecodeCluster(data []byte) error {
 	if cluster == nil {
 		return ErrTypeNotFound
 	}
+	if err = ExpandHostGroups(cluster); err != nil {
+		return err
+	}
+	if err = validateHostKeyChecking(cluster); err != nil {
+		return err
+	}
 	c.cluster = cluster
 	return nil
 }
@@ -147,6 +153,18 @@ func (c *ClusterFile) DecodeConfigs(data []byte) error {
 	return nil
 }
 
+func (c *ClusterFile) DecodePlugins(data []byte) error {
+	plugins, err := CRDForBytes(data, constants.Plugin)
+	if err != nil {
+		return err
+	}
+	if plugins == nil {
+		return ErrTypeNotFound
+	}
+	c.plugins = plugins.([]v2.Plugin)
+	return nil
+}
+
 func (c *ClusterFile) DecodeRuntimeConfig(data []byte) error {
 	// TODO: handling more types of runtime configuration
 	cfg, _ := k3s.ParseConfig(data)
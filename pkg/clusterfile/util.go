@@ -65,6 +65,9 @@ func GetClusterFromFile(filepath string) (cluster *v2.Cluster, err error) {
 	if err = yaml2.UnmarshalFile(filepath, cluster); err != nil {
 		return nil, fmt.Errorf("failed to get cluster from %s, %v", filepath, err)
 	}
+	if err = ExpandHostGroups(cluster); err != nil {
+		return nil, fmt.Errorf("failed to expand host groups of %s, %v", filepath, err)
+	}
 	return cluster, nil
 }
 
@@ -87,3 +90,23 @@ func GetClusterFromDataCompatV1(data []byte) (*v2.Cluster, error) {
 	cluster = c.(*v2.Cluster)
 	return cluster, nil
 }
+
+// validateHostKeyChecking checks the host key checking modes of cluster when it is loaded,
+// rather than when the first host is connected.
+func validateHostKeyChecking(cluster *v2.Cluster) error {
+	configs := []*v2.SSH{&cluster.Spec.SSH}
+	for i := range cluster.Spec.Hosts {
+		if cluster.Spec.Hosts[i].SSH != nil {
+			configs = append(configs, cluster.Spec.Hosts[i].SSH)
+		}
+	}
+	for _, s := range configs {
+		switch s.HostKeyChecking {
+		case "", v2.HostKeyCheckingStrict, v2.HostKeyCheckingAcceptNew, v2.HostKeyCheckingInsecure:
+		default:
+			return fmt.Errorf("unsupported host key checking mode %s, available modes are: %s, %s, %s", s.HostKeyChecking,
+				v2.HostKeyCheckingStrict, v2.HostKeyCheckingAcceptNew, v2.HostKeyCheckingInsecure)
+		}
+	}
+	return nil
+}
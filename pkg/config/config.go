@@ -95,7 +95,7 @@ func (c *Dumper) Dump() error {
 
 func (c *Dumper) WriteFiles() (err error) {
 	for _, config := range c.Configs {
-		if config.Spec.Match != "" && config.Spec.Match != c.name {
+		if !IsMatched(config, c.name) {
 			continue
 		}
 		configData := []byte(config.Spec.Data)
@@ -121,6 +121,11 @@ func (c *Dumper) WriteFiles() (err error) {
 	return nil
 }
 
+// IsMatched returns true if the config should be dumped into the rootfs of the named image.
+func IsMatched(config v1beta1.Config, imageName string) bool {
+	return config.Spec.Match == "" || config.Spec.Match == imageName
+}
+
 func getAppendOrInsertConfigData(path string, data []byte, insert bool) ([]byte, error) {
 	var configs [][]byte
 	context, err := os.ReadFile(filepath.Clean(path))
@@ -39,6 +39,7 @@ const (
 const (
 	Config  = "Config"
 	Cluster = "Cluster"
+	Plugin  = "Plugin"
 )
 
 var AppName = "sealos"
@@ -64,6 +64,16 @@ func Clusterfile(clusterName string) string {
 	return filepath.Join(DefaultRuntimeRootDir, clusterName, DefaultClusterFileName)
 }
 
+// KnownHostsFile returns the file pinning host keys of the cluster.
+func KnownHostsFile(clusterName string) string {
+	return filepath.Join(DefaultRuntimeRootDir, clusterName, "known_hosts")
+}
+
+// RunLogsDir returns the directory keeping the per-host outputs of each apply run of the cluster.
+func RunLogsDir(clusterName string) string {
+	return filepath.Join(DefaultRuntimeRootDir, clusterName, "logs")
+}
+
 func GetRuntimeRootDir(name string) string {
 	if v, ok := os.LookupEnv(strings.ToUpper(name) + "_RUNTIME_ROOT"); ok {
 		return v
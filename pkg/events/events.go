@@ -0,0 +1,164 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events emits machine-readable events of the apply path as JSON lines,
+// so that CI systems driving sealos can tell which phase or host failed.
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+type Type string
+
+const (
+	PhaseStarted         Type = "PhaseStarted"
+	PhaseFinished        Type = "PhaseFinished"
+	PhaseSkipped         Type = "PhaseSkipped"
+	CommandStarted       Type = "CommandStarted"
+	CommandFinished      Type = "CommandFinished"
+	RegistrySyncStarted  Type = "RegistrySyncStarted"
+	RegistrySyncProgress Type = "RegistrySyncProgress"
+)
+
+// Event is a single line of the event stream, fields not related to the type are omitted.
+type Event struct {
+	Time time.Time `json:"time"`
+	Type Type      `json:"type"`
+	// Source is the component emitting the event, such as the processor or the bootstrap.
+	Source  string `json:"source,omitempty"`
+	Phase   string `json:"phase,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Command string `json:"command,omitempty"`
+	Image   string `json:"image,omitempty"`
+	// ExitCode is only set when the command is finished with a known exit code.
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Duration is the elapsed seconds of the finished phase or command.
+	Duration  float64 `json:"duration,omitempty"`
+	Completed int     `json:"completed,omitempty"`
+	Total     int     `json:"total,omitempty"`
+	// TransferredBytes and SavedBytes are the sizes of the blobs copied to and already existing on the host.
+	TransferredBytes int64  `json:"transferredBytes,omitempty"`
+	SavedBytes       int64  `json:"savedBytes,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+var (
+	mu  sync.Mutex
+	out io.Writer
+)
+
+// SetOutput sets the writer of the event stream, events are dropped if w is nil.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Enabled returns true if the event stream has an output.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return out != nil
+}
+
+// Emit writes the event as a JSON line, it is safe for concurrent use.
+func Emit(e Event) {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	// ignore any writer error, the event stream never breaks the apply
+	_, _ = out.Write(append(b, '\n'))
+}
+
+// StartPhase emits a PhaseStarted event and returns the function emitting the PhaseFinished event.
+// host is empty if the phase is not running on a single host.
+func StartPhase(source, phase, host string) func(err error) {
+	start := time.Now()
+	Emit(Event{Type: PhaseStarted, Source: source, Phase: phase, Host: host})
+	return func(err error) {
+		Emit(Event{
+			Type:     PhaseFinished,
+			Source:   source,
+			Phase:    phase,
+			Host:     host,
+			Duration: time.Since(start).Seconds(),
+			Error:    errorString(err),
+		})
+	}
+}
+
+// SkipPhase emits a PhaseSkipped event with the reason.
+func SkipPhase(source, phase, reason string) {
+	Emit(Event{Type: PhaseSkipped, Source: source, Phase: phase, Reason: reason})
+}
+
+// StartCommand emits a CommandStarted event and returns the function emitting the CommandFinished event.
+func StartCommand(host, command string) func(err error) {
+	start := time.Now()
+	Emit(Event{Type: CommandStarted, Host: host, Command: command})
+	return func(err error) {
+		Emit(Event{
+			Type:     CommandFinished,
+			Host:     host,
+			Command:  command,
+			ExitCode: ExitCode(err),
+			Duration: time.Since(start).Seconds(),
+			Error:    errorString(err),
+		})
+	}
+}
+
+// ExitCode returns the exit code carried by err, nil is returned if it is unknown,
+// e.g. the command is not started because of the connection error.
+func ExitCode(err error) *int {
+	code := 0
+	if err == nil {
+		return &code
+	}
+	// *os/exec.ExitError
+	var localErr interface{ ExitCode() int }
+	// *golang.org/x/crypto/ssh.ExitError
+	var remoteErr interface{ ExitStatus() int }
+	switch {
+	case errors.As(err, &localErr):
+		code = localErr.ExitCode()
+	case errors.As(err, &remoteErr):
+		code = remoteErr.ExitStatus()
+	default:
+		return nil
+	}
+	return &code
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,86 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+)
+
+func TestStartCommand(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	exitErr := exec.Command("/bin/sh", "-c", "exit 3").Run()
+	StartCommand("192.168.0.2:22", "exit 3")(fmt.Errorf("run command: %w", exitErr))
+	StartCommand("192.168.0.3:22", "echo")(nil)
+	StartCommand("192.168.0.4:22", "echo")(errors.New("connect error"))
+
+	var got []Event
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatalf("invalid event %s: %v", scanner.Text(), err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 6 {
+		t.Fatalf("got %d events, want 6", len(got))
+	}
+	tests := []struct {
+		event    Event
+		wantType Type
+		wantCode *int
+	}{
+		{event: got[0], wantType: CommandStarted},
+		{event: got[1], wantType: CommandFinished, wantCode: intPtr(3)},
+		{event: got[3], wantType: CommandFinished, wantCode: intPtr(0)},
+		{event: got[5], wantType: CommandFinished},
+	}
+	for i, tt := range tests {
+		if tt.event.Type != tt.wantType {
+			t.Errorf("event %d type = %s, want %s", i, tt.event.Type, tt.wantType)
+		}
+		switch {
+		case tt.wantCode == nil && tt.event.ExitCode != nil:
+			t.Errorf("event %d exit code = %d, want nil", i, *tt.event.ExitCode)
+		case tt.wantCode != nil && (tt.event.ExitCode == nil || *tt.event.ExitCode != *tt.wantCode):
+			t.Errorf("event %d exit code = %v, want %d", i, tt.event.ExitCode, *tt.wantCode)
+		}
+	}
+	if got[5].Error != "connect error" {
+		t.Errorf("event error = %s, want connect error", got[5].Error)
+	}
+}
+
+func TestEmitDisabled(t *testing.T) {
+	SetOutput(nil)
+	if Enabled() {
+		t.Errorf("Enabled() = true, want false")
+	}
+	// must not panic without output
+	StartPhase("CreateProcessor", "Init", "")(nil)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -17,6 +17,7 @@ package exec
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -25,7 +26,9 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	"github.com/labring/sealos/pkg/events"
 	"github.com/labring/sealos/pkg/ssh"
+	"github.com/labring/sealos/pkg/transcript"
 	"github.com/labring/sealos/pkg/unshare"
 	fileutil "github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/iputils"
@@ -59,23 +62,33 @@ func (w *wrap) Ping(host string) error {
 	return w.inner.Ping(host)
 }
 
-func (w *wrap) Cmd(host string, command string) ([]byte, error) {
+func (w *wrap) Cmd(host string, command string) (b []byte, err error) {
+	finish := events.StartCommand(host, command)
+	defer func() { finish(err) }()
 	if w.isLocal(host) {
+		record := transcript.StartCommand(host, command)
+		defer func() { record(err) }()
 		// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
-		b, err := exec.Command("/bin/bash", "-c", command).CombinedOutput()
+		b, err = exec.Command("/bin/bash", "-c", command).CombinedOutput()
+		_, _ = transcript.Writer(host).Write(b)
 		return b, err
 	}
 	return w.inner.Cmd(host, command)
 }
 
-func (w *wrap) CmdAsyncWithContext(ctx context.Context, host string, commands ...string) error {
+func (w *wrap) CmdAsyncWithContext(ctx context.Context, host string, commands ...string) (err error) {
+	finish := events.StartCommand(host, strings.Join(commands, "; "))
+	defer func() { finish(err) }()
 	if w.isLocal(host) {
+		record := transcript.StartCommand(host, strings.Join(commands, "; "))
+		defer func() { record(err) }()
+		log := transcript.Writer(host)
 		for i := range commands {
 			// nosemgrep: go.lang.security.audit.dangerous-exec-command.dangerous-exec-command
 			cmd := exec.CommandContext(ctx, "/bin/bash", "-c", commands[i])
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
+			cmd.Stdout = io.MultiWriter(os.Stdout, log)
+			cmd.Stderr = io.MultiWriter(os.Stderr, log)
+			if err = cmd.Run(); err != nil {
 				return err
 			}
 		}
@@ -0,0 +1,168 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/opencontainers/go-digest"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const remoteProbeTimeout = 10 * time.Second
+
+// syncPlan is the images to copy to a host, and the sizes of the blobs to transfer or already
+// existing on the host, manifests are counted as blobs.
+type syncPlan struct {
+	images      []taggedImage
+	total       int
+	transferred int64
+	saved       int64
+}
+
+func (p *syncPlan) upToDate() int {
+	return p.total - len(p.images)
+}
+
+// remoteRegistry checks the content of the registry on the target host with the registry API.
+type remoteRegistry struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newRemoteRegistry(addr string) *remoteRegistry {
+	return &remoteRegistry{
+		endpoint: "http://" + addr,
+		client:   &http.Client{Timeout: remoteProbeTimeout},
+	}
+}
+
+func (r *remoteRegistry) head(ctx context.Context, path string, accept ...string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, r.endpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+// manifestDigest returns the digest of the manifest referenced by a tag or digest, it is empty
+// if the manifest does not exist.
+func (r *remoteRegistry) manifestDigest(ctx context.Context, repo, reference string) (digest.Digest, error) {
+	resp, err := r.head(ctx, fmt.Sprintf("/v2/%s/manifests/%s", repo, reference), manifest.DefaultRequestedManifestMIMETypes...)
+	if err != nil {
+		return "", err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return digest.Digest(resp.Header.Get("Docker-Content-Digest")), nil
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unexpected status %s of manifest %s:%s", resp.Status, repo, reference)
+	}
+}
+
+func (r *remoteRegistry) hasBlob(ctx context.Context, repo string, dgst digest.Digest) (bool, error) {
+	resp, err := r.head(ctx, fmt.Sprintf("/v2/%s/blobs/%s", repo, dgst))
+	if err != nil {
+		return false, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %s of blob %s@%s", resp.Status, repo, dgst)
+	}
+}
+
+// planSync compares the images in storage with the ones on the remote registry. An image is up to date
+// if the remote tag references the same manifest, otherwise the blobs missing on the remote registry
+// are counted to transfer. A blob shared by images is only counted once.
+func planSync(ctx context.Context, st *storage, remote *remoteRegistry) (*syncPlan, error) {
+	images, err := st.images()
+	if err != nil {
+		return nil, err
+	}
+	sizes, err := st.blobs()
+	if err != nil {
+		return nil, err
+	}
+	plan := &syncPlan{total: len(images)}
+	counted := make(map[digest.Digest]bool)
+	for _, img := range images {
+		manifests, blobs, err := st.references(img.digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest of image %s: %v", img, err)
+		}
+		remoteDigest, err := remote.manifestDigest(ctx, img.repo, img.tag)
+		if err != nil {
+			logger.Debug("failed to check image %s on remote registry: %v", img, err)
+		}
+		upToDate := remoteDigest == img.digest
+		if !upToDate {
+			plan.images = append(plan.images, img)
+		}
+		exists := func(dgst digest.Digest, isManifest bool) bool {
+			if upToDate {
+				return true
+			}
+			var (
+				ok  bool
+				err error
+			)
+			if isManifest {
+				var d digest.Digest
+				d, err = remote.manifestDigest(ctx, img.repo, dgst.String())
+				ok = d != ""
+			} else {
+				ok, err = remote.hasBlob(ctx, img.repo, dgst)
+			}
+			if err != nil {
+				logger.Debug("failed to check %s of image %s on remote registry: %v", dgst, img, err)
+			}
+			return ok
+		}
+		for i, refs := range [][]digest.Digest{manifests, blobs} {
+			for _, dgst := range refs {
+				if counted[dgst] {
+					continue
+				}
+				counted[dgst] = true
+				if exists(dgst, i == 0) {
+					plan.saved += sizes[dgst]
+				} else {
+					plan.transferred += sizes[dgst]
+				}
+			}
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,130 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+var registryPathRegexp = regexp.MustCompile(`^/v2/(.+)/(manifests|blobs)/([^/]+)$`)
+
+// fakeRegistry answers HEAD requests of manifests and blobs, tags are mapped to manifest digests.
+func fakeRegistry(tags map[string]digest.Digest, existing map[digest.Digest]bool) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m := registryPathRegexp.FindStringSubmatch(r.URL.Path)
+		if r.Method != http.MethodHead || m == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		dgst := digest.Digest(m[3])
+		if m[2] == "manifests" && !strings.HasPrefix(m[3], "sha256:") {
+			dgst = tags[m[1]+":"+m[3]]
+		}
+		if dgst == "" || !existing[dgst] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func TestPlanSync(t *testing.T) {
+	dir := t.TempDir()
+	pause, pauseBlobs := writeImage(t, dir, "library/pause", "3.9", "pause layer")
+	coredns, corednsBlobs := writeImage(t, dir, "coredns/coredns", "v1.10.1", "coredns layer 1", "coredns layer 2")
+	s := newStorage(dir)
+	sizes, err := s.blobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all int64
+	for _, size := range sizes {
+		all += size
+	}
+
+	tests := []struct {
+		name         string
+		tags         map[string]digest.Digest
+		existing     []digest.Digest
+		wantImages   []string
+		wantSavedOf  []digest.Digest
+		wantUpToDate int
+	}{
+		{
+			name:       "empty registry",
+			wantImages: []string{"coredns/coredns:v1.10.1", "library/pause:3.9"},
+		},
+		{
+			name:         "all images are up to date",
+			tags:         map[string]digest.Digest{"library/pause:3.9": pause, "coredns/coredns:v1.10.1": coredns},
+			existing:     append([]digest.Digest{pause, coredns}, append(pauseBlobs, corednsBlobs...)...),
+			wantSavedOf:  append([]digest.Digest{pause, coredns}, append(pauseBlobs, corednsBlobs[1:]...)...),
+			wantUpToDate: 2,
+		},
+		{
+			name:         "tag is outdated but layers exist",
+			tags:         map[string]digest.Digest{"library/pause:3.9": pause, "coredns/coredns:v1.10.1": digest.FromString("old")},
+			existing:     append([]digest.Digest{pause, digest.FromString("old")}, append(pauseBlobs, corednsBlobs[1])...),
+			wantImages:   []string{"coredns/coredns:v1.10.1"},
+			wantSavedOf:  append([]digest.Digest{pause, corednsBlobs[1]}, pauseBlobs...),
+			wantUpToDate: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing := make(map[digest.Digest]bool)
+			for _, dgst := range tt.existing {
+				existing[dgst] = true
+			}
+			server := fakeRegistry(tt.tags, existing)
+			defer server.Close()
+
+			plan, err := planSync(context.Background(), s, newRemoteRegistry(strings.TrimPrefix(server.URL, "http://")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var images []string
+			for _, img := range plan.images {
+				images = append(images, img.String())
+			}
+			sort.Strings(images)
+			if strings.Join(images, ",") != strings.Join(tt.wantImages, ",") {
+				t.Errorf("images = %v, want %v", images, tt.wantImages)
+			}
+			if plan.upToDate() != tt.wantUpToDate {
+				t.Errorf("upToDate() = %d, want %d", plan.upToDate(), tt.wantUpToDate)
+			}
+			var saved int64
+			for _, dgst := range tt.wantSavedOf {
+				saved += sizes[dgst]
+			}
+			if plan.saved != saved {
+				t.Errorf("saved = %d, want %d", plan.saved, saved)
+			}
+			if plan.saved+plan.transferred != all {
+				t.Errorf("saved + transferred = %d, want the size of all blobs %d", plan.saved+plan.transferred, all)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// storageRootDir is the root of the filesystem storage driver of the distribution registry.
+const storageRootDir = "docker/registry/v2"
+
+// storage reads the content-addressed layout of a registry directory:
+//
+//	blobs/sha256/<first two hex>/<hex>/data
+//	repositories/<name>/_manifests/tags/<tag>/current/link
+type storage struct {
+	root string
+}
+
+func newStorage(registryDir string) *storage {
+	return &storage{root: filepath.Join(registryDir, storageRootDir)}
+}
+
+// taggedImage is a tag of repository and the digest of its manifest.
+type taggedImage struct {
+	repo   string
+	tag    string
+	digest digest.Digest
+}
+
+func (i taggedImage) String() string {
+	return i.repo + ":" + i.tag
+}
+
+func (s *storage) blobsDir() string {
+	return filepath.Join(s.root, "blobs")
+}
+
+func (s *storage) repositoriesDir() string {
+	return filepath.Join(s.root, "repositories")
+}
+
+func (s *storage) blobPath(dgst digest.Digest) string {
+	return filepath.Join(s.blobsDir(), dgst.Algorithm().String(), dgst.Encoded()[:2], dgst.Encoded(), "data")
+}
+
+// blobs returns the sizes of all blobs in storage, manifests are blobs as well.
+func (s *storage) blobs() (map[digest.Digest]int64, error) {
+	ret := make(map[digest.Digest]int64)
+	err := filepath.WalkDir(s.blobsDir(), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "data" {
+			return nil
+		}
+		dgst, ok := blobDigestFromPath(path)
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		ret[dgst] = info.Size()
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return ret, nil
+	}
+	return ret, err
+}
+
+// blobDigestFromPath returns the digest of .../<algorithm>/<xx>/<hex>/data.
+func blobDigestFromPath(path string) (digest.Digest, bool) {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	if len(parts) < 4 {
+		return "", false
+	}
+	dgst := digest.NewDigestFromEncoded(digest.Algorithm(parts[len(parts)-4]), parts[len(parts)-2])
+	return dgst, dgst.Validate() == nil
+}
+
+// images returns all tagged images in storage.
+func (s *storage) images() ([]taggedImage, error) {
+	var ret []taggedImage
+	reposDir := s.repositoriesDir()
+	err := filepath.WalkDir(reposDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || !strings.HasPrefix(d.Name(), "_") {
+			return nil
+		}
+		if d.Name() == "_manifests" {
+			repo, err := filepath.Rel(reposDir, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			tags, err := s.tags(path)
+			if err != nil {
+				return err
+			}
+			for tag, dgst := range tags {
+				ret = append(ret, taggedImage{repo: filepath.ToSlash(repo), tag: tag, digest: dgst})
+			}
+		}
+		// _layers and _uploads have no nested repositories
+		return filepath.SkipDir
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return ret, err
+}
+
+func (s *storage) tags(manifestsDir string) (map[string]digest.Digest, error) {
+	tagsDir := filepath.Join(manifestsDir, "tags")
+	entries, err := os.ReadDir(tagsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	ret := make(map[string]digest.Digest)
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(tagsDir, entry.Name(), "current", "link"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		dgst, err := digest.Parse(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid link of tag %s: %v", entry.Name(), err)
+		}
+		ret[entry.Name()] = dgst
+	}
+	return ret, nil
+}
+
+// references returns the digests of manifest and its child manifests, and the blobs referenced by them.
+// The child manifests of an index are only included if they are in storage.
+func (s *storage) references(dgst digest.Digest) (manifests []digest.Digest, blobs []digest.Digest, err error) {
+	if err = dgst.Validate(); err != nil {
+		return nil, nil, err
+	}
+	b, err := os.ReadFile(s.blobPath(dgst))
+	if err != nil {
+		return nil, nil, err
+	}
+	manifests = append(manifests, dgst)
+	if manifest.MIMETypeIsMultiImage(manifest.GuessMIMEType(b)) {
+		var index ocispec.Index
+		if err = json.Unmarshal(b, &index); err != nil {
+			return nil, nil, fmt.Errorf("invalid manifest list %s: %v", dgst, err)
+		}
+		for _, m := range index.Manifests {
+			childManifests, childBlobs, err := s.references(m.Digest)
+			if os.IsNotExist(err) {
+				// only the platforms in use are saved
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			manifests = append(manifests, childManifests...)
+			blobs = append(blobs, childBlobs...)
+		}
+		return manifests, blobs, nil
+	}
+	var m ocispec.Manifest
+	if err = json.Unmarshal(b, &m); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest %s: %v", dgst, err)
+	}
+	if m.Config.Digest != "" {
+		blobs = append(blobs, m.Config.Digest)
+	}
+	for _, l := range m.Layers {
+		blobs = append(blobs, l.Digest)
+	}
+	return manifests, blobs, nil
+}
+
+// ListImages returns the tagged images saved in the registry dir of an image, as repository:tag.
+func ListImages(registryDir string) ([]string, error) {
+	images, err := newStorage(registryDir).images()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(images))
+	for _, img := range images {
+		ret = append(ret, img.String())
+	}
+	return ret, nil
+}
@@ -0,0 +1,165 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// writeBlob writes content to the storage under dir and returns its digest.
+func writeBlob(t *testing.T, dir string, content []byte) digest.Digest {
+	t.Helper()
+	dgst := digest.FromBytes(content)
+	path := newStorage(dir).blobPath(dgst)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dgst
+}
+
+// writeImage writes an image with layers to the storage under dir and returns the digest of manifest
+// and the digests of its config and layers.
+func writeImage(t *testing.T, dir, repo, tag string, layers ...string) (digest.Digest, []digest.Digest) {
+	t.Helper()
+	config := writeBlob(t, dir, []byte(`{"architecture":"amd64","os":"linux"}`))
+	m := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: config},
+	}
+	m.SchemaVersion = 2
+	blobs := []digest.Digest{config}
+	for _, l := range layers {
+		dgst := writeBlob(t, dir, []byte(l))
+		m.Layers = append(m.Layers, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: dgst, Size: int64(len(l))})
+		blobs = append(blobs, dgst)
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dgst := writeBlob(t, dir, b)
+	link := filepath.Join(newStorage(dir).repositoriesDir(), repo, "_manifests", "tags", tag, "current", "link")
+	if err = os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(link, []byte(dgst), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dgst, blobs
+}
+
+func TestStorage(t *testing.T) {
+	dir := t.TempDir()
+	pause, pauseBlobs := writeImage(t, dir, "library/pause", "3.9", "pause layer")
+	coredns, _ := writeImage(t, dir, "coredns/coredns", "v1.10.1", "coredns layer 1", "coredns layer 2")
+	if err := os.MkdirAll(filepath.Join(newStorage(dir).repositoriesDir(), "library/pause/_layers/sha256"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	s := newStorage(dir)
+
+	images, err := s.images()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].repo < images[j].repo })
+	want := []taggedImage{
+		{repo: "coredns/coredns", tag: "v1.10.1", digest: coredns},
+		{repo: "library/pause", tag: "3.9", digest: pause},
+	}
+	if len(images) != len(want) {
+		t.Fatalf("images() = %v, want %v", images, want)
+	}
+	for i := range want {
+		if images[i] != want[i] {
+			t.Errorf("images()[%d] = %+v, want %+v", i, images[i], want[i])
+		}
+	}
+
+	blobs, err := s.blobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the config is shared by both images
+	if len(blobs) != 6 {
+		t.Errorf("got %d blobs, want 6", len(blobs))
+	}
+	if blobs[pauseBlobs[1]] != int64(len("pause layer")) {
+		t.Errorf("size of layer = %d, want %d", blobs[pauseBlobs[1]], len("pause layer"))
+	}
+
+	manifests, refs, err := s.references(pause)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 1 || manifests[0] != pause {
+		t.Errorf("references() manifests = %v, want [%s]", manifests, pause)
+	}
+	if len(refs) != len(pauseBlobs) {
+		t.Errorf("references() blobs = %v, want %v", refs, pauseBlobs)
+	}
+	if _, _, err = s.references("sha256:invalid"); err == nil {
+		t.Error("references() should fail for invalid digest")
+	}
+}
+
+func TestStorageIndex(t *testing.T) {
+	dir := t.TempDir()
+	amd64, amd64Blobs := writeImage(t, dir, "library/pause", "amd64", "amd64 layer")
+	index := ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{
+			{MediaType: ocispec.MediaTypeImageManifest, Digest: amd64},
+			// the platform not saved
+			{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("arm64")},
+		},
+	}
+	index.SchemaVersion = 2
+	b, err := json.Marshal(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dgst := writeBlob(t, dir, b)
+
+	manifests, blobs, err := newStorage(dir).references(dgst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifests) != 2 || manifests[0] != dgst || manifests[1] != amd64 {
+		t.Errorf("references() manifests = %v, want [%s %s]", manifests, dgst, amd64)
+	}
+	if len(blobs) != len(amd64Blobs) {
+		t.Errorf("references() blobs = %v, want %v", blobs, amd64Blobs)
+	}
+}
+
+func TestStorageNotExist(t *testing.T) {
+	s := newStorage(t.TempDir())
+	if images, err := s.images(); err != nil || len(images) != 0 {
+		t.Errorf("images() = %v, %v, want no images", images, err)
+	}
+	if blobs, err := s.blobs(); err != nil || len(blobs) != 0 {
+		t.Errorf("blobs() = %v, %v, want no blobs", blobs, err)
+	}
+}
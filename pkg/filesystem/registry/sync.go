@@ -19,18 +19,26 @@ package registry
 import (
 	"context"
 	"fmt"
+	"io/fs"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/containers/common/pkg/retry"
 	"github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/signature"
 	"github.com/containers/image/v5/types"
-	"golang.org/x/sync/errgroup"
+	"github.com/docker/go-units"
+	"github.com/opencontainers/go-digest"
 
 	"github.com/labring/sreg/pkg/registry/handler"
 	"github.com/labring/sreg/pkg/registry/sync"
 
 	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/events"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/filesystem"
 	"github.com/labring/sealos/pkg/ssh"
@@ -38,6 +46,7 @@ import (
 	"github.com/labring/sealos/pkg/utils/file"
 	httputils "github.com/labring/sealos/pkg/utils/http"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 const (
@@ -108,24 +117,50 @@ func (s *impl) Sync(ctx context.Context, hosts ...string) error {
 		}
 	}()
 
-	eg, _ := errgroup.WithContext(ctx)
+	var mounts []v2.MountImage
+	for j := range s.mounts {
+		if file.IsDir(filepath.Join(s.mounts[j].MountPoint, constants.RegistryDirName)) {
+			mounts = append(mounts, s.mounts[j])
+		}
+	}
+	total := len(hosts) * len(mounts)
+	var completed int32
+	events.Emit(events.Event{Type: events.RegistrySyncStarted, Total: total})
+
+	eg, _ := parallel.WithContext(ctx)
 	for i := 0; i < len(hosts); i++ {
 		opt, ok := <-syncOptionChan
 		if !ok {
 			break
 		}
-		for j := range s.mounts {
-			registryDir := filepath.Join(s.mounts[j].MountPoint, constants.RegistryDirName)
-			if !file.IsDir(registryDir) {
-				continue
-			}
+		for j := range mounts {
+			mount := mounts[j]
+			registryDir := filepath.Join(mount.MountPoint, constants.RegistryDirName)
 			eg.Go(func() (err error) {
+				var plan *syncPlan
 				switch opt.typ {
 				case httpMode:
-					err = syncViaHTTP(ctx, opt.target, registryDir)
+					plan, err = syncViaHTTP(ctx, opt.target, registryDir)
 				case sshMode:
-					err = syncViaSSH(ctx, s, opt.target, registryDir)
+					plan, err = syncViaSSH(ctx, s, opt.target, registryDir)
+				}
+				e := events.Event{
+					Type:      events.RegistrySyncProgress,
+					Host:      opt.target,
+					Image:     mount.ImageName,
+					Completed: int(atomic.AddInt32(&completed, 1)),
+					Total:     total,
+				}
+				if plan != nil {
+					e.TransferredBytes = plan.transferred
+					e.SavedBytes = plan.saved
+					logger.Info("synced registry of %s to %s, %s transferred and %s already exists",
+						mount.ImageName, opt.target, units.HumanSize(float64(plan.transferred)), units.HumanSize(float64(plan.saved)))
 				}
+				if err != nil {
+					e.Error = err.Error()
+				}
+				events.Emit(e)
 				return
 			})
 		}
@@ -146,18 +181,83 @@ func getRegistryServeCommand(pathResolver constants.PathResolver, port string) s
 	)
 }
 
-func syncViaSSH(_ context.Context, s *impl, target string, localDir string) error {
-	return ssh.CopyDir(s.execer, target, localDir, s.pathResolver.RootFSRegistryPath(), nil)
+// syncViaSSH copies the blobs missing on target or having a different size, and then the tags
+// and other files of the registry dir which are small.
+func syncViaSSH(_ context.Context, s *impl, target string, localDir string) (*syncPlan, error) {
+	remoteDir := s.pathResolver.RootFSRegistryPath()
+	local, remote := newStorage(localDir), newStorage(remoteDir)
+	if !file.IsDir(local.blobsDir()) {
+		return nil, ssh.CopyDir(s.execer, target, localDir, remoteDir, nil)
+	}
+	sizes, err := local.blobs()
+	if err != nil {
+		return nil, err
+	}
+	remoteSizes, err := remoteBlobs(s.execer, target, remote.blobsDir())
+	if err != nil {
+		logger.Warn("failed to list blobs of registry on %s, copying all blobs: %v", target, err)
+	}
+	plan := &syncPlan{}
+	for dgst, size := range sizes {
+		if size == remoteSizes[dgst] {
+			plan.saved += size
+			continue
+		}
+		if err = s.execer.Copy(target, filepath.Dir(local.blobPath(dgst)), filepath.Dir(remote.blobPath(dgst))); err != nil {
+			return plan, fmt.Errorf("failed to copy blob %s to %s: %v", dgst, target, err)
+		}
+		plan.transferred += size
+	}
+	// tags are linked after blobs, so that no tag references a missing blob
+	if file.IsDir(local.repositoriesDir()) {
+		if err = ssh.CopyDir(s.execer, target, local.repositoriesDir(), remote.repositoriesDir(), nil); err != nil {
+			return plan, err
+		}
+	}
+	return plan, ssh.CopyDir(s.execer, target, localDir, remoteDir, func(entry fs.DirEntry) bool {
+		// the parent dir of storage
+		return entry.Name() != "docker"
+	})
 }
 
-func syncViaHTTP(ctx context.Context, target string, localDir string) error {
-	sys := &types.SystemContext{
-		DockerInsecureSkipTLSVerify: types.OptionalBoolTrue,
+// remoteBlobs returns the sizes of blobs in the blobs dir of target.
+func remoteBlobs(execer exec.Interface, target string, blobsDir string) (map[digest.Digest]int64, error) {
+	out, err := execer.Cmd(target, fmt.Sprintf("if [ -d %[1]s ]; then find %[1]s -type f -name data -exec stat -c '%%s %%n' {} +; fi", blobsDir))
+	if err != nil {
+		return nil, err
+	}
+	ret := make(map[digest.Digest]int64)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if dgst, ok := blobDigestFromPath(fields[1]); ok {
+			ret[dgst] = size
+		}
+	}
+	return ret, nil
+}
+
+// syncViaHTTP copies the images which are missing or outdated on target, the local registry
+// is only served if there is any.
+func syncViaHTTP(ctx context.Context, target string, localDir string) (*syncPlan, error) {
+	plan, err := planSync(ctx, newStorage(localDir), newRemoteRegistry(target))
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("%d of %d images of %s are up to date on %s", plan.upToDate(), plan.total, localDir, target)
+	if len(plan.images) == 0 {
+		return plan, nil
 	}
 
 	config, err := handler.NewConfig(localDir, 0)
 	if err != nil {
-		return err
+		return plan, err
 	}
 	config.Log.AccessLog.Disabled = true
 	errCh := handler.Run(ctx, config)
@@ -170,21 +270,54 @@ func syncViaHTTP(ctx context.Context, target string, localDir string) error {
 	probeCtx, cancel := context.WithTimeout(ctx, time.Second*3)
 	defer cancel()
 	if err = httputils.WaitUntilEndpointAlive(probeCtx, "http://"+src); err != nil {
-		return err
-	}
-	opts := &sync.Options{
-		SystemContext: sys,
-		Source:        src,
-		Target:        target,
-		SelectionOptions: []copy.ImageListSelection{
-			copy.CopyAllImages, copy.CopySystemImage,
-		},
-		OmitError: true,
+		return plan, err
 	}
+	return plan, copyImages(ctx, src, target, plan.images)
+}
 
-	if err = sync.ToRegistry(ctx, opts); err != nil && !strings.Contains(err.Error(), "manifest unknown") {
+// copyImages copies images from the src registry to the target one, the layers already existing
+// on target are skipped by copy.Image. Like sync.ToRegistry, an image failed to copy is only
+// warned, it is copied again by the next sync.
+func copyImages(ctx context.Context, src, target string, images []taggedImage) error {
+	sys := &types.SystemContext{
+		DockerInsecureSkipTLSVerify: types.OptionalBoolTrue,
+	}
+	policyContext, err := signature.NewPolicyContext(&signature.Policy{
+		Default: []signature.PolicyRequirement{signature.NewPRInsecureAcceptAnything()},
+	})
+	if err != nil {
 		return err
 	}
+	defer func() {
+		_ = policyContext.Destroy()
+	}()
+	for _, img := range images {
+		srcRef, err := docker.ParseReference(fmt.Sprintf("//%s/%s", src, img))
+		if err != nil {
+			return err
+		}
+		destRef, err := docker.ParseReference(fmt.Sprintf("//%s/%s", target, img))
+		if err != nil {
+			return err
+		}
+		// only the platforms in use are saved, fall back to copy the image of system platform
+		for _, selection := range []copy.ImageListSelection{copy.CopyAllImages, copy.CopySystemImage} {
+			err = retry.RetryIfNecessary(ctx, func() error {
+				_, copyErr := copy.Image(ctx, policyContext, destRef, srcRef, &copy.Options{
+					SourceCtx:          sys,
+					DestinationCtx:     sys,
+					ImageListSelection: selection,
+				})
+				return copyErr
+			}, &retry.RetryOptions{MaxRetry: 3})
+			if err == nil || !strings.Contains(err.Error(), "manifest unknown") {
+				break
+			}
+		}
+		if err != nil {
+			logger.Warn("failed to copy image %s to %s: %v", img, target, err)
+		}
+	}
 	return nil
 }
 
@@ -36,6 +36,7 @@ import (
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
 	"github.com/labring/sealos/pkg/utils/maps"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	stringsutil "github.com/labring/sealos/pkg/utils/strings"
 )
 
@@ -114,9 +115,10 @@ func (f *defaultRootfs) mountRootfs(cluster *v2.Cluster, ipList []string) error
 	}
 	rootfsEnvs := v2.MergeEnvWithBuiltinKeys(rootfs.Env, *rootfs)
 
+	hostGroup, _ := parallel.WithContext(ctx)
 	for idx := range ipList {
 		ip := ipList[idx]
-		eg.Go(func() error {
+		hostGroup.Go(func() error {
 			var renderingRequired bool
 			for i := range f.mounts {
 				if f.mounts[i].IsRootFs() || f.mounts[i].IsPatch() {
@@ -138,7 +140,7 @@ func (f *defaultRootfs) mountRootfs(cluster *v2.Cluster, ipList []string) error
 			return execer.CmdAsync(ip, stringsutil.RenderShellWithEnv(renderCommand, envs))
 		})
 	}
-	if err := eg.Wait(); err != nil {
+	if err := hostGroup.Wait(); err != nil {
 		return err
 	}
 
@@ -176,7 +178,7 @@ func (f *defaultRootfs) unmountRootfs(cluster *v2.Cluster, ipList []string) erro
 	clusterRootfsDir := constants.NewPathResolver(cluster.Name).Root()
 	rmRootfs := fmt.Sprintf("rm -rf %s", clusterRootfsDir)
 	deleteHomeDirCmd := fmt.Sprintf("rm -rf %s", constants.ClusterDir(cluster.Name))
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	sshClient := ssh.NewCacheClientFromCluster(cluster, true)
 	execer, err := exec.New(sshClient)
 	if err != nil {
@@ -17,10 +17,9 @@ package guest
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/labring/sealos/fork/golang/expansion"
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/env"
@@ -28,12 +27,13 @@ import (
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/maps"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	stringsutil "github.com/labring/sealos/pkg/utils/strings"
 )
 
 type Interface interface {
 	Apply(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts []string) error
-	Delete(cluster *v2.Cluster) error
+	Delete(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts []string) error
 }
 
 type Default struct{}
@@ -53,7 +53,7 @@ func (d *Default) Apply(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts
 	for i, m := range mounts {
 		switch {
 		case m.IsRootFs(), m.IsPatch():
-			eg, ctx := errgroup.WithContext(context.Background())
+			eg, ctx := parallel.WithContext(context.Background())
 			for j := range targetHosts {
 				node := targetHosts[j]
 				envs := maps.Merge(m.Env, envGetter.Getenv(node))
@@ -116,6 +116,55 @@ func formalizeImageCommands(cluster *v2.Cluster, index int, m v2.MountImage, ext
 	return cmds
 }
 
-func (d Default) Delete(_ *v2.Cluster) error {
-	panic("not yet implemented")
+// Delete runs the uninstall commands of images in the reverse order of installation,
+// the working directories of application images are removed after uninstalled.
+func (d *Default) Delete(cluster *v2.Cluster, mounts []v2.MountImage, targetHosts []string) error {
+	envGetter := env.NewEnvProcessor(cluster)
+	sshClient := ssh.NewCacheClientFromCluster(cluster, true)
+	execer, err := exec.New(sshClient)
+	if err != nil {
+		return err
+	}
+
+	for i := len(mounts) - 1; i >= 0; i-- {
+		m := mounts[i]
+		if m.UninstallCommand() == "" {
+			return fmt.Errorf("image %s has no uninstall command, it should be set by label %s", m.ImageName, v2.ImageUninstallKeys[0])
+		}
+		switch {
+		case m.IsRootFs(), m.IsPatch():
+			eg, ctx := parallel.WithContext(context.Background())
+			for j := range targetHosts {
+				node := targetHosts[j]
+				envs := maps.Merge(m.Env, envGetter.Getenv(node))
+				cmd := formalizeUninstallCommand(cluster, m, envs)
+				eg.Go(func() error {
+					return execer.CmdAsyncWithContext(ctx, node, stringsutil.RenderShellWithEnv(cmd, envs))
+				})
+			}
+			if err := eg.Wait(); err != nil {
+				return err
+			}
+		case m.IsApplication():
+			master0 := cluster.GetMaster0IPAndPort()
+			envs := maps.Merge(m.Env, envGetter.Getenv(cluster.GetMaster0IP()))
+			cmd := formalizeUninstallCommand(cluster, m, envs)
+			if err := execer.CmdAsync(master0, stringsutil.RenderShellWithEnv(cmd, envs)); err != nil {
+				return err
+			}
+			appDir := filepath.Dir(constants.GetAppWorkDir(cluster.Name, m.Name))
+			if err := execer.CmdAsync(master0, fmt.Sprintf("rm -rf %s", appDir)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formalizeUninstallCommand expands the uninstall command of image with envs, which are already merged with
+// the env of image.
+func formalizeUninstallCommand(cluster *v2.Cluster, m v2.MountImage, envs map[string]string) string {
+	envs = v2.MergeEnvWithBuiltinKeys(envs, m)
+	mapping := expansion.MappingFuncFor(envs)
+	return formalizeWorkingCommand(cluster.Name, m.Name, m.Type, expansion.Expand(m.UninstallCommand(), mapping))
 }
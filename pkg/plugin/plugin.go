@@ -0,0 +1,202 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/env"
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	fileutil "github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/maps"
+	"github.com/labring/sealos/pkg/utils/parallel"
+	stringsutil "github.com/labring/sealos/pkg/utils/strings"
+)
+
+const (
+	// PhaseOriginally runs before the rootfs is sent to the hosts of a new cluster.
+	PhaseOriginally = "Originally"
+	// PhasePreInit runs before the first master is initialized.
+	PhasePreInit = "PreInit"
+	// PhasePreGuest runs before the CMD of images are executed.
+	PhasePreGuest = "PreGuest"
+	// PhasePostInstall runs after the cluster is created.
+	PhasePostInstall = "PostInstall"
+	// PhasePreJoin runs on the joining hosts before they join the cluster.
+	PhasePreJoin = "PreJoin"
+	// PhasePostJoin runs on the joined hosts after the CMD of images are executed.
+	PhasePostJoin = "PostJoin"
+	// PhasePreReset runs on the hosts before they are reset or deleted.
+	PhasePreReset = "PreReset"
+	// PhasePostReset runs on the hosts after they are reset or deleted, before the rootfs is removed.
+	PhasePostReset = "PostReset"
+)
+
+var phases = []string{
+	PhaseOriginally, PhasePreInit, PhasePreGuest, PhasePostInstall,
+	PhasePreJoin, PhasePostJoin, PhasePreReset, PhasePostReset,
+}
+
+const (
+	envPluginPhase = "SEALOS_PLUGIN_PHASE"
+	envPluginHost  = "SEALOS_PLUGIN_HOST"
+)
+
+type Interface interface {
+	// Run executes the plugins of phase on the hosts matching their roles.
+	Run(phase string, hosts ...string) error
+}
+
+type realPlugins struct {
+	cluster      *v2.Cluster
+	plugins      []v2.Plugin
+	execer       exec.Interface
+	envProcessor env.Interface
+	pathResolver constants.PathResolver
+}
+
+func New(cluster *v2.Cluster, plugins []v2.Plugin) (Interface, error) {
+	if err := Validate(plugins); err != nil {
+		return nil, err
+	}
+	execer, err := exec.New(ssh.NewCacheClientFromCluster(cluster, true))
+	if err != nil {
+		return nil, err
+	}
+	return &realPlugins{
+		cluster:      cluster,
+		plugins:      plugins,
+		execer:       execer,
+		envProcessor: env.NewEnvProcessor(cluster),
+		pathResolver: constants.NewPathResolver(cluster.GetName()),
+	}, nil
+}
+
+// Validate checks the type, phases and content of plugins.
+func Validate(plugins []v2.Plugin) error {
+	for _, p := range plugins {
+		if p.Name == "" {
+			return fmt.Errorf("plugin name cannot be empty")
+		}
+		if len(p.Spec.Phases) == 0 {
+			return fmt.Errorf("plugin %s has no phases", p.Name)
+		}
+		for _, phase := range p.Spec.Phases {
+			if !slices.Contains(phases, phase) {
+				return fmt.Errorf("unknown phase %s of plugin %s, available phases are: %s", phase, p.Name, strings.Join(phases, ","))
+			}
+		}
+		switch p.Spec.Type {
+		case v2.ShellPlugin, "":
+			if p.Spec.Data == "" {
+				return fmt.Errorf("shell plugin %s has no data", p.Name)
+			}
+		case v2.BinaryPlugin:
+			if !fileutil.IsExist(p.Spec.Path) {
+				return fmt.Errorf("binary %s of plugin %s is not exist", p.Spec.Path, p.Name)
+			}
+		default:
+			return fmt.Errorf("unknown type %s of plugin %s", p.Spec.Type, p.Name)
+		}
+	}
+	return nil
+}
+
+func (p *realPlugins) Run(phase string, hosts ...string) error {
+	for i := range p.plugins {
+		plugin := p.plugins[i]
+		if !slices.Contains(plugin.Spec.Phases, phase) {
+			continue
+		}
+		targets := FilterHostsByRoles(p.cluster, hosts, plugin.Spec.Roles)
+		if len(targets) == 0 {
+			continue
+		}
+		logger.Info("start to run plugin %s at phase %s on %v", plugin.Name, phase, targets)
+		if err := p.run(plugin, phase, targets); err != nil {
+			return fmt.Errorf("failed to run plugin %s at phase %s: %v", plugin.Name, phase, err)
+		}
+	}
+	return nil
+}
+
+func (p *realPlugins) run(plugin v2.Plugin, phase string, hosts []string) error {
+	src := plugin.Spec.Path
+	if plugin.Spec.Type != v2.BinaryPlugin {
+		f, err := os.CreateTemp("", fmt.Sprintf("sealos-plugin-%s-", plugin.Name))
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(plugin.Spec.Data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return err
+		}
+		src = f.Name()
+	}
+	dst := filepath.Join(p.pathResolver.Root(), "plugins", plugin.Name)
+	cmd := strings.Join(append([]string{dst}, plugin.Spec.Args...), " ")
+	if plugin.Spec.Type == v2.BinaryPlugin {
+		cmd = fmt.Sprintf("chmod +x %s && %s", dst, cmd)
+	} else {
+		cmd = "bash " + cmd
+	}
+
+	eg, _ := parallel.WithContext(context.Background())
+	for _, host := range hosts {
+		host := host
+		eg.Go(func() error {
+			if err := p.execer.Copy(host, src, dst); err != nil {
+				return fmt.Errorf("failed to copy plugin to %s: %v", host, err)
+			}
+			envs := maps.Merge(p.envProcessor.Getenv(host), map[string]string{
+				envPluginPhase: phase,
+				envPluginHost:  host,
+			})
+			return p.execer.CmdAsync(host, stringsutil.RenderShellWithEnv(cmd, envs))
+		})
+	}
+	return eg.Wait()
+}
+
+// FilterHostsByRoles returns the hosts having any of the roles, all hosts are returned if roles is empty.
+func FilterHostsByRoles(cluster *v2.Cluster, hosts []string, roles []string) []string {
+	if len(roles) == 0 {
+		return hosts
+	}
+	var ret []string
+	for _, host := range hosts {
+		for _, role := range cluster.GetRolesByIP(host) {
+			if slices.Contains(roles, role) {
+				ret = append(ret, host)
+				break
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,83 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestValidate(t *testing.T) {
+	binary := filepath.Join(t.TempDir(), "register")
+	if err := os.WriteFile(binary, []byte("#!/bin/sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	newPlugin := func(spec v2.PluginSpec) v2.Plugin {
+		return v2.Plugin{ObjectMeta: metav1.ObjectMeta{Name: "test"}, Spec: spec}
+	}
+	tests := []struct {
+		name    string
+		plugin  v2.Plugin
+		wantErr bool
+	}{
+		{name: "shell", plugin: newPlugin(v2.PluginSpec{Phases: []string{PhasePreInit}, Data: "echo hello"})},
+		{name: "binary", plugin: newPlugin(v2.PluginSpec{Type: v2.BinaryPlugin, Phases: []string{PhasePostJoin}, Path: binary})},
+		{name: "no phases", plugin: newPlugin(v2.PluginSpec{Data: "echo hello"}), wantErr: true},
+		{name: "unknown phase", plugin: newPlugin(v2.PluginSpec{Phases: []string{"PreHeat"}, Data: "echo hello"}), wantErr: true},
+		{name: "shell without data", plugin: newPlugin(v2.PluginSpec{Phases: []string{PhasePreInit}}), wantErr: true},
+		{name: "binary not exist", plugin: newPlugin(v2.PluginSpec{Type: v2.BinaryPlugin, Phases: []string{PhasePreInit}, Path: binary + ".bak"}), wantErr: true},
+		{name: "unknown type", plugin: newPlugin(v2.PluginSpec{Type: "python", Phases: []string{PhasePreInit}, Data: "print(1)"}), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate([]v2.Plugin{tt.plugin}); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFilterHostsByRoles(t *testing.T) {
+	cluster := &v2.Cluster{}
+	cluster.Spec.Hosts = []v2.Host{
+		{IPS: []string{"192.168.0.2:22"}, Roles: []string{v2.MASTER, "amd64"}},
+		{IPS: []string{"192.168.0.3:22", "192.168.0.4:22"}, Roles: []string{v2.NODE, "amd64"}},
+		{IPS: []string{"192.168.0.5:22"}, Roles: []string{v2.NODE, "gpu"}},
+	}
+	hosts := []string{"192.168.0.2:22", "192.168.0.3:22", "192.168.0.5:22"}
+	tests := []struct {
+		name  string
+		roles []string
+		want  []string
+	}{
+		{name: "all hosts", want: hosts},
+		{name: "masters", roles: []string{v2.MASTER}, want: []string{"192.168.0.2:22"}},
+		{name: "any of roles", roles: []string{v2.MASTER, "gpu"}, want: []string{"192.168.0.2:22", "192.168.0.5:22"}},
+		{name: "no matched", roles: []string{"arm64"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FilterHostsByRoles(cluster, hosts, tt.roles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterHostsByRoles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
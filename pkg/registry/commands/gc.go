@@ -0,0 +1,39 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/labring/sealos/pkg/registry/gc"
+)
+
+func NewRegistryGCCmd(examplePrefix string) *cobra.Command {
+	opts := gc.Options{}
+
+	var registryGCCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "delete images not referenced by the cluster from registry",
+		Long: `Delete the images which are neither saved in the current cluster images nor used by any pod
+from each registry host, and remove the blobs of them.`,
+		Example: examplePrefix + " gc --dry-run",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.Run(cmd.Context())
+		},
+	}
+	opts.RegisterFlags(registryGCCmd.Flags())
+	return registryGCCmd
+}
@@ -0,0 +1,122 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/docker/go-units"
+	"github.com/spf13/pflag"
+
+	"github.com/labring/sealos/pkg/client-go/kubernetes"
+	"github.com/labring/sealos/pkg/clusterfile"
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/registry/helpers"
+	"github.com/labring/sealos/pkg/ssh"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// garbageCollectCommand stops the registry while removing the blobs not referenced by any
+// manifest, so that no blob being uploaded is removed.
+const garbageCollectCommand = "systemctl stop registry && { registry garbage-collect %s; rc=$?; systemctl start registry; exit $rc; }"
+
+type Options struct {
+	ClusterName        string
+	RegistryConfigPath string
+	DryRun             bool
+	execer             exec.Interface
+}
+
+func (o *Options) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&o.ClusterName, "cluster-name", "c", "default", "cluster name")
+	fs.StringVar(&o.RegistryConfigPath, "registry-config-path", "/etc/registry/registry_config.yml", "config file path of registry on registry hosts")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "only print the images to delete and the size to reclaim")
+}
+
+// Run deletes the images not referenced by the mounted images or pods of the cluster from
+// each registry host, and then removes the blobs of them.
+func (o *Options) Run(ctx context.Context) error {
+	cluster, err := clusterfile.GetClusterFromName(o.ClusterName)
+	if err != nil {
+		return err
+	}
+	refs := newReferences()
+	if err = refs.addMounts(cluster.Status.Mounts); err != nil {
+		return err
+	}
+	client, err := kubernetes.NewKubernetesClient(constants.NewPathResolver(cluster.Name).AdminFile(), "")
+	if err != nil {
+		return err
+	}
+	if err = refs.addPods(ctx, client.Kubernetes()); err != nil {
+		return err
+	}
+	logger.Info("%d images are referenced by the mounted images and pods of cluster %s", refs.len(), cluster.Name)
+
+	if o.execer == nil {
+		if o.execer, err = exec.New(ssh.NewCacheClientFromCluster(cluster, true)); err != nil {
+			return err
+		}
+	}
+	root := constants.NewPathResolver(cluster.Name).RootFSPath()
+	info := helpers.GetRegistryInfo(o.execer, root, cluster.GetRegistryIPAndPortList()...)
+	for _, host := range cluster.GetRegistryIPAndPortList() {
+		c := newRegistryClient(ctx, net.JoinHostPort(iputils.GetHostIP(host), info.Port), info.Username, info.Password)
+		p, err := c.plan(refs)
+		if err != nil {
+			return fmt.Errorf("failed to plan garbage collection of registry on %s: %v", host, err)
+		}
+		if err = printPlan(os.Stdout, host, p); err != nil {
+			return err
+		}
+		if o.DryRun {
+			continue
+		}
+		if err = c.delete(p); err != nil {
+			return fmt.Errorf("failed to delete images of registry on %s: %v", host, err)
+		}
+		logger.Info("removing unreferenced blobs of registry on %s", host)
+		if err = o.execer.CmdAsync(host, fmt.Sprintf(garbageCollectCommand, o.RegistryConfigPath)); err != nil {
+			return fmt.Errorf("failed to run garbage collection of registry on %s: %v", host, err)
+		}
+	}
+	return nil
+}
+
+func printPlan(out io.Writer, host string, p *plan) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "REPOSITORY\tTAGS\tDIGEST")
+	for _, m := range p.deletions {
+		tags := "<none>"
+		if len(m.tags) > 0 {
+			tags = strings.Join(m.tags, ",")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", m.repo, tags, m.digest)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(out, "%d manifests of registry on %s are not referenced, about %s can be reclaimed\n",
+		len(p.deletions), host, units.HumanSize(float64(p.reclaimable)))
+	return err
+}
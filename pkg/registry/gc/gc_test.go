@@ -0,0 +1,183 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/opencontainers/go-digest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAddImage(t *testing.T) {
+	dgst := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		image       string
+		wantTag     string
+		wantDigest  string
+		wantInvalid bool
+	}{
+		{image: "nginx", wantTag: "library/nginx:latest"},
+		{image: "sealos.hub:5000/library/pause:3.9", wantTag: "library/pause:3.9"},
+		{image: "registry.k8s.io/coredns/coredns:v1.10.1", wantTag: "coredns/coredns:v1.10.1"},
+		{image: "docker.io/library/nginx@" + dgst, wantDigest: dgst},
+		{image: "nginx:1.25@" + dgst, wantTag: "library/nginx:1.25", wantDigest: dgst},
+		{image: "Invalid:Image", wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			r := newReferences()
+			err := r.addImage(tt.image)
+			if (err != nil) != tt.wantInvalid {
+				t.Fatalf("addImage() error = %v, wantInvalid %v", err, tt.wantInvalid)
+			}
+			if tt.wantTag != "" && !r.tags.Has(tt.wantTag) {
+				t.Errorf("tags = %v, want %s", r.tags.List(), tt.wantTag)
+			}
+			if tt.wantDigest != "" && !r.digests.Has(tt.wantDigest) {
+				t.Errorf("digests = %v, want %s", r.digests.List(), tt.wantDigest)
+			}
+		})
+	}
+}
+
+func TestAddPods(t *testing.T) {
+	dgst := "sha256:" + strings.Repeat("b", 64)
+	client := fake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+			Containers:     []corev1.Container{{Name: "nginx", Image: "nginx:1.25"}},
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{Name: "nginx", Image: "docker.io/library/nginx:1.25", ImageID: "docker.io/library/nginx@" + dgst}},
+		},
+	})
+	r := newReferences()
+	if err := r.addPods(context.Background(), client); err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"library/busybox:1.36", "library/nginx:1.25"} {
+		if !r.tags.Has(tag) {
+			t.Errorf("tags = %v, want %s", r.tags.List(), tag)
+		}
+	}
+	if !r.digests.Has(dgst) {
+		t.Errorf("digests = %v, want %s", r.digests.List(), dgst)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	server := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "http://")
+
+	push := func(ref string, img v1.Image) digest.Digest {
+		t.Helper()
+		if err := crane.Push(img, addr+"/"+ref, crane.Insecure); err != nil {
+			t.Fatal(err)
+		}
+		d, err := img.Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return digest.Digest(d.String())
+	}
+	randomImage := func() v1.Image {
+		t.Helper()
+		img, err := random.Image(1024, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return img
+	}
+
+	old := randomImage()
+	oldDigest := push("library/nginx:1.24", old)
+	push("library/nginx:1.25", randomImage())
+	pause := push("library/pause:3.9", randomImage())
+	index, err := random.Index(1024, 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := name.ParseReference(addr+"/library/multi:v1", name.Insecure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = remote.WriteIndex(ref, index); err != nil {
+		t.Fatal(err)
+	}
+	indexManifest, err := index.IndexManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs := newReferences()
+	refs.tags.Insert("library/nginx:1.25")
+	refs.digests.Insert(pause.String())
+	c := newRegistryClient(context.Background(), addr, "", "")
+	p, err := c.plan(refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range p.deletions {
+		got = append(got, m.repo+":"+strings.Join(m.tags, ","))
+	}
+	want := []string{"library/multi:v1", "library/multi:", "library/multi:", "library/nginx:1.24"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("deletions = %v, want %v", got, want)
+	}
+	for i, child := range indexManifest.Manifests {
+		if p.deletions[i+1].digest.String() != child.Digest.String() {
+			t.Errorf("deletion %d = %s, want child %s", i+1, p.deletions[i+1].digest, child.Digest)
+		}
+	}
+	var wantReclaimable int64
+	oldManifest, err := old.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantReclaimable += oldManifest.Config.Size
+	for _, l := range oldManifest.Layers {
+		wantReclaimable += l.Size
+	}
+	if p.reclaimable <= wantReclaimable {
+		t.Errorf("reclaimable = %d, want more than the size of nginx:1.24 %d", p.reclaimable, wantReclaimable)
+	}
+
+	if err = c.delete(p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = crane.Manifest(addr+"/library/nginx@"+oldDigest.String(), crane.Insecure); err == nil {
+		t.Error("library/nginx:1.24 should be deleted")
+	}
+	if _, err = crane.Manifest(addr+"/library/pause@"+pause.String(), crane.Insecure); err != nil {
+		t.Errorf("library/pause:3.9 should be kept: %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/containers/image/v5/manifest"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/crane"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// repoManifest is a manifest in a repository, with the tags referencing it.
+type repoManifest struct {
+	repo   string
+	digest digest.Digest
+	tags   []string
+}
+
+// manifestInfo is the child manifests of an index, or the config and layers of a manifest.
+type manifestInfo struct {
+	size     int64
+	children []digest.Digest
+	blobs    map[digest.Digest]int64
+}
+
+// plan is the manifests to delete from a registry, tagged manifests go before their children.
+type plan struct {
+	deletions   []*repoManifest
+	reclaimable int64
+}
+
+type registryClient struct {
+	addr      string
+	opts      []crane.Option
+	manifests map[digest.Digest]*manifestInfo
+}
+
+func newRegistryClient(ctx context.Context, addr, username, password string) *registryClient {
+	opts := []crane.Option{crane.Insecure, crane.WithContext(ctx)}
+	if username != "" {
+		opts = append(opts, crane.WithAuth(&authn.Basic{Username: username, Password: password}))
+	}
+	return &registryClient{addr: addr, opts: opts, manifests: make(map[digest.Digest]*manifestInfo)}
+}
+
+func hasStatus(err error, code int) bool {
+	var terr *transport.Error
+	return errors.As(err, &terr) && terr.StatusCode == code
+}
+
+// load reads the manifest and its children recursively, raw is fetched if it is nil. Child
+// manifests not in the registry are skipped as only the platforms in use are saved.
+func (c *registryClient) load(repo string, dgst digest.Digest, raw []byte) error {
+	if _, ok := c.manifests[dgst]; ok {
+		return nil
+	}
+	if raw == nil {
+		b, err := crane.Manifest(fmt.Sprintf("%s/%s@%s", c.addr, repo, dgst), c.opts...)
+		if hasStatus(err, http.StatusNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		raw = b
+	}
+	info := &manifestInfo{size: int64(len(raw)), blobs: make(map[digest.Digest]int64)}
+	c.manifests[dgst] = info
+	if manifest.MIMETypeIsMultiImage(manifest.GuessMIMEType(raw)) {
+		var index ocispec.Index
+		if err := json.Unmarshal(raw, &index); err != nil {
+			return fmt.Errorf("invalid manifest list %s@%s: %v", repo, dgst, err)
+		}
+		for _, m := range index.Manifests {
+			info.children = append(info.children, m.Digest)
+			if err := c.load(repo, m.Digest, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var m ocispec.Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return fmt.Errorf("invalid manifest %s@%s: %v", repo, dgst, err)
+	}
+	if m.Config.Digest != "" {
+		info.blobs[m.Config.Digest] = m.Config.Size
+	}
+	for _, l := range m.Layers {
+		info.blobs[l.Digest] = l.Size
+	}
+	return nil
+}
+
+// tagged returns the tagged manifests of all repositories.
+func (c *registryClient) tagged() ([]*repoManifest, error) {
+	repos, err := crane.Catalog(c.addr, c.opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %v", err)
+	}
+	sort.Strings(repos)
+	var ret []*repoManifest
+	for _, repo := range repos {
+		tags, err := crane.ListTags(c.addr+"/"+repo, c.opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tags of %s: %v", repo, err)
+		}
+		sort.Strings(tags)
+		byDigest := make(map[digest.Digest]*repoManifest)
+		for _, tag := range tags {
+			raw, err := crane.Manifest(fmt.Sprintf("%s/%s:%s", c.addr, repo, tag), c.opts...)
+			if hasStatus(err, http.StatusNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			dgst := digest.FromBytes(raw)
+			if err = c.load(repo, dgst, raw); err != nil {
+				return nil, err
+			}
+			if m, ok := byDigest[dgst]; ok {
+				m.tags = append(m.tags, tag)
+				continue
+			}
+			byDigest[dgst] = &repoManifest{repo: repo, digest: dgst, tags: []string{tag}}
+			ret = append(ret, byDigest[dgst])
+		}
+	}
+	return ret, nil
+}
+
+// plan returns the tagged manifests not referenced and their children not referenced by others.
+// The reclaimable size is the size of the manifests and blobs only referenced by them.
+func (c *registryClient) plan(refs *references) (*plan, error) {
+	roots, err := c.tagged()
+	if err != nil {
+		return nil, err
+	}
+	keptManifests, keptBlobs := make(map[digest.Digest]bool), make(map[digest.Digest]bool)
+	var keep func(dgst digest.Digest)
+	keep = func(dgst digest.Digest) {
+		if keptManifests[dgst] {
+			return
+		}
+		keptManifests[dgst] = true
+		info, ok := c.manifests[dgst]
+		if !ok {
+			return
+		}
+		for blob := range info.blobs {
+			keptBlobs[blob] = true
+		}
+		for _, child := range info.children {
+			keep(child)
+		}
+	}
+	for _, m := range roots {
+		if refs.has(m.repo, m.tags, m.digest) {
+			keep(m.digest)
+		}
+	}
+	// a pod may reference the manifest of its platform
+	for dgst := range c.manifests {
+		if refs.digests.Has(dgst.String()) {
+			keep(dgst)
+		}
+	}
+
+	p := &plan{}
+	deleted, reclaimed := make(map[string]bool), make(map[digest.Digest]bool)
+	reclaim := func(dgst digest.Digest, size int64) {
+		if !keptManifests[dgst] && !keptBlobs[dgst] && !reclaimed[dgst] {
+			reclaimed[dgst] = true
+			p.reclaimable += size
+		}
+	}
+	var remove func(m *repoManifest)
+	remove = func(m *repoManifest) {
+		key := m.repo + "@" + m.digest.String()
+		if deleted[key] {
+			return
+		}
+		deleted[key] = true
+		p.deletions = append(p.deletions, m)
+		info, ok := c.manifests[m.digest]
+		if !ok {
+			return
+		}
+		reclaim(m.digest, info.size)
+		for blob, size := range info.blobs {
+			reclaim(blob, size)
+		}
+		for _, child := range info.children {
+			// children of manifests in use are kept, and the missing platforms
+			if _, ok := c.manifests[child]; ok && !keptManifests[child] {
+				remove(&repoManifest{repo: m.repo, digest: child})
+			}
+		}
+	}
+	for _, m := range roots {
+		if !refs.has(m.repo, m.tags, m.digest) {
+			remove(m)
+		}
+	}
+	return p, nil
+}
+
+// delete deletes the manifests of plan, the tags referencing them are deleted as well.
+func (c *registryClient) delete(p *plan) error {
+	for _, m := range p.deletions {
+		err := crane.Delete(fmt.Sprintf("%s/%s@%s", c.addr, m.repo, m.digest), c.opts...)
+		switch {
+		case err == nil, hasStatus(err, http.StatusNotFound):
+		case hasStatus(err, http.StatusMethodNotAllowed):
+			return fmt.Errorf("deleting is disabled, set storage.delete.enabled to true in registry config: %v", err)
+		default:
+			return fmt.Errorf("failed to delete %s@%s: %v", m.repo, m.digest, err)
+		}
+	}
+	return nil
+}
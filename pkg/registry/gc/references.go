@@ -0,0 +1,141 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/containers/image/v5/docker/reference"
+	"github.com/opencontainers/go-digest"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/filesystem/registry"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/file"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// references is the images in use by the cluster. Images are matched by repository path and
+// tag or by digest, the registry domain is ignored as image-cri-shim pulls any domain from
+// the cluster registry.
+type references struct {
+	tags    sets.String
+	digests sets.String
+}
+
+func newReferences() *references {
+	return &references{tags: sets.NewString(), digests: sets.NewString()}
+}
+
+func (r *references) len() int {
+	return r.tags.Len() + r.digests.Len()
+}
+
+// has returns true if any tag of the manifest or its digest is referenced.
+func (r *references) has(repo string, tags []string, dgst digest.Digest) bool {
+	if r.digests.Has(dgst.String()) {
+		return true
+	}
+	for _, tag := range tags {
+		if r.tags.Has(repo + ":" + tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *references) addImage(image string) error {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return err
+	}
+	digested, isDigested := named.(reference.Digested)
+	if isDigested {
+		r.digests.Insert(digested.Digest().String())
+	}
+	if tagged, ok := named.(reference.Tagged); ok {
+		r.tags.Insert(reference.Path(named) + ":" + tagged.Tag())
+	} else if !isDigested {
+		r.tags.Insert(reference.Path(named) + ":latest")
+	}
+	return nil
+}
+
+// addMounts adds the images saved in the registry dirs of mounted images, which are the
+// images synced to the registry by the current cluster images.
+func (r *references) addMounts(mounts []v2.MountImage) error {
+	for _, mount := range mounts {
+		if !file.IsDir(mount.MountPoint) {
+			return fmt.Errorf("mount point %s of image %s is not found, images of it cannot be determined", mount.MountPoint, mount.ImageName)
+		}
+		registryDir := filepath.Join(mount.MountPoint, constants.RegistryDirName)
+		if !file.IsDir(registryDir) {
+			continue
+		}
+		images, err := registry.ListImages(registryDir)
+		if err != nil {
+			return fmt.Errorf("failed to list images of %s: %v", mount.ImageName, err)
+		}
+		r.tags.Insert(images...)
+	}
+	return nil
+}
+
+// addPods adds the images of containers of all pods, and the digests of images they are running.
+func (r *references) addPods(ctx context.Context, client kubernetes.Interface) error {
+	pods, err := client.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %v", err)
+	}
+	for _, pod := range pods.Items {
+		var images []string
+		for _, c := range pod.Spec.InitContainers {
+			images = append(images, c.Image)
+		}
+		for _, c := range pod.Spec.Containers {
+			images = append(images, c.Image)
+		}
+		for _, c := range pod.Spec.EphemeralContainers {
+			images = append(images, c.Image)
+		}
+		var statuses []corev1.ContainerStatus
+		statuses = append(statuses, pod.Status.InitContainerStatuses...)
+		statuses = append(statuses, pod.Status.ContainerStatuses...)
+		statuses = append(statuses, pod.Status.EphemeralContainerStatuses...)
+		for _, s := range statuses {
+			images = append(images, s.Image)
+			// imageID is the repo digest such as docker.io/library/nginx@sha256:...
+			if idx := strings.LastIndex(s.ImageID, "@"); idx >= 0 {
+				r.digests.Insert(s.ImageID[idx+1:])
+			}
+		}
+		for _, image := range images {
+			if image == "" {
+				continue
+			}
+			if err := r.addImage(image); err != nil {
+				logger.Debug("skip invalid image %s of pod %s/%s: %v", image, pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -16,11 +16,13 @@ package helpers
 
 import (
 	"fmt"
+	"os"
 	"path"
 
 	"github.com/labring/image-cri-shim/pkg/types"
-
+	"golang.org/x/exp/slices"
 	"k8s.io/apimachinery/pkg/util/yaml"
+	sigsyaml "sigs.k8s.io/yaml"
 
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/exec"
@@ -31,7 +33,10 @@ import (
 
 const RegistryCustomConfig = "registry.yml"
 
-func GetRegistryInfo(execer exec.Interface, rootfs, defaultRegistry string) *v1beta1.RegistryConfig {
+// GetRegistryInfo loads the registry config from the first available one of registries, the replicas
+// after the first one are only tried if it is down.
+func GetRegistryInfo(execer exec.Interface, rootfs string, registries ...string) *v1beta1.RegistryConfig {
+	defaultRegistry := registries[0]
 	var DefaultConfig = &v1beta1.RegistryConfig{
 		IP:       iputils.GetHostIP(defaultRegistry),
 		Domain:   constants.DefaultRegistryDomain,
@@ -41,7 +46,17 @@ func GetRegistryInfo(execer exec.Interface, rootfs, defaultRegistry string) *v1b
 		Data:     constants.DefaultRegistryData,
 	}
 	etcPath := path.Join(rootfs, constants.EtcDirName, RegistryCustomConfig)
-	out, err := execer.Cmd(defaultRegistry, fmt.Sprintf("cat %s", etcPath))
+	var (
+		out []byte
+		err error
+	)
+	for _, registry := range registries {
+		if out, err = execer.Cmd(registry, fmt.Sprintf("cat %s", etcPath)); err == nil {
+			defaultRegistry = registry
+			break
+		}
+		logger.Debug("failed to load registry config from %s: %v", registry, err)
+	}
 	if err != nil {
 		logger.Warn("load registry config error: %+v, using default registry config", err)
 		return DefaultConfig
@@ -78,3 +93,42 @@ func GetImageCRIShimInfo(execer exec.Interface, config, defaultIP string) *types
 	logger.Debug("show registry info, addr: %s,  auth: %s", readConfig.Address, readConfig.Auth)
 	return readConfig
 }
+
+// SetImageCRIShimReplicas sets the registry replicas of image-cri-shim on host to the IPs of registries
+// and restarts it, failover is disabled if there is only one registry. Nothing is changed if the replicas
+// are up to date.
+func SetImageCRIShimReplicas(execer exec.Interface, host string, registries []string) error {
+	shimConfig := GetImageCRIShimInfo(execer, types.DefaultImageCRIShimConfig, host)
+	if shimConfig == nil || shimConfig.Address == "" {
+		logger.Warn("image-cri-shim is not configured on %s, skip setting registry replicas", host)
+		return nil
+	}
+	var replicas []string
+	if len(registries) > 1 {
+		replicas = iputils.GetHostIPs(registries)
+	}
+	if slices.Equal(shimConfig.RegistryReplicas, replicas) {
+		return nil
+	}
+	shimConfig.RegistryReplicas = replicas
+	data, err := sigsyaml.Marshal(shimConfig)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp("", "image-cri-shim-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	if err = execer.Copy(host, f.Name(), types.DefaultImageCRIShimConfig); err != nil {
+		return fmt.Errorf("failed to copy image-cri-shim config to %s: %v", host, err)
+	}
+	return execer.CmdAsync(host, "systemctl restart image-cri-shim")
+}
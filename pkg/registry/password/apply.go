@@ -144,7 +144,7 @@ func (r *RegistryPasswdResults) Apply(cluster *v1beta1.Cluster) error {
 		r.upgrade = NewUpgrade(cluster.Name, r.execer)
 	}
 	root := constants.NewPathResolver(cluster.Name).RootFSPath()
-	registry := helpers.GetRegistryInfo(r.execer, root, cluster.GetRegistryIPAndPort())
+	registry := helpers.GetRegistryInfo(r.execer, root, cluster.GetRegistryIPAndPortList()...)
 	shim := helpers.GetImageCRIShimInfo(r.execer, r.ImageCRIShimFilePath, cluster.GetMaster0IPAndPort())
 	if registry == nil || shim == nil {
 		return errors.New("get registry or shim info error")
@@ -14,8 +14,14 @@
 
 package runtime
 
+import (
+	"github.com/labring/sealos/pkg/cert"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
 type Interface interface {
 	Ruler
+	Labeler
 	Init() error
 	Reset() error
 	ScaleUp(newMasterIPList []string, newNodeIPList []string) error
@@ -28,9 +34,16 @@ type Ruler interface {
 	SyncNodeIPVS(masters, nodes []string) error
 }
 
+type Labeler interface {
+	// SyncNodeLabels applies the labels and taints of hosts to their nodes,
+	// the ones declared in previous hosts but not any more are removed.
+	SyncNodeLabels(previous []v2.Host, hosts ...string) error
+}
+
 type CertManager interface {
 	Renew() error
 	UpdateCertSANs(certSANs []string) error
+	CheckExpiration() ([]cert.Expiration, error)
 }
 
 type Config interface {
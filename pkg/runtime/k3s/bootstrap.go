@@ -22,13 +22,12 @@ import (
 
 	"github.com/pkg/errors"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/labring/sealos/pkg/utils/iputils"
 
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/rand"
 	"github.com/labring/sealos/pkg/utils/yaml"
 )
@@ -45,6 +44,7 @@ func (k *K3s) initMaster0() error {
 			return k.remoteUtil.HostsAdd(master0, iputils.GetHostIP(master0), constants.DefaultAPIServerDomain)
 		},
 		func() error { return k.copyKubeConfigFileToNodes(k.cluster.GetMaster0IPAndPort()) },
+		func() error { return k.SyncNodeLabels(nil, master0) },
 	)
 }
 
@@ -239,7 +239,7 @@ func (k *K3s) copyKubeConfigFileToNodes(hosts ...string) error {
 	if err = file.WriteFile(src, []byte(newData)); err != nil {
 		return errors.WithMessage(err, "write admin.config file failed")
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range hosts {
 		node := node
 		eg.Go(func() error {
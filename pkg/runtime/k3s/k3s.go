@@ -18,14 +18,14 @@ import (
 	"context"
 	"fmt"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/strings"
 
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/env"
 	"github.com/labring/sealos/pkg/exec"
+	"github.com/labring/sealos/pkg/runtime/utils"
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/logger"
@@ -88,7 +88,11 @@ func (k *K3s) ScaleUp(masters []string, nodes []string) error {
 			return err
 		}
 	}
-	return nil
+	return k.SyncNodeLabels(nil, append(masters, nodes...)...)
+}
+
+func (k *K3s) SyncNodeLabels(previous []v2.Host, hosts ...string) error {
+	return utils.SyncNodeLabels(k.execer, k.cluster.GetMaster0IPAndPort(), k.cluster.Spec.Hosts, previous, hosts...)
 }
 
 func (k *K3s) ScaleDown(masters []string, nodes []string) error {
@@ -106,8 +110,20 @@ func (k *K3s) ScaleDown(masters []string, nodes []string) error {
 }
 
 func (k *K3s) Upgrade(version string) error {
-	logger.Info("trying to upgrade to version %s", version)
-	return nil
+	currVersion := k.getVersionFromImage()
+	if currVersion == "" {
+		return fmt.Errorf("cannot get current k3s version from cluster image")
+	}
+	need, err := validateUpgradeVersion(currVersion, version)
+	if err != nil {
+		return err
+	}
+	if !need {
+		logger.Info("skip upgrade because of same version")
+		return nil
+	}
+	logger.Info("trying to upgrade k3s from %s to %s", currVersion, version)
+	return k.upgradeCluster(version)
 }
 
 func (k *K3s) GetRawConfig() ([]byte, error) {
@@ -129,7 +145,7 @@ func (k *K3s) SyncNodeIPVS(mastersIPList, nodeIPList []string) error {
 		masters = append(masters, fmt.Sprintf("%s:%d", iputils.GetHostIP(master), apiPort))
 	}
 	image := k.cluster.GetLvscareImage()
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range nodeIPList {
 		node := node
 		eg.Go(func() error {
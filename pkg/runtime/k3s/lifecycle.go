@@ -18,19 +18,16 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/labring/sealos/pkg/utils/iputils"
-
-	"github.com/labring/sealos/pkg/utils/strings"
-
 	"golang.org/x/exp/slices"
 
+	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
-
-	"golang.org/x/sync/errgroup"
+	"github.com/labring/sealos/pkg/utils/parallel"
+	"github.com/labring/sealos/pkg/utils/strings"
 )
 
 func (k *K3s) resetNodes(nodes []string) error {
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for i := range nodes {
 		node := nodes[i]
 		eg.Go(func() error {
@@ -41,7 +38,7 @@ func (k *K3s) resetNodes(nodes []string) error {
 }
 
 func (k *K3s) removeNodes(nodes []string) error {
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for i := range nodes {
 		node := nodes[i]
 		eg.Go(func() error {
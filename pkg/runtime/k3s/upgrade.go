@@ -0,0 +1,196 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k3s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	// replace the binary wherever it was installed, `cp -f` unlinks a busy target before writing
+	installK3sCmd   = "cp -rf %s/k3s \"$(command -v k3s || echo /usr/bin/k3s)\""
+	cordonNodeCmd   = "kubectl cordon %s"
+	uncordonNodeCmd = "kubectl uncordon %s"
+	drainNodeCmd    = "kubectl drain %s --ignore-daemonsets --delete-emptydir-data --force --timeout=%s"
+	readyzCmd       = "kubectl get --raw=/readyz"
+	nodeReadyCmd    = "kubectl wait --for=condition=Ready node/%s --timeout=%s"
+)
+
+const (
+	upgradeHealthyTimeout  = 3 * time.Minute
+	upgradeHealthyInterval = 5 * time.Second
+	drainTimeout           = 5 * time.Minute
+)
+
+func (k *K3s) getVersionFromImage() string {
+	img := k.cluster.GetRootfsImage()
+	if img == nil {
+		return ""
+	}
+	return img.KubeVersion()
+}
+
+func (k *K3s) upgradeCluster(version string) error {
+	master0 := k.cluster.GetMaster0IPAndPort()
+	// assure the connection to api-server succeed before replacing any binary
+	if err := k.waitForAPIServer(master0); err != nil {
+		return err
+	}
+	logger.Info("start to upgrade k3s servers to %s", version)
+	for _, master := range k.cluster.GetMasterIPAndPortList() {
+		if err := k.upgradeServer(master); err != nil {
+			return err
+		}
+	}
+	logger.Info("start to upgrade k3s agents to %s", version)
+	for _, node := range k.cluster.GetNodeIPAndPortList() {
+		if err := k.upgradeAgent(master0, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upgradeServer restarts a single server with the new binary, the next server
+// will not be touched until the api-server of this one is healthy again.
+func (k *K3s) upgradeServer(host string) error {
+	nodeName, err := k.getNodeName(host)
+	if err != nil {
+		return err
+	}
+	return k.runPipelines(fmt.Sprintf("upgrade server %s", host),
+		func() error { return k.execer.CmdAsync(host, fmt.Sprintf(cordonNodeCmd, nodeName)) },
+		func() error { return k.replaceBinaryAndRestart(host) },
+		func() error { return k.waitForAPIServer(host) },
+		func() error { return k.tryUncordonNode(host, nodeName) },
+	)
+}
+
+func (k *K3s) upgradeAgent(master0, host string) error {
+	nodeName, err := k.getNodeName(host)
+	if err != nil {
+		return err
+	}
+	return k.runPipelines(fmt.Sprintf("upgrade agent %s", host),
+		func() error {
+			return k.execer.CmdAsync(master0, fmt.Sprintf(drainNodeCmd, nodeName, drainTimeout))
+		},
+		func() error { return k.replaceBinaryAndRestart(host) },
+		func() error {
+			return k.execer.CmdAsync(master0, fmt.Sprintf(nodeReadyCmd, nodeName, upgradeHealthyTimeout))
+		},
+		func() error { return k.tryUncordonNode(master0, nodeName) },
+	)
+}
+
+func (k *K3s) replaceBinaryAndRestart(host string) error {
+	logger.Info("replace k3s binary and restart k3s service on %s", host)
+	if err := k.execer.CmdAsync(host, fmt.Sprintf(installK3sCmd, k.pathResolver.RootFSBinPath())); err != nil {
+		return err
+	}
+	return k.remoteUtil.InitSystem(host).ServiceRestart("k3s")
+}
+
+func (k *K3s) getNodeName(host string) (string, error) {
+	nodeName, err := k.remoteUtil.Hostname(host)
+	if err != nil {
+		return "", err
+	}
+	//default nodeName in k8s is the lower case of their hostname because of DNS protocol.
+	return strings.ToLower(strings.TrimSpace(nodeName)), nil
+}
+
+func (k *K3s) waitForAPIServer(host string) error {
+	timeout := time.Now().Add(upgradeHealthyTimeout)
+	for {
+		_, err := k.execer.CmdToString(host, readyzCmd, "")
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("api-server on %s is not ready within %s: %v", host, upgradeHealthyTimeout, err)
+		}
+		time.Sleep(upgradeHealthyInterval)
+	}
+}
+
+func (k *K3s) tryUncordonNode(host, nodeName string) error {
+	timeout := time.Now().Add(upgradeHealthyTimeout)
+	for {
+		err := k.execer.CmdAsync(host, fmt.Sprintf(uncordonNodeCmd, nodeName))
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("try uncordon node %s timeout %s", nodeName, upgradeHealthyTimeout)
+		}
+		time.Sleep(upgradeHealthyInterval)
+	}
+}
+
+// k3sRevision returns the k3s patch revision of v, k3s releases are tagged like v1.25.6+k3s1
+// and semver ignores the build metadata when comparing versions.
+func k3sRevision(v *semver.Version) (int, error) {
+	if v.Metadata() == "" {
+		return 0, nil
+	}
+	rev, err := strconv.Atoi(strings.TrimPrefix(v.Metadata(), "k3s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid k3s revision %s of version %s", v.Metadata(), v.Original())
+	}
+	return rev, nil
+}
+
+func validateUpgradeVersion(currVersion, version string) (bool, error) {
+	v0, err := semver.NewVersion(currVersion)
+	if err != nil {
+		return false, err
+	}
+	v1, err := semver.NewVersion(version)
+	if err != nil {
+		return false, err
+	}
+	if v0.Equal(v1) {
+		r0, err := k3sRevision(v0)
+		if err != nil {
+			return false, err
+		}
+		r1, err := k3sRevision(v1)
+		if err != nil {
+			return false, err
+		}
+		if r0 > r1 {
+			return false, fmt.Errorf("cannot apply an older version %s than %s", version, currVersion)
+		}
+		return r0 < r1, nil
+	}
+	if v0.GreaterThan(v1) {
+		return false, fmt.Errorf("cannot apply an older version %s than %s", version, currVersion)
+	}
+	if v0.Major() != v1.Major() {
+		return false, fmt.Errorf("cannot be upgraded across major releases, %s -> %s", currVersion, version)
+	}
+	if v0.Minor()+1 < v1.Minor() {
+		return false, fmt.Errorf("cannot be upgraded across more than one minor release, %s -> %s", currVersion, version)
+	}
+	return true, nil
+}
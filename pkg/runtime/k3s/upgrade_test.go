@@ -0,0 +1,50 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k3s
+
+import "testing"
+
+func Test_validateUpgradeVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		version  string
+		wantNeed bool
+		wantErr  bool
+	}{
+		{name: "same version", current: "v1.25.6+k3s1", version: "v1.25.6+k3s1"},
+		{name: "k3s revision bump", current: "v1.25.6+k3s1", version: "v1.25.6+k3s2", wantNeed: true},
+		{name: "patch release", current: "v1.25.6+k3s1", version: "v1.25.9+k3s1", wantNeed: true},
+		{name: "minor release", current: "v1.25.6+k3s1", version: "v1.26.1+k3s1", wantNeed: true},
+		{name: "skip minor release", current: "v1.25.6+k3s1", version: "v1.27.1+k3s1", wantErr: true},
+		{name: "downgrade", current: "v1.26.1+k3s1", version: "v1.25.6+k3s1", wantErr: true},
+		{name: "k3s revision downgrade", current: "v1.25.6+k3s2", version: "v1.25.6+k3s1", wantErr: true},
+		{name: "invalid k3s revision", current: "v1.25.6+k3s1", version: "v1.25.6+rc1", wantErr: true},
+		{name: "major release", current: "v1.27.1+k3s1", version: "v2.0.0+k3s1", wantErr: true},
+		{name: "invalid version", current: "v1.25.6+k3s1", version: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			need, err := validateUpgradeVersion(tt.current, tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateUpgradeVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if need != tt.wantNeed {
+				t.Errorf("validateUpgradeVersion() = %v, want %v", need, tt.wantNeed)
+			}
+		})
+	}
+}
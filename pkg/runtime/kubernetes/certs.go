@@ -18,15 +18,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path"
+	"path/filepath"
+	"strings"
+	"time"
 
-	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/json"
 
+	"github.com/labring/sealos/pkg/cert"
 	"github.com/labring/sealos/pkg/client-go/kubernetes"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/yaml"
 )
 
@@ -37,8 +42,76 @@ const (
 	KubeletConf    = "kubelet.conf"
 )
 
+// Renew regenerates all the leaf certificates and kubeconfig files of control-plane
+// signed by the existing CAs, then restarts the static pods master by master.
+// The api-server is not called until the certs are renewed, since they may have expired.
 func (k *KubeadmRuntime) Renew() error {
-	return errors.New("not implement")
+	if err := k.CompleteKubeadmConfig(setCGroupDriverAndSocket, setCertificateKey); err != nil {
+		return err
+	}
+	pipeline := []func() error{
+		k.mergeWithLocalKubeadmConfig,
+		k.initCert,
+		k.renewKubeConfigs,
+		k.rollingRestartControlPlane,
+		k.showAllKubeadmCerts,
+	}
+	for _, f := range pipeline {
+		if err := f(); err != nil {
+			return fmt.Errorf("failed to renew cert %v", err)
+		}
+	}
+	return nil
+}
+
+// CheckExpiration fetches all the certificates and kubeconfig files of control-plane from every master.
+func (k *KubeadmRuntime) CheckExpiration() ([]cert.Expiration, error) {
+	var expirations []cert.Expiration
+	for _, master := range k.getMasterIPAndPortList() {
+		list, err := k.fetchCertExpirations(master)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check certs of master %s: %v", master, err)
+		}
+		expirations = append(expirations, list...)
+	}
+	return expirations, nil
+}
+
+func (k *KubeadmRuntime) fetchCertExpirations(host string) ([]cert.Expiration, error) {
+	out, err := k.execer.CmdToString(host, fmt.Sprintf("find %s -name '*.crt' | sort", kubernetesEtcPKI), ",")
+	if err != nil {
+		return nil, err
+	}
+	var expirations []cert.Expiration
+	for _, f := range strings.Split(out, ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		data, err := k.execer.Cmd(host, fmt.Sprintf("cat %s", f))
+		if err != nil {
+			return nil, err
+		}
+		name, _ := filepath.Rel(kubernetesEtcPKI, f)
+		exp, err := cert.ParseCertExpiration(host, name, data)
+		if err != nil {
+			return nil, err
+		}
+		expirations = append(expirations, *exp)
+	}
+	// kubelet.conf is skipped because it points to the rotated client certificate of kubelet
+	for _, f := range []string{AdminConf, ControllerConf, SchedulerConf} {
+		data, err := k.execer.Cmd(host, fmt.Sprintf("cat %s", path.Join(kubernetesEtc, f)))
+		if err != nil {
+			logger.Warn("failed to read %s in %s: %v", f, host, err)
+			continue
+		}
+		exp, err := cert.ParseKubeConfigExpiration(host, f, data)
+		if err != nil {
+			return nil, err
+		}
+		expirations = append(expirations, *exp)
+	}
+	return expirations, nil
 }
 
 func (k *KubeadmRuntime) UpdateCertSANs(certSans []string) error {
@@ -162,43 +235,132 @@ func (k *KubeadmRuntime) syncCert() error {
 }
 
 func (k *KubeadmRuntime) showKubeadmCert() error {
+	return k.showKubeadmCertOn(k.getMaster0IPAndPort())
+}
+
+func (k *KubeadmRuntime) showAllKubeadmCerts() error {
+	for _, master := range k.getMasterIPAndPortList() {
+		logger.Info("certificates expiration of master %s", master)
+		if err := k.showKubeadmCertOn(master); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (k *KubeadmRuntime) showKubeadmCertOn(host string) error {
 	certCheck := "kubeadm certs check-expiration"
-	return k.sshCmdAsync(k.getMaster0IPAndPort(), fmt.Sprintf("%s%s", certCheck, vlogToStr(k.klogLevel)))
+	return k.sshCmdAsync(host, fmt.Sprintf("%s%s", certCheck, vlogToStr(k.klogLevel)))
 }
 
-func (k *KubeadmRuntime) deleteAPIServer() error {
-	podIDSh := fmt.Sprintf("crictl ps -a --name %s -o json", kubernetes.KubeAPIServer)
-	type crictlPS struct {
-		Containers []struct {
-			ID           string `json:"id"`
-			PodSandboxID string `json:"podSandboxId"`
-		} `json:"containers"`
+// renewKubeConfigs removes the kubeconfig files which are shared by all masters and generates
+// new ones, kubelet.conf is skipped because kubelet rotates its client certificate by itself.
+func (k *KubeadmRuntime) renewKubeConfigs() error {
+	logger.Info("start to renew kubeconfig...")
+	for _, f := range []string{AdminConf, ControllerConf, SchedulerConf} {
+		if err := os.Remove(path.Join(k.pathResolver.EtcPath(), f)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return k.CreateKubeConfigFiles()
+}
+
+func (k *KubeadmRuntime) rollingRestartControlPlane() error {
+	for i, master := range k.getMasterIPAndPortList() {
+		pipelines := make([]func() error, 0)
+		// certs of master0 are generated locally and sent by initCert
+		if i > 0 {
+			pipelines = append(pipelines, func() error { return k.execCert(master) })
+		}
+		pipelines = append(pipelines,
+			func() error {
+				return k.SendJoinMasterKubeConfigs([]string{master}, AdminConf, ControllerConf, SchedulerConf)
+			},
+			func() error { return k.copyMasterKubeConfig(master) },
+			func() error { return k.restartStaticPods(master) },
+			func() error { return k.waitForAPIServerReady(master) },
+		)
+		if err := k.runPipelines(fmt.Sprintf("renew cert of master %s", master), pipelines...); err != nil {
+			return err
+		}
+		logger.Info("succeeded in renewing cert of master %s", master)
+	}
+	return nil
+}
+
+func (k *KubeadmRuntime) restartStaticPods(host string) error {
+	for _, name := range []string{kubernetes.Etcd, kubernetes.KubeAPIServer, kubernetes.KubeControllerManager, kubernetes.KubeScheduler} {
+		found, err := k.deleteStaticPod(host, name)
+		if err != nil {
+			return err
+		}
+		// external etcd is not managed by kubelet
+		if !found {
+			logger.Warn("not found %s pod running in %s, skip restarting it", name, host)
+		}
+	}
+	return nil
+}
+
+func (k *KubeadmRuntime) waitForAPIServerReady(host string) error {
+	timeout := time.Now().Add(3 * time.Minute)
+	for {
+		_, err := k.sshCmdToString(host, "kubectl get --raw=/readyz")
+		if err == nil {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return fmt.Errorf("api-server in %s is not ready within three minutes: %v", host, err)
+		}
+		time.Sleep(5 * time.Second)
 	}
+}
+
+func (k *KubeadmRuntime) deleteAPIServer() error {
 	logger.Info("delete pod apiserver from crictl")
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, master := range k.getMasterIPAndPortList() {
 		m := master
 		eg.Go(func() error {
-			podIDJson, err := k.sshCmdToString(m, podIDSh)
+			found, err := k.deleteStaticPod(m, kubernetes.KubeAPIServer)
 			if err != nil {
 				return err
 			}
-			ps := &crictlPS{}
-			if err = json.Unmarshal([]byte(podIDJson), ps); err != nil {
-				return err
-			}
-			if len(ps.Containers) > 0 {
-				podID := ps.Containers[0].PodSandboxID[:13]
-				logger.Debug("found podID %s in %s", podID, m)
-				//crictl stopp
-				if err = k.sshCmdAsync(m, fmt.Sprintf("crictl --timeout=10s stopp %s", podID)); err != nil {
-					return err
-				}
-				//crictl rmp
-				return k.sshCmdAsync(m, fmt.Sprintf("crictl rmp %s", podID))
+			if !found {
+				return errors.New("not found apiServer pod running")
 			}
-			return errors.New("not found apiServer pod running")
+			return nil
 		})
 	}
 	return eg.Wait()
 }
+
+// deleteStaticPod removes the pod sandbox of the static pod, kubelet will recreate it from the manifest.
+func (k *KubeadmRuntime) deleteStaticPod(host, name string) (bool, error) {
+	podIDSh := fmt.Sprintf("crictl ps -a --name %s -o json", name)
+	type crictlPS struct {
+		Containers []struct {
+			ID           string `json:"id"`
+			PodSandboxID string `json:"podSandboxId"`
+		} `json:"containers"`
+	}
+	podIDJson, err := k.sshCmdToString(host, podIDSh)
+	if err != nil {
+		return false, err
+	}
+	ps := &crictlPS{}
+	if err = json.Unmarshal([]byte(podIDJson), ps); err != nil {
+		return false, err
+	}
+	if len(ps.Containers) == 0 {
+		return false, nil
+	}
+	podID := ps.Containers[0].PodSandboxID[:13]
+	logger.Debug("found %s podID %s in %s", name, podID, host)
+	//crictl stopp
+	if err = k.sshCmdAsync(host, fmt.Sprintf("crictl --timeout=10s stopp %s", podID)); err != nil {
+		return false, err
+	}
+	//crictl rmp
+	return true, k.sshCmdAsync(host, fmt.Sprintf("crictl rmp %s", podID))
+}
@@ -215,20 +215,76 @@ func (k *KubeadmRuntime) mergeWithBuiltinKubeadmConfig() error {
 	}
 	logger.Debug("current cluster config data: %+v", obj)
 
-	var certs []string
+	certs, err := getCertSANsFromConfig(obj)
+	if err != nil {
+		return err
+	}
+	logger.Debug("current cluster certSANs: %+v", certs)
+	k.setCertSANs(certs)
+	return k.setNetWorking(obj)
+}
+
+// mergeWithLocalKubeadmConfig is like mergeWithBuiltinKubeadmConfig, but it reads the kubeadm config
+// saved in master0 instead of the configmap, so it works even if the certs have already expired.
+func (k *KubeadmRuntime) mergeWithLocalKubeadmConfig() error {
+	var obj map[string]interface{}
+	// kubeadm-update.yaml is saved by `sealos cert --alt-names`, its certSANs are newer than the init config
+	for _, name := range []string{defaultUpdateKubeadmFileName, defaultInitKubeadmFileName} {
+		configPath := path.Join(k.pathResolver.ConfigsPath(), name)
+		data, err := k.execer.Cmd(k.getMaster0IPAndPort(), fmt.Sprintf("cat %s", configPath))
+		if err != nil {
+			logger.Debug("failed to read %s in master0: %v", configPath, err)
+			continue
+		}
+		if obj, err = getClusterConfiguration(data); err != nil {
+			return fmt.Errorf("failed to parse %s: %v", configPath, err)
+		}
+		if obj != nil {
+			logger.Info("fetch certSANs from %s", configPath)
+			break
+		}
+	}
+	if obj == nil {
+		return fmt.Errorf("kubeadm ClusterConfiguration not found in %s of master0", k.pathResolver.ConfigsPath())
+	}
+	certs, err := getCertSANsFromConfig(obj)
+	if err != nil {
+		return err
+	}
+	logger.Debug("current cluster certSANs: %+v", certs)
+	// the saved config does not contain the masters joined later
+	k.setCertSANs(append(k.getCertSANs(), certs...))
+	return k.setNetWorking(obj)
+}
+
+// getClusterConfiguration returns the ClusterConfiguration document of kubeadm config,
+// or nil if not found.
+func getClusterConfiguration(data []byte) (map[string]interface{}, error) {
+	for _, doc := range yaml.ToJSON(data) {
+		obj, err := yaml.UnmarshalToMap([]byte(doc))
+		if err != nil {
+			return nil, err
+		}
+		if obj["kind"] == "ClusterConfiguration" {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+func getCertSANsFromConfig(obj map[string]interface{}) ([]string, error) {
 	certsStruct, exist, err := unstructured.NestedSlice(obj, "apiServer", "certSANs")
 	if !exist {
 		if err != nil {
-			return err
+			return nil, err
 		}
-		return fmt.Errorf("apiServer certSANs not exist")
+		return nil, fmt.Errorf("apiServer certSANs not exist")
 	}
+	var certs []string
 	for i := range certsStruct {
 		certs = append(certs, certsStruct[i].(string))
 	}
-	logger.Debug("current cluster certSANs: %+v", certs)
-	k.setCertSANs(certs)
-	return k.setNetWorking(obj)
+	return certs, nil
 }
 
 func (k *KubeadmRuntime) setNetWorking(obj map[string]interface{}) error {
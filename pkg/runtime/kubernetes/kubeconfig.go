@@ -20,14 +20,14 @@ import (
 	"context"
 	"path/filepath"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 const copyKubeAdminConfigCommand = `rm -rf $HOME/.kube/config && mkdir -p $HOME/.kube && cp /etc/kubernetes/admin.conf $HOME/.kube/config`
 
 func (k *KubeadmRuntime) copyKubeConfigFileToNodes(hosts ...string) error {
 	src := k.pathResolver.AdminFile()
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range hosts {
 		node := node
 		eg.Go(func() error {
@@ -22,9 +22,8 @@ import (
 	"github.com/labring/sealos/pkg/ssh"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 	"github.com/labring/sealos/pkg/utils/strings"
-
-	"golang.org/x/sync/errgroup"
 )
 
 func (k *KubeadmRuntime) InitMaster0() error {
@@ -43,7 +42,7 @@ func (k *KubeadmRuntime) InitMaster0() error {
 
 // sendJoinCPConfig send join CP masters configuration
 func (k *KubeadmRuntime) sendJoinCPConfig(joinMaster []string) error {
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, master := range joinMaster {
 		master := master
 		eg.Go(func() error {
@@ -144,7 +143,7 @@ func (k *KubeadmRuntime) deleteMasters(masters []string) error {
 	if len(masters) == 0 {
 		return nil
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, master := range masters {
 		master := master
 		eg.Go(func() error {
@@ -22,8 +22,7 @@ import (
 	"github.com/labring/sealos/pkg/ssh"
 	"github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
-
-	"golang.org/x/sync/errgroup"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 func (k *KubeadmRuntime) joinNodes(newNodesIPList []string) error {
@@ -39,7 +38,7 @@ func (k *KubeadmRuntime) joinNodes(newNodesIPList []string) error {
 	if err = k.mergeWithBuiltinKubeadmConfig(); err != nil {
 		return err
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range newNodesIPList {
 		node := node
 		eg.Go(func() error {
@@ -93,7 +92,7 @@ func (k *KubeadmRuntime) deleteNodes(nodes []string) error {
 	if len(nodes) == 0 {
 		return nil
 	}
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range nodes {
 		node := node
 		eg.Go(func() error {
@@ -19,9 +19,9 @@ import (
 	"fmt"
 
 	"golang.org/x/exp/slices"
-	"golang.org/x/sync/errgroup"
 
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 const (
@@ -41,7 +41,7 @@ func (k *KubeadmRuntime) reset() error {
 
 func (k *KubeadmRuntime) resetNodes(nodes []string) {
 	logger.Info("start to reset nodes: %v", nodes)
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for _, node := range nodes {
 		node := node
 		eg.Go(func() error {
@@ -25,6 +25,7 @@ import (
 	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/exec"
 	"github.com/labring/sealos/pkg/runtime/kubernetes/types"
+	"github.com/labring/sealos/pkg/runtime/utils"
 	"github.com/labring/sealos/pkg/ssh"
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	"github.com/labring/sealos/pkg/utils/logger"
@@ -52,9 +53,14 @@ func (k *KubeadmRuntime) Init() error {
 		k.InitCertsAndKubeConfigs,
 		k.CopyStaticFilesToMasters,
 		k.InitMaster0,
+		func() error { return k.SyncNodeLabels(nil, k.getMaster0IPAndPort()) },
 	)
 }
 
+func (k *KubeadmRuntime) SyncNodeLabels(previous []v2.Host, hosts ...string) error {
+	return utils.SyncNodeLabels(k.execer, k.getMaster0IPAndPort(), k.cluster.Spec.Hosts, previous, hosts...)
+}
+
 func (k *KubeadmRuntime) GetRawConfig() ([]byte, error) {
 	if k.config.KubeadmConfig == nil {
 		return nil, errors.New("please provide a nonnull config")
@@ -103,9 +109,11 @@ func (k *KubeadmRuntime) ScaleUp(newMasterIPList []string, newNodeIPList []strin
 		if err := k.joinNodes(newNodeIPList); err != nil {
 			return err
 		}
-		return k.copyKubeConfigFileToNodes(newNodeIPList...)
+		if err := k.copyKubeConfigFileToNodes(newNodeIPList...); err != nil {
+			return err
+		}
 	}
-	return nil
+	return k.SyncNodeLabels(nil, append(newMasterIPList, newNodeIPList...)...)
 }
 
 func (k *KubeadmRuntime) ScaleDown(deleteMastersIPList []string, deleteNodesIPList []string) error {
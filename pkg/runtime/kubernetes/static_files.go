@@ -19,9 +19,8 @@ import (
 	"fmt"
 	"path/filepath"
 
-	"golang.org/x/sync/errgroup"
-
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 const (
@@ -52,7 +51,7 @@ func (k *KubeadmRuntime) copyStaticFiles(nodes []string) error {
 	for _, file := range MasterStaticFiles {
 		staticFilePath := filepath.Join(k.pathResolver.RootFSStaticsPath(), file.Name)
 		cmdLinkStatic := fmt.Sprintf(copyFileToDirCommand, file.DestinationDir, staticFilePath, filepath.Join(file.DestinationDir, file.Name))
-		eg, _ := errgroup.WithContext(context.Background())
+		eg, _ := parallel.WithContext(context.Background())
 		for _, host := range nodes {
 			host := host
 			eg.Go(func() error {
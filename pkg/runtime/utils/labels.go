@@ -0,0 +1,178 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/labring/sealos/pkg/ssh"
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/iputils"
+	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/retry"
+)
+
+const getNodeAddressesCmd = `kubectl get nodes -o jsonpath='{range .items[*]}{.metadata.name}{" "}{.status.addresses[?(@.type=="InternalIP")].address}{"\n"}{end}'`
+
+var taintEffects = []string{"NoSchedule", "PreferNoSchedule", "NoExecute"}
+
+// ValidateTaint checks the taint is in format key[=value]:effect.
+func ValidateTaint(taint string) error {
+	idx := strings.LastIndex(taint, ":")
+	if idx <= 0 {
+		return fmt.Errorf("taint %s should be in format key[=value]:effect", taint)
+	}
+	if effect := taint[idx+1:]; !slices.Contains(taintEffects, effect) {
+		return fmt.Errorf("unknown effect %s of taint %s, available effects are: %s", effect, taint, strings.Join(taintEffects, ","))
+	}
+	return nil
+}
+
+// taintKey returns the key:effect identifying the taint on a node.
+func taintKey(taint string) string {
+	idx := strings.LastIndex(taint, ":")
+	return strings.SplitN(taint[:idx], "=", 2)[0] + taint[idx:]
+}
+
+// NodeLabelArgs returns the arguments of `kubectl label` and `kubectl taint` that make a node
+// have the labels and taints of host, the ones only declared in previous are removed.
+func NodeLabelArgs(host, previous *v2.Host) (labels []string, taints []string, removedTaints []string, err error) {
+	if host == nil {
+		host = &v2.Host{}
+	}
+	if previous == nil {
+		previous = &v2.Host{}
+	}
+	for k, v := range host.Labels {
+		if old, ok := previous.Labels[k]; !ok || old != v {
+			labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+	for k := range previous.Labels {
+		if _, ok := host.Labels[k]; !ok {
+			labels = append(labels, k+"-")
+		}
+	}
+	sort.Strings(labels)
+
+	keys := make([]string, 0, len(host.Taints))
+	for _, taint := range host.Taints {
+		if err = ValidateTaint(taint); err != nil {
+			return nil, nil, nil, err
+		}
+		keys = append(keys, taintKey(taint))
+		if !slices.Contains(previous.Taints, taint) {
+			taints = append(taints, taint)
+		}
+	}
+	for _, taint := range previous.Taints {
+		if ValidateTaint(taint) != nil {
+			continue
+		}
+		if key := taintKey(taint); !slices.Contains(keys, key) {
+			removedTaints = append(removedTaints, key+"-")
+		}
+	}
+	return labels, taints, removedTaints, nil
+}
+
+// parseNodeAddresses returns the node names by their addresses from the output of getNodeAddressesCmd,
+// a dual-stack node has an InternalIP of each IP family.
+func parseNodeAddresses(out string) map[string]string {
+	names := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		for _, addr := range fields[1:] {
+			names[addr] = fields[0]
+		}
+	}
+	return names
+}
+
+func findHost(hosts []v2.Host, ip string) *v2.Host {
+	for i := range hosts {
+		if slices.Contains(hosts[i].IPS, ip) {
+			return &hosts[i]
+		}
+	}
+	return nil
+}
+
+// SyncNodeLabels applies the labels and taints declared in hosts to the nodes of ips by running kubectl on master0,
+// labels and taints which are declared in previous hosts but not in hosts any more are removed from the nodes.
+func SyncNodeLabels(execer ssh.Interface, master0 string, hosts, previous []v2.Host, ips ...string) error {
+	type nodeArgs struct {
+		labels, taints, removedTaints []string
+	}
+	todo := make(map[string]nodeArgs)
+	for _, ip := range ips {
+		labels, taints, removedTaints, err := NodeLabelArgs(findHost(hosts, ip), findHost(previous, ip))
+		if err != nil {
+			return err
+		}
+		if len(labels)+len(taints)+len(removedTaints) > 0 {
+			todo[ip] = nodeArgs{labels, taints, removedTaints}
+		}
+	}
+	if len(todo) == 0 {
+		return nil
+	}
+
+	names := make(map[string]string)
+	// the joined nodes may not be registered immediately
+	if err := retry.Retry(5, time.Second, func() error {
+		out, err := execer.Cmd(master0, getNodeAddressesCmd)
+		if err != nil {
+			return fmt.Errorf("failed to get nodes: %v", err)
+		}
+		names = parseNodeAddresses(string(out))
+		for ip := range todo {
+			if _, ok := names[iputils.GetHostIP(ip)]; !ok {
+				return fmt.Errorf("node with ip address %s is not found", ip)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for ip, args := range todo {
+		name := names[iputils.GetHostIP(ip)]
+		logger.Info("sync labels and taints of node %s", name)
+		var cmds []string
+		if len(args.labels) > 0 {
+			cmds = append(cmds, fmt.Sprintf("kubectl label node %s --overwrite %s", name, strings.Join(args.labels, " ")))
+		}
+		if len(args.taints) > 0 {
+			cmds = append(cmds, fmt.Sprintf("kubectl taint node %s --overwrite %s", name, strings.Join(args.taints, " ")))
+		}
+		for _, taint := range args.removedTaints {
+			// the taint may have been removed by hand, which fails kubectl
+			cmds = append(cmds, fmt.Sprintf("(kubectl taint node %s %s || true)", name, taint))
+		}
+		if err := execer.CmdAsync(master0, strings.Join(cmds, " && ")); err != nil {
+			return fmt.Errorf("failed to sync labels and taints of node %s: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func TestNodeLabelArgs(t *testing.T) {
+	tests := []struct {
+		name              string
+		host              *v2.Host
+		previous          *v2.Host
+		wantLabels        []string
+		wantTaints        []string
+		wantRemovedTaints []string
+		wantErr           bool
+	}{
+		{
+			name:       "joining",
+			host:       &v2.Host{Labels: map[string]string{"zone": "a", "gpu": "true"}, Taints: []string{"gpu=true:NoSchedule"}},
+			wantLabels: []string{"gpu=true", "zone=a"},
+			wantTaints: []string{"gpu=true:NoSchedule"},
+		},
+		{
+			name:     "unchanged",
+			host:     &v2.Host{Labels: map[string]string{"zone": "a"}, Taints: []string{"gpu:NoSchedule"}},
+			previous: &v2.Host{Labels: map[string]string{"zone": "a"}, Taints: []string{"gpu:NoSchedule"}},
+		},
+		{
+			name:              "reconcile",
+			host:              &v2.Host{Labels: map[string]string{"zone": "b"}, Taints: []string{"gpu=false:NoSchedule"}},
+			previous:          &v2.Host{Labels: map[string]string{"zone": "a", "tier": "web"}, Taints: []string{"gpu=true:NoSchedule", "dedicated=db:NoExecute"}},
+			wantLabels:        []string{"tier-", "zone=b"},
+			wantTaints:        []string{"gpu=false:NoSchedule"},
+			wantRemovedTaints: []string{"dedicated:NoExecute-"},
+		},
+		{
+			name:    "invalid effect",
+			host:    &v2.Host{Taints: []string{"gpu=true:Never"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, taints, removedTaints, err := NodeLabelArgs(tt.host, tt.previous)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NodeLabelArgs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(labels, tt.wantLabels) {
+				t.Errorf("NodeLabelArgs() labels = %v, want %v", labels, tt.wantLabels)
+			}
+			if !reflect.DeepEqual(taints, tt.wantTaints) {
+				t.Errorf("NodeLabelArgs() taints = %v, want %v", taints, tt.wantTaints)
+			}
+			if !reflect.DeepEqual(removedTaints, tt.wantRemovedTaints) {
+				t.Errorf("NodeLabelArgs() removed taints = %v, want %v", removedTaints, tt.wantRemovedTaints)
+			}
+		})
+	}
+}
+
+func Test_parseNodeAddresses(t *testing.T) {
+	out := "master0 192.168.0.2\nnode0 192.168.0.3 fd00::3\n\nnode1\n"
+	want := map[string]string{
+		"192.168.0.2": "master0",
+		"192.168.0.3": "node0",
+		"fd00::3":     "node0",
+	}
+	if got := parseNodeAddresses(out); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNodeAddresses() = %v, want %v", got, want)
+	}
+}
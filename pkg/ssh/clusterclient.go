@@ -19,6 +19,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/labring/sealos/pkg/constants"
 	"github.com/labring/sealos/pkg/types/v1beta1"
 )
 
@@ -50,6 +51,18 @@ func OverSSHConfig(original, override *v1beta1.SSH) {
 		if override.Port > 0 {
 			original.Port = override.Port
 		}
+		if override.PkCert != "" {
+			original.PkCert = override.PkCert
+		}
+		if override.Agent != "" {
+			original.Agent = override.Agent
+		}
+		if override.ProxyJump != "" {
+			original.ProxyJump = override.ProxyJump
+		}
+		if override.HostKeyChecking != "" {
+			original.HostKeyChecking = override.HostKeyChecking
+		}
 	}
 }
 
@@ -70,6 +83,7 @@ func (cc *clusterClient) getSSHOptionForHost(host string) (*Option, error) {
 	}
 
 	opt := newOptionFromSSH(sshConfig, cc.isStdout)
+	WithKnownHosts(constants.KnownHostsFile(cc.cluster.Name), sshConfig.HostKeyChecking)(opt)
 	cc.mutex.Lock()
 	cc.configs[host] = opt
 	cc.mutex.Unlock()
@@ -15,44 +15,99 @@
 package ssh
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
-	"github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
 
 	"github.com/labring/sealos/pkg/utils/iputils"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
-type HostClientMap struct {
-	ClientMap map[string]HostClient
-	Mux       sync.Mutex
+func (c *Client) connect(host string) (*ssh.Client, error) {
+	ip, port := iputils.GetSSHHostIPAndPort(host)
+	addr := formalizeAddr(ip, port)
+	if len(c.Option.proxyJump) == 0 {
+		return ssh.Dial("tcp", addr, c.ClientConfig)
+	}
+	return c.connectThroughJumps(addr)
 }
 
-type HostClient struct {
-	SSHClient  *ssh.Client
-	SftpClient *sftp.Client
+// connectThroughJumps tunnels the connection to addr through the jump hosts in order,
+// the jump clients are closed once the client of addr is closed.
+func (c *Client) connectThroughJumps(addr string) (*ssh.Client, error) {
+	var jumps []*ssh.Client
+	closeJumps := func() {
+		for i := len(jumps) - 1; i >= 0; i-- {
+			_ = jumps[i].Close()
+		}
+	}
+	for _, jump := range c.Option.proxyJump {
+		user, jumpAddr := parseJumpHost(jump)
+		config := *c.ClientConfig
+		if user != "" {
+			config.User = user
+		}
+		var via *ssh.Client
+		if len(jumps) > 0 {
+			via = jumps[len(jumps)-1]
+		}
+		client, err := dialVia(via, jumpAddr, &config)
+		if err != nil {
+			closeJumps()
+			return nil, fmt.Errorf("failed to connect to jump host %s: %w", jump, err)
+		}
+		jumps = append(jumps, client)
+	}
+	client, err := dialVia(jumps[len(jumps)-1], addr, c.ClientConfig)
+	if err != nil {
+		closeJumps()
+		return nil, fmt.Errorf("failed to connect to %s through jump hosts %s: %w", addr, strings.Join(c.Option.proxyJump, ","), err)
+	}
+	go func() {
+		_ = client.Wait()
+		closeJumps()
+	}()
+	return client, nil
 }
 
-var hostsClientMap = &HostClientMap{
-	ClientMap: make(map[string]HostClient),
+// dialVia dials addr directly if via is nil, otherwise the connection is forwarded by via.
+func dialVia(via *ssh.Client, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	if via == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+	conn, err := via.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(clientConn, chans, reqs), nil
 }
 
-func (c *Client) connect(host string) (*ssh.Client, error) {
-	ip, port := iputils.GetSSHHostIPAndPort(host)
-	addr := formalizeAddr(ip, port)
-	return ssh.Dial("tcp", addr, c.ClientConfig)
+// parseJumpHost parses the jump host in format [user@]host[:port], the port defaults to 22.
+func parseJumpHost(jump string) (user string, addr string) {
+	if idx := strings.LastIndex(jump, "@"); idx >= 0 {
+		user, jump = jump[:idx], jump[idx+1:]
+	}
+	if host, port, err := net.SplitHostPort(jump); err == nil {
+		return user, net.JoinHostPort(host, port)
+	}
+	return user, net.JoinHostPort(strings.Trim(jump, "[]"), "22")
 }
 
 func newSession(client *ssh.Client) (*ssh.Session, error) {
 	session, err := client.NewSession()
 	if err != nil {
-		_ = client.Close()
 		return nil, err
 	}
 	modes := ssh.TerminalModes{
@@ -62,7 +117,6 @@ func newSession(client *ssh.Client) (*ssh.Session, error) {
 	}
 	if err := session.RequestPty("xterm", 80, 40, modes); err != nil {
 		_ = session.Close()
-		_ = client.Close()
 		return nil, err
 	}
 	return session, nil
@@ -78,7 +132,9 @@ func (c *Client) Connect(host string) (sshClient *ssh.Client, session *ssh.Sessi
 
 func isErrorWorthRetry(err error) bool {
 	return strings.Contains(err.Error(), "connection reset by peer") ||
-		strings.Contains(err.Error(), io.EOF.Error())
+		strings.Contains(err.Error(), io.EOF.Error()) ||
+		// too many sessions on the pooled connection
+		strings.Contains(err.Error(), "administratively prohibited")
 }
 
 func exponentialBackOffRetry(steps int, interval time.Duration, factor int,
@@ -103,12 +159,7 @@ func exponentialBackOffRetry(steps int, interval time.Duration, factor int,
 }
 
 func (c *Client) newClientAndSession(host string) (*ssh.Client, *ssh.Session, error) {
-	sshClient, err := c.connect(host)
-	if err != nil {
-		return nil, nil, err
-	}
-	session, err := newSession(sshClient)
-	return sshClient, session, err
+	return c.newPooledSession(host)
 }
 
 func parsePrivateKey(pemBytes []byte, password []byte) (ssh.Signer, error) {
@@ -126,6 +177,104 @@ func parsePrivateKeyFile(filename string, password string) (ssh.Signer, error) {
 	return parsePrivateKey(pemBytes, []byte(password))
 }
 
+// certSuffix is the suffix of the OpenSSH certificate file of a private key.
+const certSuffix = "-cert.pub"
+
+// withCertSigner returns the signer of certificate cert followed by signer, signer is returned only if cert is empty.
+func withCertSigner(signer ssh.Signer, cert string) ([]ssh.Signer, error) {
+	if cert == "" {
+		return []ssh.Signer{signer}, nil
+	}
+	data, err := os.ReadFile(cert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate file %v", err)
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate %s: %v", cert, err)
+	}
+	certificate, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an OpenSSH certificate", cert)
+	}
+	if certificate.ValidBefore != ssh.CertTimeInfinity && time.Now().Unix() >= int64(certificate.ValidBefore) {
+		return nil, fmt.Errorf("certificate %s is expired at %s", cert, time.Unix(int64(certificate.ValidBefore), 0))
+	}
+	certSigner, err := ssh.NewCertSigner(certificate, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use certificate %s: %v", cert, err)
+	}
+	return []ssh.Signer{certSigner, signer}, nil
+}
+
+// agentSigners returns the keys of the ssh-agent listening on socket, the connection is closed once
+// the keys are listed. No key is returned if the agent can't be reached, the other keys and the
+// password are still tried.
+func agentSigners(socket string) []ssh.Signer {
+	var signers []ssh.Signer
+	err := withAgent(socket, func(client agent.ExtendedAgent) error {
+		keys, err := client.List()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			signers = append(signers, &agentSigner{socket: socket, pub: key})
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Warn("failed to get keys from ssh-agent %s: %v", socket, err)
+		return nil
+	}
+	return signers
+}
+
+// agentSigner signs with a key of ssh-agent, the agent is dialed again for each signature.
+type agentSigner struct {
+	socket string
+	pub    ssh.PublicKey
+}
+
+func (s *agentSigner) PublicKey() ssh.PublicKey {
+	return s.pub
+}
+
+func (s *agentSigner) Sign(rand io.Reader, data []byte) (*ssh.Signature, error) {
+	return s.SignWithAlgorithm(rand, data, "")
+}
+
+func (s *agentSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*ssh.Signature, error) {
+	var sig *ssh.Signature
+	err := withAgent(s.socket, func(client agent.ExtendedAgent) error {
+		signers, err := client.Signers()
+		if err != nil {
+			return err
+		}
+		for _, signer := range signers {
+			if !bytes.Equal(signer.PublicKey().Marshal(), s.pub.Marshal()) {
+				continue
+			}
+			if algorithmSigner, ok := signer.(ssh.AlgorithmSigner); ok {
+				sig, err = algorithmSigner.SignWithAlgorithm(rand, data, algorithm)
+			} else {
+				sig, err = signer.Sign(rand, data)
+			}
+			return err
+		}
+		return fmt.Errorf("key %s is removed from ssh-agent", ssh.FingerprintSHA256(s.pub))
+	})
+	return sig, err
+}
+
+func withAgent(socket string, fn func(agent.ExtendedAgent) error) error {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return fn(agent.NewClient(conn))
+}
+
 func formalizeAddr(host, port string) string {
 	if !strings.Contains(host, ":") {
 		host = fmt.Sprintf("%s:%s", host, port)
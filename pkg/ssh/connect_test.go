@@ -0,0 +1,201 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func Test_parseJumpHost(t *testing.T) {
+	tests := []struct {
+		jump     string
+		wantUser string
+		wantAddr string
+	}{
+		{jump: "10.0.0.1", wantAddr: "10.0.0.1:22"},
+		{jump: "admin@10.0.0.1:2222", wantUser: "admin", wantAddr: "10.0.0.1:2222"},
+		{jump: "bastion.example.com", wantAddr: "bastion.example.com:22"},
+		{jump: "admin@[fe80::1]:2222", wantUser: "admin", wantAddr: "[fe80::1]:2222"},
+		{jump: "[fe80::1]", wantAddr: "[fe80::1]:22"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jump, func(t *testing.T) {
+			user, addr := parseJumpHost(tt.jump)
+			if user != tt.wantUser || addr != tt.wantAddr {
+				t.Errorf("parseJumpHost() = %s, %s, want %s, %s", user, addr, tt.wantUser, tt.wantAddr)
+			}
+		})
+	}
+}
+
+func TestWithProxyJump(t *testing.T) {
+	opt := NewOption()
+	WithProxyJump("admin@10.0.0.1, 10.0.1.1:2222,")(opt)
+	want := []string{"admin@10.0.0.1", "10.0.1.1:2222"}
+	if !reflect.DeepEqual(opt.proxyJump, want) {
+		t.Errorf("WithProxyJump() = %v, want %v", opt.proxyJump, want)
+	}
+}
+
+func Test_withCertSigner(t *testing.T) {
+	newSigner := func() ssh.Signer {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer, err := ssh.NewSignerFromKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signer
+	}
+	ca, user := newSigner(), newSigner()
+	writeCert := func(name string, validBefore time.Time) string {
+		cert := &ssh.Certificate{
+			Key:             user.PublicKey(),
+			CertType:        ssh.UserCert,
+			ValidPrincipals: []string{"root"},
+			ValidBefore:     uint64(validBefore.Unix()),
+		}
+		if err := cert.SignCert(rand.Reader, ca); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), name)
+		if err := os.WriteFile(path, ssh.MarshalAuthorizedKey(cert), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	notCert := filepath.Join(t.TempDir(), "id_ed25519.pub")
+	if err := os.WriteFile(notCert, ssh.MarshalAuthorizedKey(user.PublicKey()), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		cert      string
+		wantCount int
+		wantErr   bool
+	}{
+		{name: "no certificate", wantCount: 1},
+		{name: "valid certificate", cert: writeCert("valid-cert.pub", time.Now().Add(time.Hour)), wantCount: 2},
+		{name: "expired certificate", cert: writeCert("expired-cert.pub", time.Now().Add(-time.Hour)), wantErr: true},
+		{name: "not a certificate", cert: notCert, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signers, err := withCertSigner(user, tt.cert)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withCertSigner() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(signers) != tt.wantCount {
+				t.Fatalf("withCertSigner() returns %d signers, want %d", len(signers), tt.wantCount)
+			}
+			if tt.wantCount == 2 {
+				if _, ok := signers[0].PublicKey().(*ssh.Certificate); !ok {
+					t.Errorf("withCertSigner() does not put the certificate first")
+				}
+			}
+		})
+	}
+}
+
+func Test_agentSigners(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring := agent.NewKeyring()
+	for _, key := range []interface{}{edKey, rsaKey} {
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	socket := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var opened atomic.Int32
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			opened.Add(1)
+			go func() {
+				_ = agent.ServeAgent(keyring, conn)
+				opened.Add(-1)
+			}()
+		}
+	}()
+	waitClosed := func() {
+		for i := 0; i < 100 && opened.Load() > 0; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if n := opened.Load(); n > 0 {
+			t.Errorf("%d connections to ssh-agent are not closed", n)
+		}
+	}
+
+	signers := agentSigners(socket)
+	if len(signers) != 2 {
+		t.Fatalf("agentSigners() returns %d signers, want 2", len(signers))
+	}
+	waitClosed()
+	data := []byte("session")
+	for _, signer := range signers {
+		algorithm := signer.PublicKey().Type()
+		if algorithm == ssh.KeyAlgoRSA {
+			algorithm = ssh.KeyAlgoRSASHA256
+		}
+		sig, err := signer.(ssh.AlgorithmSigner).SignWithAlgorithm(rand.Reader, data, algorithm)
+		if err != nil {
+			t.Fatalf("SignWithAlgorithm() error = %v", err)
+		}
+		if sig.Format != algorithm {
+			t.Errorf("SignWithAlgorithm() signs with %s, want %s", sig.Format, algorithm)
+		}
+		if err = signer.PublicKey().Verify(data, sig); err != nil {
+			t.Errorf("signature of %s is invalid: %v", signer.PublicKey().Type(), err)
+		}
+	}
+	waitClosed()
+
+	if signers = agentSigners(filepath.Join(t.TempDir(), "not-exist.sock")); len(signers) != 0 {
+		t.Errorf("agentSigners() returns %d signers for unreachable agent, want 0", len(signers))
+	}
+	if _, err = New(NewOption(), WithAgent(filepath.Join(t.TempDir(), "not-exist.sock"))); err != nil {
+		t.Errorf("New() should not fail for unreachable agent: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/exp/slices"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// knownHostsMutex serializes the reads and writes of known_hosts files in the process.
+var knownHostsMutex sync.Mutex
+
+// KnownHost is a host key pinned in the known_hosts file.
+type KnownHost struct {
+	Hosts       []string
+	Type        string
+	Fingerprint string
+}
+
+// KnownHosts is a trust-on-first-use store of host keys in the OpenSSH known_hosts format.
+type KnownHosts struct {
+	path string
+}
+
+func NewKnownHosts(path string) *KnownHosts {
+	return &KnownHosts{path: path}
+}
+
+func (k *KnownHosts) Path() string {
+	return k.path
+}
+
+// HostKeyCallback returns the callback verifying host keys in the mode of checking,
+// unknown hosts are pinned in accept-new mode and rejected in strict mode.
+func (k *KnownHosts) HostKeyCallback(checking string) ssh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		switch checking {
+		case v2.HostKeyCheckingInsecure:
+			return nil
+		case "", v2.HostKeyCheckingAcceptNew, v2.HostKeyCheckingStrict:
+		default:
+			return fmt.Errorf("unsupported host key checking mode %s, available modes are: %s, %s, %s", checking,
+				v2.HostKeyCheckingStrict, v2.HostKeyCheckingAcceptNew, v2.HostKeyCheckingInsecure)
+		}
+		knownHostsMutex.Lock()
+		defer knownHostsMutex.Unlock()
+
+		err := k.check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if err == nil || !errors.As(err, &keyErr) {
+			return err
+		}
+		fingerprint := ssh.FingerprintSHA256(key)
+		if len(keyErr.Want) > 0 {
+			return fmt.Errorf("host key %s of %s does not match the one pinned in %s, which may be a man-in-the-middle attack, "+
+				"run `sealos ssh-keys rotate %s` if the host key is changed on purpose", fingerprint, hostname, k.path, hostname)
+		}
+		if checking == v2.HostKeyCheckingStrict {
+			return fmt.Errorf("host key %s of %s is not pinned in %s, run `sealos ssh-keys rotate %s` to pin it",
+				fingerprint, hostname, k.path, hostname)
+		}
+		if err = k.add(hostname, remote, key); err != nil {
+			return fmt.Errorf("failed to pin host key of %s: %v", hostname, err)
+		}
+		logger.Info("pinned host key %s of %s in %s", fingerprint, hostname, k.path)
+		return nil
+	}
+}
+
+func (k *KnownHosts) check(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	if _, err := os.Stat(k.path); os.IsNotExist(err) {
+		return &knownhosts.KeyError{}
+	}
+	callback, err := knownhosts.New(k.path)
+	if err != nil {
+		return err
+	}
+	return callback(hostname, remote, key)
+}
+
+func (k *KnownHosts) add(hostname string, remote net.Addr, key ssh.PublicKey) error {
+	addresses := []string{knownhosts.Normalize(hostname)}
+	if remote != nil {
+		if addr := knownhosts.Normalize(remote.String()); !slices.Contains(addresses, addr) {
+			addresses = append(addresses, addr)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(k.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, knownhosts.Line(addresses, key))
+	return err
+}
+
+// List returns the pinned host keys, nothing is returned if the file does not exist.
+func (k *KnownHosts) List() ([]KnownHost, error) {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ret []KnownHost
+	for {
+		_, hosts, key, _, rest, err := ssh.ParseKnownHosts(data)
+		if err == io.EOF {
+			return ret, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", k.path, err)
+		}
+		ret = append(ret, KnownHost{Hosts: hosts, Type: key.Type(), Fingerprint: ssh.FingerprintSHA256(key)})
+		data = rest
+	}
+}
+
+// Remove unpins the host keys of hosts, and returns the number of removed entries.
+func (k *KnownHosts) Remove(hosts ...string) (int, error) {
+	knownHostsMutex.Lock()
+	defer knownHostsMutex.Unlock()
+
+	data, err := os.ReadFile(k.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	targets := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		targets = append(targets, knownhosts.Normalize(host))
+	}
+	var (
+		buf     bytes.Buffer
+		removed int
+	)
+	for _, line := range strings.SplitAfter(string(data), "\n") {
+		_, pinned, _, _, _, err := ssh.ParseKnownHosts([]byte(line))
+		if err == nil && slices.ContainsFunc(pinned, func(h string) bool {
+			return slices.Contains(targets, h)
+		}) {
+			removed++
+			continue
+		}
+		buf.WriteString(line)
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, os.WriteFile(k.path, buf.Bytes(), 0600)
+}
+
+// WithKnownHosts verifies host keys with the known_hosts file in the mode of checking.
+func WithKnownHosts(path, checking string) OptionFunc {
+	return WithHostKeyCallback(NewKnownHosts(path).HostKeyCallback(checking))
+}
@@ -0,0 +1,84 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+
+	v2 "github.com/labring/sealos/pkg/types/v1beta1"
+)
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestKnownHosts(t *testing.T) {
+	k := NewKnownHosts(filepath.Join(t.TempDir(), "default", "known_hosts"))
+	remote := &net.TCPAddr{IP: net.ParseIP("192.168.0.2"), Port: 22}
+	key, changed := newTestHostKey(t), newTestHostKey(t)
+
+	strict := k.HostKeyCallback(v2.HostKeyCheckingStrict)
+	acceptNew := k.HostKeyCallback(v2.HostKeyCheckingAcceptNew)
+	insecure := k.HostKeyCallback(v2.HostKeyCheckingInsecure)
+
+	if err := strict("192.168.0.2:22", remote, key); err == nil {
+		t.Errorf("strict mode accepts unknown host")
+	}
+	if err := acceptNew("192.168.0.2:22", remote, key); err != nil {
+		t.Errorf("accept-new mode rejects unknown host: %v", err)
+	}
+	if err := strict("192.168.0.2:22", remote, key); err != nil {
+		t.Errorf("strict mode rejects pinned host: %v", err)
+	}
+	if err := acceptNew("192.168.0.2:22", remote, changed); err == nil {
+		t.Errorf("accept-new mode accepts changed host key")
+	}
+	if err := insecure("192.168.0.2:22", remote, changed); err != nil {
+		t.Errorf("insecure mode rejects changed host key: %v", err)
+	}
+	if err := k.HostKeyCallback("unknown")("192.168.0.2:22", remote, key); err == nil {
+		t.Errorf("unknown mode is accepted")
+	}
+
+	hosts, err := k.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Fingerprint != ssh.FingerprintSHA256(key) {
+		t.Fatalf("List() = %+v, want the pinned key only", hosts)
+	}
+
+	removed, err := k.Remove("192.168.0.2")
+	if err != nil || removed != 1 {
+		t.Fatalf("Remove() = %d, %v, want 1", removed, err)
+	}
+	if err := acceptNew("192.168.0.2:22", remote, changed); err != nil {
+		t.Errorf("accept-new mode rejects rotated host key: %v", err)
+	}
+}
@@ -15,8 +15,9 @@
 package ssh
 
 import (
-	"net"
+	"os"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/containers/storage/pkg/homedir"
@@ -36,6 +37,9 @@ type Option struct {
 	passphrase        string
 	timeout           time.Duration
 	hostKeyCallback   ssh.HostKeyCallback
+	proxyJump         []string
+	certificate       string
+	agentSocket       string
 }
 
 func (o *Option) BindFlags(fs *pflag.FlagSet) {
@@ -46,7 +50,12 @@ func (o *Option) BindFlags(fs *pflag.FlagSet) {
 	fs.StringVarP(&o.privateKey, "private-key", "i", o.privateKey,
 		"selects a file from which the identity (private key) for public key authentication is read")
 	fs.StringVar(&o.passphrase, "passphrase", o.passphrase, "passphrase for decrypting a PEM encoded private key")
+	fs.StringVar(&o.certificate, "cert", o.certificate,
+		"OpenSSH certificate of the private key, defaults to the file with suffix -cert.pub of private key if exists")
+	fs.StringVar(&o.agentSocket, "ssh-agent", o.agentSocket, "socket of ssh-agent to authenticate with")
 	fs.DurationVar(&o.timeout, "timeout", o.timeout, "ssh connection establish timeout")
+	fs.StringSliceVarP(&o.proxyJump, "proxy-jump", "J", o.proxyJump,
+		"connect to the target host by first making a ssh connection to the jump hosts in format [user@]host[:port]")
 }
 
 const (
@@ -68,9 +77,8 @@ func NewOption() *Option {
 		user:       defaultUsername,
 		privateKey: getSSHFile("id_rsa", "id_dsa"),
 		timeout:    10 * time.Second,
-		hostKeyCallback: func(hostname string, remote net.Addr, key ssh.PublicKey) error {
-			return nil
-		},
+		// clients of clusters verify host keys with the known_hosts of cluster, see WithKnownHosts
+		hostKeyCallback: ssh.InsecureIgnoreHostKey(),
 	}
 	return opt
 }
@@ -115,6 +123,19 @@ func WithPrivateKeyAndPhrase(pk, passphrase string) OptionFunc {
 	}
 }
 
+func WithCertificate(cert string) OptionFunc {
+	return func(o *Option) {
+		o.certificate = cert
+	}
+}
+
+// WithAgent authenticates with the keys in ssh-agent listening on socket, environment variables in socket are expanded.
+func WithAgent(socket string) OptionFunc {
+	return func(o *Option) {
+		o.agentSocket = os.ExpandEnv(socket)
+	}
+}
+
 func WithTimeout(timeout time.Duration) OptionFunc {
 	if timeout == 0 {
 		timeout = 10 * time.Second
@@ -129,3 +150,15 @@ func WithHostKeyCallback(fn ssh.HostKeyCallback) OptionFunc {
 		o.hostKeyCallback = fn
 	}
 }
+
+// WithProxyJump sets the comma separated jump hosts in format [user@]host[:port].
+func WithProxyJump(jumps string) OptionFunc {
+	return func(o *Option) {
+		o.proxyJump = nil
+		for _, jump := range strings.Split(jumps, ",") {
+			if jump = strings.TrimSpace(jump); jump != "" {
+				o.proxyJump = append(o.proxyJump, jump)
+			}
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+var defaultKeepAliveInterval = 15 * time.Second
+
+// pooledConn is the persistent connection to a host, which is shared by sessions and the sftp client.
+type pooledConn struct {
+	mu         sync.Mutex
+	sshClient  *ssh.Client
+	sftpClient *sftp.Client
+}
+
+// connPool caches a persistent connection for each host. Broken connections are detected by
+// keepalive requests or failures of opening sessions, and dialed again on the next use.
+type connPool struct {
+	mu    sync.Mutex
+	conns map[string]*pooledConn
+}
+
+func newConnPool() *connPool {
+	return &connPool{conns: make(map[string]*pooledConn)}
+}
+
+func (p *connPool) get(host string) *pooledConn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pc, ok := p.conns[host]
+	if !ok {
+		pc = &pooledConn{}
+		p.conns[host] = pc
+	}
+	return pc
+}
+
+// getConn returns the pooled connection of host, it is dialed if there is none.
+func (c *Client) getConn(host string) (*ssh.Client, error) {
+	pc := c.pool.get(host)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	return c.getConnLocked(host, pc)
+}
+
+func (c *Client) getConnLocked(host string, pc *pooledConn) (*ssh.Client, error) {
+	if pc.sshClient != nil {
+		return pc.sshClient, nil
+	}
+	client, err := c.connect(host)
+	if err != nil {
+		return nil, err
+	}
+	pc.sshClient = client
+	go c.keepAlive(host, client)
+	return client, nil
+}
+
+// getSftpClient returns the sftp client over the pooled connection of host.
+func (c *Client) getSftpClient(host string) (*ssh.Client, *sftp.Client, error) {
+	pc := c.pool.get(host)
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	client, err := c.getConnLocked(host, pc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pc.sftpClient != nil {
+		return client, pc.sftpClient, nil
+	}
+	var sftpClient *sftp.Client
+	if c.Option.sudo || c.Option.user != defaultUsername {
+		sftpClient, err = NewSudoSftpClient(client, c.password)
+	} else {
+		sftpClient, err = sftp.NewClient(client)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	pc.sftpClient = sftpClient
+	return client, sftpClient, nil
+}
+
+// invalidate closes the connection and removes it from the pool if it is still the pooled one.
+func (c *Client) invalidate(host string, client *ssh.Client) {
+	pc := c.pool.get(host)
+	pc.mu.Lock()
+	if pc.sshClient == client {
+		if pc.sftpClient != nil {
+			_ = pc.sftpClient.Close()
+		}
+		pc.sshClient, pc.sftpClient = nil, nil
+	}
+	pc.mu.Unlock()
+	_ = client.Close()
+}
+
+// newPooledSession opens a session on the pooled connection of host, the connection is dialed
+// again once if it is found broken.
+func (c *Client) newPooledSession(host string) (*ssh.Client, *ssh.Session, error) {
+	client, err := c.getConn(host)
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := newSession(client)
+	var openErr *ssh.OpenChannelError
+	// the connection is healthy if the server refuses to open the channel, e.g. exceeding MaxSessions
+	if err == nil || errors.As(err, &openErr) {
+		return client, session, err
+	}
+	logger.Debug("failed to open session on %s: %v, reconnecting", host, err)
+	c.invalidate(host, client)
+	if client, err = c.getConn(host); err != nil {
+		return nil, nil, err
+	}
+	session, err = newSession(client)
+	return client, session, err
+}
+
+// keepAlive sends keepalive requests on the connection until it is closed, the connection
+// is removed from the pool once a request fails or times out.
+func (c *Client) keepAlive(host string, client *ssh.Client) {
+	closed := make(chan struct{})
+	go func() {
+		_ = client.Wait()
+		close(closed)
+	}()
+	ticker := time.NewTicker(defaultKeepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			c.invalidate(host, client)
+			return
+		case <-ticker.C:
+			errCh := make(chan error, 1)
+			go func() {
+				_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+				errCh <- err
+			}()
+			var err error
+			select {
+			case err = <-errCh:
+			case <-time.After(defaultKeepAliveInterval):
+				err = fmt.Errorf("no reply in %s", defaultKeepAliveInterval)
+			}
+			if err != nil {
+				logger.Debug("keepalive of connection to %s failed: %v, it will be reconnected on the next use", host, err)
+				c.invalidate(host, client)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// testServer is a ssh server accepting sessions without authentication.
+type testServer struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+	mu       sync.Mutex
+	conns    []*ssh.ServerConn
+}
+
+func newTestServer(t *testing.T) *testServer {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{listener: listener, config: config}
+	go s.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return s
+}
+
+func (s *testServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			serverConn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.conns = append(s.conns, serverConn)
+			s.mu.Unlock()
+			go ssh.DiscardRequests(reqs)
+			for ch := range chans {
+				channel, requests, err := ch.Accept()
+				if err != nil {
+					continue
+				}
+				go func() {
+					for req := range requests {
+						_ = req.Reply(true, nil)
+					}
+					_ = channel.Close()
+				}()
+			}
+		}()
+	}
+}
+
+func (s *testServer) connCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.conns)
+}
+
+func (s *testServer) closeConns() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func TestPooledConnection(t *testing.T) {
+	server := newTestServer(t)
+	host := server.listener.Addr().String()
+	client, err := New(nil, WithPrivateKeyAndPhrase("", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := client.Ping(host); err != nil {
+			t.Fatalf("Ping() error = %v", err)
+		}
+	}
+	if n := server.connCount(); n != 1 {
+		t.Fatalf("got %d connections, want the pooled one only", n)
+	}
+
+	server.closeConns()
+	// wait for the broken connection to be removed from the pool
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		pc := client.pool.get(host)
+		pc.mu.Lock()
+		removed := pc.sshClient == nil
+		pc.mu.Unlock()
+		if removed || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := client.Ping(host); err != nil {
+		t.Fatalf("Ping() after the connection is broken error = %v", err)
+	}
+	if n := server.connCount(); n != 2 {
+		t.Fatalf("got %d connections, want 2 after reconnecting", n)
+	}
+}
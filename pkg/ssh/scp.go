@@ -60,37 +60,7 @@ func (c *Client) CmdToString(host, cmd, sep string) (string, error) {
 }
 
 func (c *Client) newClientAndSftpClient(host string) (*ssh.Client, *sftp.Client, error) {
-	var (
-		sshClient  *ssh.Client
-		sftpClient *sftp.Client
-		err        error
-	)
-
-	hostsClientMap.Mux.Lock()
-	defer hostsClientMap.Mux.Unlock()
-	if hc, ok := hostsClientMap.ClientMap[host]; ok {
-		return hc.SSHClient, hc.SftpClient, err
-	}
-	sshClient, err = c.connect(host)
-	if err != nil {
-		return nil, nil, err
-	}
-	// create sftp client
-	if c.Option.sudo || c.Option.user != defaultUsername {
-		sftpClient, err = NewSudoSftpClient(sshClient, c.password)
-	} else {
-		sftpClient, err = sftp.NewClient(sshClient)
-	}
-
-	if err == nil {
-		hc := HostClient{
-			SSHClient:  sshClient,
-			SftpClient: sftpClient,
-		}
-		hostsClientMap.ClientMap[host] = hc
-	}
-
-	return sshClient, sftpClient, err
+	return c.getSftpClient(host)
 }
 
 func (c *Client) sftpConnect(host string) (sshClient *ssh.Client, sftpClient *sftp.Client, err error) {
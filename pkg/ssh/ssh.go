@@ -20,11 +20,11 @@ import (
 
 	"github.com/spf13/pflag"
 	"golang.org/x/crypto/ssh"
-	"golang.org/x/sync/errgroup"
 
 	v2 "github.com/labring/sealos/pkg/types/v1beta1"
 	fileutils "github.com/labring/sealos/pkg/utils/file"
 	"github.com/labring/sealos/pkg/utils/logger"
+	"github.com/labring/sealos/pkg/utils/parallel"
 )
 
 var (
@@ -65,6 +65,7 @@ type Interface interface {
 type Client struct {
 	*ssh.ClientConfig
 	*Option
+	pool *connPool
 }
 
 var _ Interface = &Client{}
@@ -102,12 +103,15 @@ func newFromOptions(opt *Option, opts ...OptionFunc) (*Client, error) {
 	if len(opt.password) > 0 {
 		config.Auth = append(config.Auth, ssh.Password(opt.password))
 	}
+	var signers []ssh.Signer
 	if len(opt.rawPrivateKeyData) > 0 {
 		signer, err := parsePrivateKey([]byte(opt.rawPrivateKeyData), []byte(opt.passphrase))
 		if err != nil {
 			return nil, err
 		}
-		config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+		if signers, err = withCertSigner(signer, opt.certificate); err != nil {
+			return nil, err
+		}
 	} else if len(opt.privateKey) > 0 {
 		if !fileutils.IsExist(opt.privateKey) {
 			logger.Debug("not trying to parse private key file cause it's not exists")
@@ -116,10 +120,25 @@ func newFromOptions(opt *Option, opts ...OptionFunc) (*Client, error) {
 			if err != nil {
 				return nil, err
 			}
-			config.Auth = append(config.Auth, ssh.PublicKeys(signer))
+			cert := opt.certificate
+			if cert == "" && fileutils.IsExist(opt.privateKey+certSuffix) {
+				cert = opt.privateKey + certSuffix
+			}
+			if signers, err = withCertSigner(signer, cert); err != nil {
+				return nil, err
+			}
 		}
 	}
-	return &Client{ClientConfig: config, Option: opt}, nil
+	// the client only tries the first auth method of each type, so that all keys are in one method
+	if len(signers) > 0 || len(opt.agentSocket) > 0 {
+		config.Auth = append(config.Auth, ssh.PublicKeysCallback(func() ([]ssh.Signer, error) {
+			if len(opt.agentSocket) == 0 {
+				return signers, nil
+			}
+			return append(append([]ssh.Signer{}, signers...), agentSigners(opt.agentSocket)...), nil
+		}))
+	}
+	return &Client{ClientConfig: config, Option: opt, pool: newConnPool()}, nil
 }
 
 func newOptionFromSSH(ssh *v2.SSH, isStdout bool) *Option {
@@ -138,6 +157,15 @@ func newOptionFromSSH(ssh *v2.SSH, isStdout bool) *Option {
 	if len(ssh.PkData) > 0 {
 		opts = append(opts, WithRawPrivateKeyDataAndPhrase(ssh.PkData, ssh.PkPasswd))
 	}
+	if len(ssh.PkCert) > 0 {
+		opts = append(opts, WithCertificate(ssh.PkCert))
+	}
+	if len(ssh.Agent) > 0 {
+		opts = append(opts, WithAgent(ssh.Agent))
+	}
+	if len(ssh.ProxyJump) > 0 {
+		opts = append(opts, WithProxyJump(ssh.ProxyJump))
+	}
 	if ssh.User != "" && ssh.User != defaultUsername {
 		opts = append(opts, WithSudoEnable(true))
 	}
@@ -149,12 +177,12 @@ func newOptionFromSSH(ssh *v2.SSH, isStdout bool) *Option {
 	return opt
 }
 
-func newFromSSH(ssh *v2.SSH, isStdout bool) (Interface, error) {
-	return New(newOptionFromSSH(ssh, isStdout))
+func newFromSSH(ssh *v2.SSH, isStdout bool, opts ...OptionFunc) (Interface, error) {
+	return New(newOptionFromSSH(ssh, isStdout), opts...)
 }
 
-func MustNewClient(ssh *v2.SSH, isStdout bool) Interface {
-	client, err := newFromSSH(ssh, isStdout)
+func MustNewClient(ssh *v2.SSH, isStdout bool, opts ...OptionFunc) Interface {
+	client, err := newFromSSH(ssh, isStdout, opts...)
 	if err != nil {
 		logger.Fatal("failed to create ssh client: %v", err)
 	}
@@ -172,7 +200,7 @@ func NewCacheClientFromCluster(cluster *v2.Cluster, isStdout bool) Interface {
 }
 
 func WaitReady(client Interface, _ int, hosts ...string) error {
-	eg, _ := errgroup.WithContext(context.Background())
+	eg, _ := parallel.WithContext(context.Background())
 	for i := range hosts {
 		host := hosts[i]
 		eg.Go(func() (err error) {
@@ -26,15 +26,16 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/labring/sealos/pkg/transcript"
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
 func (c *Client) Ping(host string) error {
-	client, _, err := c.Connect(host)
+	_, session, err := c.Connect(host)
 	if err != nil {
 		return fmt.Errorf("failed to connect %s: %v", host, err)
 	}
-	return client.Close()
+	return session.Close()
 }
 
 func (c *Client) wrapCommands(cmds ...string) string {
@@ -44,14 +45,15 @@ func (c *Client) wrapCommands(cmds ...string) string {
 	return fmt.Sprintf("sudo -E /bin/bash -c '%s'", strings.Join(cmds, "; "))
 }
 
-func (c *Client) CmdAsyncWithContext(ctx context.Context, host string, cmds ...string) error {
+func (c *Client) CmdAsyncWithContext(ctx context.Context, host string, cmds ...string) (err error) {
+	finish := transcript.StartCommand(host, strings.Join(cmds, "; "))
+	defer func() { finish(err) }()
 	cmd := c.wrapCommands(cmds...)
 	logger.Debug("start to exec `%s` on %s", cmd, host)
-	client, session, err := c.Connect(host)
+	_, session, err := c.Connect(host)
 	if err != nil {
 		return fmt.Errorf("connect error: %v", err)
 	}
-	defer client.Close()
 	defer session.Close()
 	stdout, err := session.StdoutPipe()
 	if err != nil {
@@ -84,7 +86,7 @@ func (c *Client) CmdAsyncWithContext(ctx context.Context, host string, cmds ...s
 				return err
 			}
 			if err = session.Wait(); err != nil {
-				return fmt.Errorf("run command `%s` on %s, output: %s, error: %v,", cmd, host, out.b.String(), err)
+				return fmt.Errorf("run command `%s` on %s, output: %s, error: %w,", cmd, host, out.b.String(), err)
 			}
 			return nil
 		}()
@@ -104,14 +106,15 @@ func (c *Client) CmdAsync(host string, cmds ...string) error {
 	return c.CmdAsyncWithContext(ctx, host, cmds...)
 }
 
-func (c *Client) Cmd(host, cmd string) ([]byte, error) {
+func (c *Client) Cmd(host, cmd string) (_ []byte, err error) {
+	finish := transcript.StartCommand(host, cmd)
+	defer func() { finish(err) }()
 	cmd = c.wrapCommands(cmd)
 	logger.Debug("start to exec `%s` on %s", cmd, host)
-	client, session, err := c.Connect(host)
+	_, session, err := c.Connect(host)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ssh session for %s: %v", host, err)
 	}
-	defer client.Close()
 	defer session.Close()
 	in, err := session.StdinPipe()
 	if err != nil {
@@ -125,6 +128,7 @@ func (c *Client) Cmd(host, cmd string) ([]byte, error) {
 	session.Stdout = &b
 	session.Stderr = &b
 	err = session.Run(cmd)
+	_, _ = transcript.Writer(host).Write(b.b.Bytes())
 	return b.b.Bytes(), err
 }
 
@@ -177,7 +181,7 @@ func isSudoPrompt(p []byte) bool {
 
 func (c *Client) handlePipe(host string, pipe io.Reader, out io.Writer, isStdout bool) error {
 	r := bufio.NewReader(pipe)
-	writers := []io.Writer{out}
+	writers := []io.Writer{out, transcript.Writer(host)}
 	if isStdout {
 		writers = append(writers, &withPrefixWriter{prefix: host + "\t", newline: true, w: os.Stdout})
 	}
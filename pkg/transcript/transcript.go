@@ -0,0 +1,314 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transcript records the outputs of commands executed on each host during an apply run,
+// so that a failed run can be inspected per host afterwards with `sealos logs`.
+package transcript
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labring/sealos/pkg/constants"
+	"github.com/labring/sealos/pkg/events"
+	"github.com/labring/sealos/pkg/utils/iputils"
+)
+
+const (
+	runFileName   = "run.json"
+	logFileSuffix = ".log"
+	runIDLayout   = "20060102-150405"
+	timeLayout    = "15:04:05"
+	redacted      = "******"
+)
+
+// secretFlags are the flags of credentials, their values are redacted from the recorded command.
+var secretFlags = map[string]bool{"-p": true, "--passwd": true, "--pk-passwd": true}
+
+// Run is the metadata of an apply run, it is stored in the run directory with the host logs.
+type Run struct {
+	ID         string     `json:"id"`
+	Command    string     `json:"command"`
+	StartTime  time.Time  `json:"startTime"`
+	FinishTime *time.Time `json:"finishTime,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	// Hosts are the hosts which have any outputs recorded, in the order of the first output.
+	Hosts []string `json:"hosts,omitempty"`
+
+	dir string
+}
+
+// Status returns Running if the run is not finished, it is also the case of a killed run.
+func (r *Run) Status() string {
+	switch {
+	case r.FinishTime == nil:
+		return "Running"
+	case r.Error != "":
+		return "Failed"
+	default:
+		return "Succeeded"
+	}
+}
+
+// LogFile returns the log file of host, which is matched by the address or the IP only.
+func (r *Run) LogFile(host string) (string, bool) {
+	for _, h := range r.Hosts {
+		if h == host || iputils.GetHostIP(h) == host {
+			return filepath.Join(r.dir, logFileName(h)), true
+		}
+	}
+	return "", false
+}
+
+type hostLog struct {
+	mu     sync.Mutex
+	f      *os.File
+	closed bool
+}
+
+// Write never fails, outputs written after the run finished are dropped.
+func (l *hostLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.closed {
+		_, _ = l.f.Write(p)
+	}
+	return len(p), nil
+}
+
+func (l *hostLog) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	_ = l.f.Close()
+}
+
+type recorder struct {
+	run  Run
+	logs map[string]*hostLog
+}
+
+var (
+	mu      sync.Mutex
+	current *recorder
+)
+
+// Start starts recording a run of the cluster, and returns the function finishing it with the result.
+// Nested calls are no-op, the outputs are recorded in the outermost run.
+func Start(clusterName string) func(err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current != nil {
+		return func(error) {}
+	}
+	now := time.Now()
+	dir, id, err := mkRunDir(constants.RunLogsDir(clusterName), now)
+	if err != nil {
+		// never break the apply because of the transcript
+		return func(error) {}
+	}
+	r := &recorder{
+		run: Run{
+			ID:        id,
+			Command:   strings.Join(redactArgs(os.Args), " "),
+			StartTime: now,
+			dir:       dir,
+		},
+		logs: make(map[string]*hostLog),
+	}
+	_ = writeRun(&r.run)
+	current = r
+	return func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, l := range r.logs {
+			l.close()
+		}
+		finish := time.Now()
+		r.run.FinishTime = &finish
+		if err != nil {
+			r.run.Error = err.Error()
+		}
+		_ = writeRun(&r.run)
+		if current == r {
+			current = nil
+		}
+	}
+}
+
+// redactArgs returns a copy of args with the values of secret flags redacted, in the forms of
+// "--flag value", "--flag=value" and "-pvalue".
+func redactArgs(args []string) []string {
+	ret := append([]string(nil), args...)
+	for i := 0; i < len(ret); i++ {
+		arg := ret[i]
+		if arg == "--" {
+			break
+		}
+		if name, _, ok := strings.Cut(arg, "="); ok && secretFlags[name] {
+			ret[i] = name + "=" + redacted
+			continue
+		}
+		if secretFlags[arg] {
+			if i+1 < len(ret) {
+				i++
+				ret[i] = redacted
+			}
+			continue
+		}
+		if len(arg) > 2 && strings.HasPrefix(arg, "-p") {
+			ret[i] = "-p" + redacted
+		}
+	}
+	return ret
+}
+
+func mkRunDir(root string, now time.Time) (string, string, error) {
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", "", err
+	}
+	id := now.Format(runIDLayout)
+	for i := 1; ; i++ {
+		dir := filepath.Join(root, id)
+		err := os.Mkdir(dir, 0700)
+		if err == nil {
+			return dir, id, nil
+		}
+		if !os.IsExist(err) {
+			return "", "", err
+		}
+		id = fmt.Sprintf("%s-%d", now.Format(runIDLayout), i)
+	}
+}
+
+func writeRun(r *Run) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, runFileName), b, 0600)
+}
+
+func logFileName(host string) string {
+	return strings.NewReplacer(":", "_", "/", "_", "[", "", "]", "").Replace(host) + logFileSuffix
+}
+
+// Writer returns the writer of the log of host in the current run, io.Discard is returned
+// if there is no run being recorded. It is safe for concurrent use.
+func Writer(host string) io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	if current == nil {
+		return io.Discard
+	}
+	if l, ok := current.logs[host]; ok {
+		return l
+	}
+	f, err := os.OpenFile(filepath.Join(current.run.dir, logFileName(host)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return io.Discard
+	}
+	l := &hostLog{f: f}
+	current.logs[host] = l
+	current.run.Hosts = append(current.run.Hosts, host)
+	_ = writeRun(&current.run)
+	return l
+}
+
+// StartCommand writes the command to the log of host, and returns the function writing its result.
+func StartCommand(host, command string) func(err error) {
+	w := Writer(host)
+	if w == io.Discard {
+		return func(error) {}
+	}
+	start := time.Now()
+	fmt.Fprintf(w, "[%s] $ %s\n", start.Format(timeLayout), command)
+	return func(err error) {
+		result := "succeeded"
+		if code := events.ExitCode(err); code != nil && *code != 0 {
+			result = fmt.Sprintf("exited with code %d", *code)
+		} else if err != nil {
+			result = fmt.Sprintf("failed: %v", err)
+		}
+		fmt.Fprintf(w, "[%s] %s in %s\n\n", time.Now().Format(timeLayout), result, time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// ListRuns returns the recorded runs of the cluster, from the oldest to the latest.
+func ListRuns(clusterName string) ([]*Run, error) {
+	root := constants.RunLogsDir(clusterName)
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []*Run
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		r, err := readRun(filepath.Join(root, entry.Name()))
+		if err != nil {
+			// e.g. the directory is created by others
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartTime.Before(runs[j].StartTime)
+	})
+	return runs, nil
+}
+
+// GetRun returns the run with id, the latest run is returned if id is empty.
+func GetRun(clusterName, id string) (*Run, error) {
+	if id != "" {
+		r, err := readRun(filepath.Join(constants.RunLogsDir(clusterName), id))
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("run %s of cluster %s is not found", id, clusterName)
+		}
+		return r, err
+	}
+	runs, err := ListRuns(clusterName)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, errors.New("no run is recorded for cluster " + clusterName)
+	}
+	return runs[len(runs)-1], nil
+}
+
+func readRun(dir string) (*Run, error) {
+	b, err := os.ReadFile(filepath.Join(dir, runFileName))
+	if err != nil {
+		return nil, err
+	}
+	r := &Run{}
+	if err := json.Unmarshal(b, r); err != nil {
+		return nil, err
+	}
+	r.dir = dir
+	return r, nil
+}
@@ -0,0 +1,145 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labring/sealos/pkg/constants"
+)
+
+func TestTranscript(t *testing.T) {
+	root := constants.DefaultRuntimeRootDir
+	constants.DefaultRuntimeRootDir = t.TempDir()
+	defer func() { constants.DefaultRuntimeRootDir = root }()
+
+	if w := Writer("192.168.0.2:22"); w != io.Discard {
+		t.Fatal("Writer() should discard outputs if no run is being recorded")
+	}
+
+	for i := 0; i < 2; i++ {
+		finish := Start("default")
+		// nested runs are recorded in the outer one
+		Start("default")(nil)
+		exitErr := exec.Command("/bin/sh", "-c", "exit 3").Run()
+		done := StartCommand("192.168.0.2:22", "exit 3")
+		fmt.Fprintln(Writer("192.168.0.2:22"), "output of exit")
+		done(fmt.Errorf("run command: %w", exitErr))
+		StartCommand("192.168.0.3:22", "true")(nil)
+		if i == 0 {
+			finish(errors.New("failed"))
+		} else {
+			finish(nil)
+		}
+	}
+
+	runs, err := ListRuns("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(runs))
+	}
+	if runs[0].ID == runs[1].ID {
+		t.Errorf("runs have the same id %s", runs[0].ID)
+	}
+	for i, want := range []string{"Failed", "Succeeded"} {
+		if got := runs[i].Status(); got != want {
+			t.Errorf("Status() of run %d = %s, want %s", i, got, want)
+		}
+	}
+
+	latest, err := GetRun("default", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != runs[1].ID {
+		t.Errorf("GetRun() = %s, want the latest run %s", latest.ID, runs[1].ID)
+	}
+	if len(latest.Hosts) != 2 {
+		t.Fatalf("got hosts %v, want 2 hosts", latest.Hosts)
+	}
+	path, ok := latest.LogFile("192.168.0.2")
+	if !ok {
+		t.Fatal("LogFile() should match the host by IP")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"$ exit 3\n", "output of exit\n", "exited with code 3"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("log %q does not contain %q", b, want)
+		}
+	}
+	if _, ok := latest.LogFile("192.168.0.4:22"); ok {
+		t.Error("LogFile() should not match unknown hosts")
+	}
+	if _, err := GetRun("default", "not-exist"); err == nil {
+		t.Error("GetRun() should fail for unknown runs")
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "no secret",
+			args: []string{"sealos", "run", "labring/kubernetes:v1.25.0", "--masters", "192.168.0.2"},
+			want: []string{"sealos", "run", "labring/kubernetes:v1.25.0", "--masters", "192.168.0.2"},
+		},
+		{
+			name: "separated values",
+			args: []string{"sealos", "run", "-p", "secret", "--pk-passwd", "phrase", "--masters", "192.168.0.2"},
+			want: []string{"sealos", "run", "-p", redacted, "--pk-passwd", redacted, "--masters", "192.168.0.2"},
+		},
+		{
+			name: "joined values",
+			args: []string{"sealos", "add", "--passwd=secret", "-p=secret", "-psecret", "--nodes", "192.168.0.3"},
+			want: []string{"sealos", "add", "--passwd=" + redacted, "-p=" + redacted, "-p" + redacted, "--nodes", "192.168.0.3"},
+		},
+		{
+			name: "missing value",
+			args: []string{"sealos", "run", "--passwd"},
+			want: []string{"sealos", "run", "--passwd"},
+		},
+		{
+			name: "after terminator",
+			args: []string{"sealos", "run", "-p", "secret", "--", "-p", "value"},
+			want: []string{"sealos", "run", "-p", redacted, "--", "-p", "value"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string(nil), tt.args...)
+			if got := redactArgs(tt.args); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("redactArgs() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(tt.args, args) {
+				t.Errorf("redactArgs() modified args to %v", tt.args)
+			}
+		})
+	}
+}
@@ -78,11 +78,14 @@ var (
 	imageTypeKeyV2         = path.Join(GroupName, "type")
 	imageVersionKeyV2      = path.Join(GroupName, "version")
 	imageDistributionKeyV2 = path.Join(GroupName, "distribution")
+	imageUninstallKey      = "sealos.io.uninstall"
+	imageUninstallKeyV2    = path.Join(GroupName, "uninstall")
 )
 
 var ImageTypeKeys = []string{imageTypeKey, imageTypeKeyV2}
 var ImageVersionKeys = []string{imageVersionKey, imageVersionKeyV2}
 var ImageDistributionKeys = []string{imageDistributionKey, imageDistributionKeyV2}
+var ImageUninstallKeys = []string{imageUninstallKeyV2, imageUninstallKey}
 
 type MountImage struct {
 	Name       string            `json:"name"`
@@ -114,6 +117,16 @@ func (m *MountImage) IsPatch() bool {
 	return m.Type == PatchImage
 }
 
+// UninstallCommand returns the command to remove what the image has installed, which is set by image label.
+func (m *MountImage) UninstallCommand() string {
+	for _, k := range ImageUninstallKeys {
+		if v, ok := m.Labels[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
 func MergeEnvWithBuiltinKeys(src map[string]string, m MountImage) map[string]string {
 	out := make(map[string]string, len(src))
 	for k, v := range src {
@@ -141,6 +154,9 @@ const (
 	CommandConditionTypeSuccess   string = "ApplyCommandSuccess"
 	CommandConditionTypeError     string = "ApplyCommandError"
 	CommandConditionTypeCancelled string = "ApplyCommandCancelled"
+
+	UninstallCommandConditionTypeSuccess string = "UninstallCommandSuccess"
+	UninstallCommandConditionTypeError   string = "UninstallCommandError"
 )
 
 // ClusterCondition describes the state of a cluster at a certain point.
@@ -207,11 +223,34 @@ func NewCancelledCommandCondition(message string) CommandCondition {
 	}
 }
 
+func NewSuccessUninstallCommandCondition() CommandCondition {
+	return CommandCondition{
+		Type:              UninstallCommandConditionTypeSuccess,
+		Status:            v1.ConditionTrue,
+		LastHeartbeatTime: metav1.Now(),
+		Reason:            "Uninstall Command",
+		Message:           "Uninstalled from cluster successfully",
+	}
+}
+
+func NewFailedUninstallCommandCondition(message string) CommandCondition {
+	return CommandCondition{
+		Type:              UninstallCommandConditionTypeError,
+		Status:            v1.ConditionFalse,
+		LastHeartbeatTime: metav1.Now(),
+		Reason:            "Uninstall Command",
+		Message:           message,
+	}
+}
+
 type ClusterStatus struct {
 	Phase             ClusterPhase       `json:"phase,omitempty"`
 	Mounts            []MountImage       `json:"mounts,omitempty"`
 	Conditions        []ClusterCondition `json:"conditions,omitempty"`
 	CommandConditions []CommandCondition `json:"commandCondition,omitempty"`
+	// CompletedPhases records the phases of create pipeline that have been executed successfully,
+	// a failed creation will be resumed from the first phase which is not in the list.
+	CompletedPhases []string `json:"completedPhases,omitempty"`
 }
 
 type SSH struct {
@@ -222,8 +261,29 @@ type SSH struct {
 	Pk       string `json:"pk,omitempty"`
 	PkPasswd string `json:"pkPasswd,omitempty"`
 	Port     uint16 `json:"port,omitempty"`
+	// PkCert is the OpenSSH certificate of Pk, defaults to the file with suffix -cert.pub of Pk if exists.
+	PkCert string `json:"pkCert,omitempty"`
+	// Agent is the socket of ssh-agent to authenticate with, environment variables in it are expanded,
+	// e.g. $SSH_AUTH_SOCK.
+	Agent string `json:"agent,omitempty"`
+	// ProxyJump is the comma separated jump hosts in format [user@]host[:port], the connection to
+	// the target host is tunneled through them in order, which is the same as the -J option of ssh.
+	// The jump hosts are authenticated with the same credentials as the target host.
+	ProxyJump string `json:"proxyJump,omitempty"`
+	// HostKeyChecking is the mode to verify host keys with the known_hosts of cluster,
+	// one of strict, accept-new and insecure, defaults to accept-new.
+	HostKeyChecking string `json:"hostKeyChecking,omitempty"`
 }
 
+const (
+	// HostKeyCheckingStrict only accepts the host keys pinned in known_hosts.
+	HostKeyCheckingStrict = "strict"
+	// HostKeyCheckingAcceptNew pins the host keys of unknown hosts on first use, and rejects changed ones.
+	HostKeyCheckingAcceptNew = "accept-new"
+	// HostKeyCheckingInsecure accepts any host key without verification.
+	HostKeyCheckingInsecure = "insecure"
+)
+
 func (s *SSH) DefaultPort() uint16 {
 	if s.Port != 0 {
 		return s.Port
@@ -236,6 +296,39 @@ type Host struct {
 	Roles []string `json:"roles,omitempty"`
 	Env   []string `json:"env,omitempty"` // overwrite env
 	SSH   *SSH     `json:"ssh,omitempty"` // overwrite global ssh config
+	// Labels and Taints are set to the nodes of the hosts
+	Labels map[string]string `json:"labels,omitempty"`
+	Taints []string          `json:"taints,omitempty"`
+}
+
+// HostGroup is a named group of hosts sharing the same settings, groups are expanded
+// into hosts when the Clusterfile is loaded, and the group names are appended to the
+// roles of their hosts.
+type HostGroup struct {
+	Name string `json:"name"`
+	// Hosts supports ranges in brackets, e.g. 192.168.0.[10:50]:22
+	Hosts []string `json:"hosts,omitempty"`
+	// Children are the names of groups inheriting the settings of this group,
+	// the settings of children overwrite the inherited ones.
+	Children []string          `json:"children,omitempty"`
+	Roles    []string          `json:"roles,omitempty"`
+	Env      []string          `json:"env,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Taints   []string          `json:"taints,omitempty"`
+	SSH      *SSH              `json:"ssh,omitempty"`
+}
+
+// PreflightCheck configures a preflight check run on hosts before creating the cluster or joining them.
+type PreflightCheck struct {
+	// Name is the name of the check, one of swap, kernel-modules, disk, ports and cgroup.
+	Name string `json:"name"`
+	// Disabled skips the check.
+	Disabled bool `json:"disabled,omitempty"`
+	// Level is the result of a host not passing the check, one of warn and fail, defaults to the
+	// level of the check.
+	Level string `json:"level,omitempty"`
+	// Args overwrite the default arguments of the check.
+	Args map[string]string `json:"args,omitempty"`
 }
 
 type ImageList []string
@@ -248,6 +341,8 @@ type ClusterSpec struct {
 	Image ImageList `json:"image,omitempty"`
 	SSH   SSH       `json:"ssh"`
 	Hosts []Host    `json:"hosts,omitempty"`
+	// HostGroups are expanded and appended to Hosts
+	HostGroups []HostGroup `json:"hostGroups,omitempty"`
 	// Why env not using map[string]string
 	// Because some argument is list, like: CertSANS=127.0.0.1 CertSANS=localhost, if ENV is map, will merge those two values
 	// but user want to InfraMetadata a list, using array we can convert it to {CertSANS:[127.0.0.1, localhost]}
@@ -262,4 +357,7 @@ type ClusterSpec struct {
 	// More info: https://kubernetes.io/docs/tasks/inject-data-application/define-command-argument-container/#running-a-command-in-a-shell
 	// +optional
 	Command []string `json:"command,omitempty"`
+	// Preflight configures the preflight checks, checks not listed run with their defaults.
+	// +optional
+	Preflight []PreflightCheck `json:"preflight,omitempty"`
 }
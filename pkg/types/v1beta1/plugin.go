@@ -0,0 +1,96 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Plugins run site-specific hooks on the hosts at the phases of create, scale and reset.
+
+Clusterfile:
+
+apiVersion: apps.sealos.io/v1beta1
+kind: Plugin
+metadata:
+  name: mount-data-disk
+spec:
+  type: shell
+  phases:
+  - Originally
+  - PreJoin
+  roles:
+  - node
+  data: |
+       mkfs.xfs /dev/vdb && mount /dev/vdb /data
+---
+apiVersion: apps.sealos.io/v1beta1
+kind: Plugin
+metadata:
+  name: register-cmdb
+spec:
+  type: binary
+  phases:
+  - PostInstall
+  - PostJoin
+  path: /usr/local/bin/cmdb-register
+  args:
+  - --zone=cn-north
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type PluginType string
+
+const (
+	// ShellPlugin runs the data of plugin by bash.
+	ShellPlugin PluginType = "shell"
+	// BinaryPlugin copies the local binary to the hosts and runs it.
+	BinaryPlugin PluginType = "binary"
+)
+
+// PluginSpec defines the desired state of Plugin
+type PluginSpec struct {
+	Type PluginType `json:"type,omitempty"`
+	// Phases are the phases the plugin is executed at, such as PreInit, PostJoin.
+	Phases []string `json:"phases,omitempty"`
+	// Roles selects the hosts the plugin is executed on, all hosts if empty.
+	Roles []string `json:"roles,omitempty"`
+	// Data is the script of shell plugin.
+	Data string `json:"data,omitempty"`
+	// Path is the local path of the binary plugin.
+	Path string   `json:"path,omitempty"`
+	Args []string `json:"args,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Plugin is the Schema for the plugins API
+type Plugin struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PluginSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PluginList contains a list of Plugin
+type PluginList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Plugin `json:"items"`
+}
@@ -181,6 +181,15 @@ func (c *Cluster) GetRolesByIP(ip string) []string {
 	return nil
 }
 
+func (c *Cluster) GetHostByIP(ip string) *Host {
+	for i := range c.Spec.Hosts {
+		if slices.Contains(c.Spec.Hosts[i].IPS, ip) {
+			return &c.Spec.Hosts[i]
+		}
+	}
+	return nil
+}
+
 func (c *Cluster) GetDistribution() string {
 	root := c.GetRootfsImage()
 	if root != nil {
@@ -0,0 +1,104 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parallel provides the worker pool shared by all per-host fan-outs, so that
+// the number of hosts operated at the same time is bounded on large clusters.
+package parallel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/sync/errgroup"
+)
+
+const DefaultMaxParallel = 64
+
+var (
+	mu          sync.Mutex
+	maxParallel = DefaultMaxParallel
+	pool        chan struct{}
+)
+
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&maxParallel, "max-parallel", maxParallel,
+		"max number of hosts operated at the same time, zero or a negative number means unlimited")
+}
+
+// SetMaxParallel sets the size of the worker pool, it takes effect on the goroutines started later.
+func SetMaxParallel(n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	maxParallel = n
+	pool = nil
+}
+
+// acquire takes a slot of the worker pool, and returns the function to release it.
+func acquire() func() {
+	mu.Lock()
+	if maxParallel <= 0 {
+		mu.Unlock()
+		return func() {}
+	}
+	if pool == nil || cap(pool) != maxParallel {
+		pool = make(chan struct{}, maxParallel)
+	}
+	p := pool
+	mu.Unlock()
+
+	p <- struct{}{}
+	return func() { <-p }
+}
+
+// Group is the same as errgroup.Group, except that its goroutines run in the worker pool,
+// it must be created by WithContext.
+// Groups must not be nested, the goroutines of an inner group may wait forever for the slots
+// held by the outer ones.
+type Group struct {
+	eg *errgroup.Group
+}
+
+// WithContext returns a new Group and the derived context, which is canceled once a function
+// returns an error or Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	eg, ctx := errgroup.WithContext(ctx)
+	return &Group{eg: eg}, ctx
+}
+
+// Go calls fn in a new goroutine once a slot of the worker pool is available.
+func (g *Group) Go(fn func() error) {
+	g.eg.Go(func() error {
+		release := acquire()
+		defer release()
+		return fn()
+	})
+}
+
+// Wait blocks until all functions are returned, and returns the first error.
+func (g *Group) Wait() error {
+	return g.eg.Wait()
+}
+
+// ForEach calls fn on each host in the worker pool, and returns the first error.
+func ForEach(hosts []string, fn func(host string) error) error {
+	g, _ := WithContext(context.Background())
+	for i := range hosts {
+		host := hosts[i]
+		g.Go(func() error {
+			return fn(host)
+		})
+	}
+	return g.Wait()
+}
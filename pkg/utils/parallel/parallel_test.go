@@ -0,0 +1,77 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parallel
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEach(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxParallel int
+		wantMax     int32
+	}{
+		{name: "limited", maxParallel: 3, wantMax: 3},
+		{name: "unlimited", maxParallel: 0, wantMax: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMaxParallel(tt.maxParallel)
+			defer SetMaxParallel(DefaultMaxParallel)
+
+			var hosts []string
+			for i := 0; i < 10; i++ {
+				hosts = append(hosts, fmt.Sprintf("192.168.0.%d:22", i))
+			}
+			var running, max int32
+			err := ForEach(hosts, func(host string) error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					m := atomic.LoadInt32(&max)
+					if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if max != tt.wantMax {
+				t.Errorf("max running = %d, want %d", max, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	want := errors.New("failed")
+	err := ForEach([]string{"a", "b"}, func(host string) error {
+		if host == "b" {
+			return want
+		}
+		return nil
+	})
+	if !errors.Is(err, want) {
+		t.Errorf("ForEach() error = %v, want %v", err, want)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shim
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/labring/sealos/pkg/utils/hosts"
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	defaultHostsFile        = "/etc/hosts"
+	defaultFailoverInterval = 10 * time.Second
+	defaultProbeTimeout     = 3 * time.Second
+)
+
+// registryFailover keeps the registry domain resolved to a healthy replica, the domain is
+// resolved to another replica only if the current one is down, so that pulls stay on one replica.
+type registryFailover struct {
+	domain   string
+	port     string
+	scheme   string
+	replicas []string
+	hosts    *hosts.HostFile
+	probe    func(addr string) bool
+}
+
+func newRegistryFailover(address string, replicas []string) (*registryFailover, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry address %s: %v", address, err)
+	}
+	if u.Hostname() == "" || net.ParseIP(u.Hostname()) != nil {
+		return nil, fmt.Errorf("registry address %s has no domain to fail over", address)
+	}
+	f := &registryFailover{
+		domain:   u.Hostname(),
+		port:     u.Port(),
+		scheme:   u.Scheme,
+		replicas: replicas,
+		hosts:    &hosts.HostFile{Path: defaultHostsFile},
+	}
+	if f.port == "" {
+		f.port = "443"
+		if f.scheme == "http" {
+			f.port = "80"
+		}
+	}
+	f.probe = f.probeRegistry
+	return f, nil
+}
+
+// probeRegistry returns true if the registry API of addr is serving, authentication errors are
+// healthy responses.
+func (f *registryFailover) probeRegistry(addr string) bool {
+	client := &http.Client{
+		Timeout: defaultProbeTimeout,
+		Transport: &http.Transport{
+			// #nosec G402, replicas are probed by IP which is not in the certificate
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Get(fmt.Sprintf("%s://%s/v2/", f.scheme, addr))
+	if err != nil {
+		logger.Debug("registry replica %s is unavailable: %v", addr, err)
+		return false
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode < http.StatusInternalServerError
+}
+
+// check resolves the domain to the first healthy replica if the current one is down.
+func (f *registryFailover) check() {
+	current, _ := f.hosts.HasDomain(f.domain)
+	if current != "" && f.probe(net.JoinHostPort(current, f.port)) {
+		return
+	}
+	for _, ip := range f.replicas {
+		if ip == current || !f.probe(net.JoinHostPort(ip, f.port)) {
+			continue
+		}
+		logger.Warn("registry replica %s of %s is unavailable, fail over to %s", current, f.domain, ip)
+		f.hosts.DeleteDomain(f.domain)
+		f.hosts.AppendHost(f.domain, ip)
+		return
+	}
+	logger.Error("no registry replica of %s is available in %v", f.domain, f.replicas)
+}
+
+func (f *registryFailover) run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(defaultFailoverInterval)
+	defer ticker.Stop()
+	for {
+		f.check()
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shim
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/labring/sealos/pkg/utils/hosts"
+)
+
+func TestRegistryFailover(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		healthy []string
+		want    string
+	}{
+		{name: "current is healthy", current: "192.168.0.2", healthy: []string{"192.168.0.2", "192.168.0.3"}, want: "192.168.0.2"},
+		{name: "current is down", current: "192.168.0.2", healthy: []string{"192.168.0.3", "192.168.0.4"}, want: "192.168.0.3"},
+		{name: "not resolved", healthy: []string{"192.168.0.4"}, want: "192.168.0.4"},
+		{name: "all are down", current: "192.168.0.2", want: "192.168.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hosts")
+			content := "127.0.0.1 localhost\n"
+			if tt.current != "" {
+				content += tt.current + " sealos.hub\n"
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			f, err := newRegistryFailover("http://sealos.hub:5000", []string{"192.168.0.2", "192.168.0.3", "192.168.0.4"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.hosts = &hosts.HostFile{Path: path}
+			f.probe = func(addr string) bool {
+				for _, ip := range tt.healthy {
+					if addr == ip+":5000" {
+						return true
+					}
+				}
+				return false
+			}
+			f.check()
+			if got, _ := f.hosts.HasDomain("sealos.hub"); got != tt.want {
+				t.Errorf("sealos.hub is resolved to %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRegistryFailover(t *testing.T) {
+	if _, err := newRegistryFailover("http://192.168.0.2:5000", []string{"192.168.0.3"}); err == nil {
+		t.Error("newRegistryFailover() should fail for the address without domain")
+	}
+	f, err := newRegistryFailover("https://sealos.hub", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.port != "443" {
+		t.Errorf("port = %s, want 443", f.port)
+	}
+}
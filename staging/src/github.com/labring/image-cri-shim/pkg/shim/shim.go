@@ -50,12 +50,22 @@ type shim struct {
 	cfg        *types.Config // shim options
 	client     server.Client // shim CRI client
 	server     server.Server // shim CRI server
+	failover   *registryFailover
+	stopCh     chan struct{}
 }
 
 // NewShim creates a new shim instance.
 func NewShim(cfg *types.Config, auth *types.ShimAuthConfig) (Shim, error) {
 	r := &shim{
-		cfg: cfg,
+		cfg:    cfg,
+		stopCh: make(chan struct{}),
+	}
+	if len(cfg.RegistryReplicas) > 0 {
+		failover, err := newRegistryFailover(cfg.Address, cfg.RegistryReplicas)
+		if err != nil {
+			return nil, shimError("failed to create registry failover: %v", err)
+		}
+		r.failover = failover
 	}
 
 	cltopts := server.CRIClientOptions{
@@ -106,12 +116,17 @@ func (r *shim) Start() error {
 	if err := r.server.Start(); err != nil {
 		return shimError("failed to start shim: %v", err)
 	}
+	if r.failover != nil {
+		logger.Info("registry replicas: %v", r.cfg.RegistryReplicas)
+		go r.failover.run(r.stopCh)
+	}
 
 	return nil
 }
 
 // Stop stops the shim.
 func (r *shim) Stop() {
+	close(r.stopCh)
 	r.client.Close()
 	r.server.Stop()
 }
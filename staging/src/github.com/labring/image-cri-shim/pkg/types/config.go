@@ -54,6 +54,9 @@ type Config struct {
 	Timeout         metav1.Duration `json:"timeout"`
 	Auth            string          `json:"auth"`
 	Registries      []Registry      `json:"registries"`
+	// RegistryReplicas are the IPs of the registry replicas serving the registry of Address,
+	// the shim keeps the domain of Address resolved to a healthy replica in the hosts file.
+	RegistryReplicas []string `json:"registryReplicas,omitempty"`
 }
 
 type ShimAuthConfig struct {
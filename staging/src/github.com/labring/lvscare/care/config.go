@@ -0,0 +1,154 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// config is the file of --config, for example:
+//
+//	virtualServers:
+//	- address: 10.103.97.2:6443
+//	  realServers: [192.168.0.2:6443, 192.168.0.3:6443]
+//	- address: 10.103.97.3:5000
+//	  scheduler: wrr
+//	  realServers: [192.168.0.2:5000]
+//	  health:
+//	    type: tcp
+//	    failure-threshold: 3
+type config struct {
+	VirtualServers []*virtualServer `json:"virtualServers"`
+}
+
+// virtualServer is a virtual server with its real servers, scheduler and prober.
+type virtualServer struct {
+	Address     string   `json:"address"`
+	Scheduler   string   `json:"scheduler,omitempty"`
+	RealServers []string `json:"realServers"`
+	// Health is the health flags without the "health-" prefix, the prober of the flags is
+	// used if it is empty.
+	Health map[string]interface{} `json:"health,omitempty"`
+
+	prober Prober
+}
+
+// loadConfig loads the virtual servers of file, the scheduler and prober are used for the
+// virtual servers which do not set them.
+func loadConfig(file, scheduler string, prober Prober) ([]*virtualServer, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var cfg config
+	if err = yaml.UnmarshalStrict(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %v", file, err)
+	}
+	if len(cfg.VirtualServers) == 0 {
+		return nil, fmt.Errorf("no virtual server found in config %s", file)
+	}
+	seen := make(map[endpoint]bool)
+	for _, vs := range cfg.VirtualServers {
+		ep, err := parseEndpoint(vs.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid virtual server %q: %v", vs.Address, err)
+		}
+		if seen[ep] {
+			return nil, fmt.Errorf("duplicate virtual server %s", vs.Address)
+		}
+		seen[ep] = true
+		if err = vs.setDefaults(scheduler, prober); err != nil {
+			return nil, fmt.Errorf("invalid virtual server %s: %v", vs.Address, err)
+		}
+	}
+	return cfg.VirtualServers, nil
+}
+
+func (vs *virtualServer) setDefaults(scheduler string, prober Prober) error {
+	if vs.Scheduler == "" {
+		vs.Scheduler = scheduler
+	}
+	if !validScheduler(vs.Scheduler) {
+		return fmt.Errorf("invalid scheduler %s", vs.Scheduler)
+	}
+	if len(vs.RealServers) == 0 {
+		return errors.New("no real server")
+	}
+	for _, rs := range vs.RealServers {
+		if _, err := parseEndpoint(rs); err != nil {
+			return fmt.Errorf("invalid real server %q: %v", rs, err)
+		}
+	}
+	if len(vs.Health) == 0 {
+		vs.prober = prober
+		return nil
+	}
+	var err error
+	vs.prober, err = newHealthProber(vs.Health)
+	return err
+}
+
+// newHealthProber returns a prober with the health flags set to values.
+func newHealthProber(values map[string]interface{}) (Prober, error) {
+	p := newTypedProber()
+	fs := pflag.NewFlagSet(appName, pflag.ContinueOnError)
+	p.RegisterFlags(fs)
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fs.Set("health-"+name, flagValue(values[name])); err != nil {
+			return nil, fmt.Errorf("invalid health %s: %v", name, err)
+		}
+	}
+	if err := p.ValidateAndSetDefaults(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// flagValue formats the value decoded from config as the value of flag, lists are joined by
+// commas and maps are joined as key=value pairs.
+func flagValue(v interface{}) string {
+	switch vv := v.(type) {
+	case []interface{}:
+		parts := make([]string, 0, len(vv))
+		for i := range vv {
+			parts = append(parts, fmt.Sprint(vv[i]))
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		parts := make([]string, 0, len(vv))
+		for k := range vv {
+			parts = append(parts, k+"="+fmt.Sprint(vv[k]))
+		}
+		sort.Strings(parts)
+		return strings.Join(parts, ",")
+	case float64:
+		return strconv.FormatFloat(vv, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
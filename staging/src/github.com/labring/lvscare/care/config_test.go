@@ -0,0 +1,275 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	defaultProber := &tcpProber{}
+	tests := []struct {
+		name    string
+		config  string
+		want    []*virtualServer
+		wantErr bool
+	}{
+		{
+			name: "multiple virtual servers",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.2:6443, 192.168.0.3:6443]
+- address: 10.103.97.3:5000
+  scheduler: wrr
+  realServers:
+  - 192.168.0.2:5000
+`,
+			want: []*virtualServer{
+				{Address: "10.103.97.2:6443", Scheduler: "rr", RealServers: []string{"192.168.0.2:6443", "192.168.0.3:6443"}, prober: defaultProber},
+				{Address: "10.103.97.3:5000", Scheduler: "wrr", RealServers: []string{"192.168.0.2:5000"}, prober: defaultProber},
+			},
+		},
+		{
+			name:    "no virtual server",
+			config:  "virtualServers: []\n",
+			wantErr: true,
+		},
+		{
+			name: "duplicate virtual server",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.2:6443]
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.3:6443]
+`,
+			wantErr: true,
+		},
+		{
+			name: "missing real servers",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid real server",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.2]
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid virtual server",
+			config: `virtualServers:
+- address: 10.103.97.2
+  realServers: [192.168.0.2:6443]
+`,
+			wantErr: true,
+		},
+		{
+			name: "invalid scheduler",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  scheduler: fifo
+  realServers: [192.168.0.2:6443]
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown field",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  realServer: [192.168.0.2:6443]
+`,
+			wantErr: true,
+		},
+		{
+			name: "unknown health key",
+			config: `virtualServers:
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.2:6443]
+  health:
+    retries: 3
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := loadConfig(file, "rr", defaultProber)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadConfig() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_health(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	config := `virtualServers:
+- address: 10.103.97.2:6443
+  realServers: [192.168.0.2:6443]
+  health:
+    type: tcp
+    failure-threshold: 3
+    timeout: 2s
+`
+	if err := os.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadConfig(file, "rr", &tcpProber{})
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	p, ok := got[0].prober.(*typedProber)
+	if !ok {
+		t.Fatalf("prober = %T, want *typedProber", got[0].prober)
+	}
+	if p.Type != tcpProbe || p.FailureThreshold != 3 || p.tcp.timeout != 2*time.Second {
+		t.Errorf("prober = {type: %s, failure-threshold: %d, timeout: %s}, want {type: tcp, failure-threshold: 3, timeout: 2s}",
+			p.Type, p.FailureThreshold, p.tcp.timeout)
+	}
+}
+
+func TestNewHealthProber(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  map[string]interface{}
+		check   func(*typedProber) bool
+		wantErr bool
+	}{
+		{
+			name:   "number",
+			values: map[string]interface{}{"success-threshold": float64(2)},
+			check:  func(p *typedProber) bool { return p.SuccessThreshold == 2 },
+		},
+		{
+			name:   "list",
+			values: map[string]interface{}{"status": []interface{}{float64(401), float64(403)}},
+			check:  func(p *typedProber) bool { return reflect.DeepEqual(p.http.ValidStatusCodes, []int{401, 403}) },
+		},
+		{
+			name:   "map",
+			values: map[string]interface{}{"req-headers": map[string]interface{}{"Host": "sealos.io", "X-Probe": "lvscare"}},
+			check: func(p *typedProber) bool {
+				return reflect.DeepEqual(p.http.Headers, map[string]string{"Host": "sealos.io", "X-Probe": "lvscare"})
+			},
+		},
+		{
+			name:   "bool",
+			values: map[string]interface{}{"insecure-skip-verify": false},
+			check:  func(p *typedProber) bool { return !p.InsecureSkipVerify },
+		},
+		{
+			name:    "unknown key",
+			values:  map[string]interface{}{"retries": float64(3)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid value",
+			values:  map[string]interface{}{"failure-threshold": "three"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid threshold",
+			values:  map[string]interface{}{"failure-threshold": float64(0)},
+			wantErr: true,
+		},
+		{
+			name:    "invalid type",
+			values:  map[string]interface{}{"type": "udp"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newHealthProber(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newHealthProber() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if p := got.(*typedProber); !tt.check(p) {
+				t.Errorf("newHealthProber() = %+v, does not match %v", p, tt.values)
+			}
+		})
+	}
+}
+
+func TestFlagValue(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "string",
+			v:    "tcp",
+			want: "tcp",
+		},
+		{
+			name: "integer",
+			v:    float64(3),
+			want: "3",
+		},
+		{
+			name: "float",
+			v:    float64(1.5),
+			want: "1.5",
+		},
+		{
+			name: "large number",
+			v:    float64(10000000),
+			want: "10000000",
+		},
+		{
+			name: "bool",
+			v:    true,
+			want: "true",
+		},
+		{
+			name: "list",
+			v:    []interface{}{float64(401), "403"},
+			want: "401,403",
+		},
+		{
+			name: "map",
+			v:    map[string]interface{}{"b": "2", "a": float64(1)},
+			want: "a=1,b=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := flagValue(tt.v); got != tt.want {
+				t.Errorf("flagValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
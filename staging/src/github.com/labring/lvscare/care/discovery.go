@@ -0,0 +1,241 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+const (
+	staticSource    = "static"
+	endpointsSource = "endpoints"
+	fileSource      = "file"
+	urlSource       = "url"
+)
+
+// realServerSource discovers the real servers of the virtual server. The update func is
+// called with nil if the source is unavailable, then the static real servers are used.
+type realServerSource interface {
+	Run(ctx context.Context, update func([]string))
+}
+
+func newRealServerSource(o *options) (realServerSource, error) {
+	interval := time.Duration(o.Interval)
+	switch o.RealServerSource {
+	case "", staticSource:
+		return nil, nil
+	case endpointsSource:
+		namespace, name, err := cache.SplitMetaNamespaceKey(o.Endpoints)
+		if err != nil {
+			return nil, err
+		}
+		if namespace == "" {
+			namespace = metav1.NamespaceDefault
+		}
+		cfg, err := clientcmd.BuildConfigFromFlags("", o.Kubeconfig)
+		if err != nil {
+			return nil, err
+		}
+		client, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &endpointsWatcher{client: client, namespace: namespace, name: name, port: o.EndpointsPort, retryPeriod: interval}, nil
+	case fileSource:
+		return &pollingSource{name: o.RealServerFile, interval: interval, fetch: func(context.Context) ([]byte, error) {
+			return os.ReadFile(o.RealServerFile)
+		}}, nil
+	case urlSource:
+		client := &http.Client{Timeout: 10 * time.Second}
+		return &pollingSource{name: o.RealServerURL, interval: interval, fetch: func(ctx context.Context) ([]byte, error) {
+			return httpGet(ctx, client, o.RealServerURL)
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported real server source %s", o.RealServerSource)
+	}
+}
+
+// endpointsWatcher watches the ready addresses of an Endpoints.
+type endpointsWatcher struct {
+	client      kubernetes.Interface
+	namespace   string
+	name        string
+	port        string
+	retryPeriod time.Duration
+}
+
+func (w *endpointsWatcher) Run(ctx context.Context, update func([]string)) {
+	for {
+		err := w.watch(ctx, update)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logger.Warn("failed to watch endpoints %s/%s, falling back to static real servers: %v", w.namespace, w.name, err)
+			update(nil)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.retryPeriod):
+			}
+		}
+	}
+}
+
+// watch returns nil if the watch is closed by the API server, it should be watched again.
+func (w *endpointsWatcher) watch(ctx context.Context, update func([]string)) error {
+	ep, err := w.client.CoreV1().Endpoints(w.namespace).Get(ctx, w.name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	update(endpointsRealServers(ep, w.port))
+	wi, err := w.client.CoreV1().Endpoints(w.namespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fields.OneTermEqualSelector("metadata.name", w.name).String(),
+		ResourceVersion: ep.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	defer wi.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-wi.ResultChan():
+			if !ok {
+				return nil
+			}
+			switch ev.Type {
+			case watch.Added, watch.Modified:
+				if ep, ok := ev.Object.(*corev1.Endpoints); ok {
+					update(endpointsRealServers(ep, w.port))
+				}
+			case watch.Deleted:
+				return fmt.Errorf("endpoints %s/%s is deleted", w.namespace, w.name)
+			case watch.Error:
+				return apierrors.FromObject(ev.Object)
+			}
+		}
+	}
+}
+
+// endpointsRealServers returns the ready addresses with the port of name, or the first port
+// if name is empty.
+func endpointsRealServers(ep *corev1.Endpoints, portName string) []string {
+	var ret []string
+	for _, subset := range ep.Subsets {
+		var port int32
+		for _, p := range subset.Ports {
+			if portName == "" || p.Name == portName {
+				port = p.Port
+				break
+			}
+		}
+		if port == 0 {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			ret = append(ret, net.JoinHostPort(addr.IP, strconv.Itoa(int(port))))
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// pollingSource reads the real servers every interval.
+type pollingSource struct {
+	name     string
+	interval time.Duration
+	fetch    func(context.Context) ([]byte, error)
+}
+
+func (s *pollingSource) Run(ctx context.Context, update func([]string)) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		data, err := s.fetch(ctx)
+		var rs []string
+		if err == nil {
+			rs, err = parseRealServers(string(data))
+		}
+		if err != nil {
+			logger.Warn("failed to read real servers from %s, falling back to static real servers: %v", s.name, err)
+		}
+		update(rs)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// parseRealServers parses the real servers separated by lines or commas, lines starting
+// with # are comments.
+func parseRealServers(s string) ([]string, error) {
+	var ret []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, rs := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if _, err := parseEndpoint(rs); err != nil {
+				return nil, fmt.Errorf("invalid real server %s: %v", rs, err)
+			}
+			ret = append(ret, rs)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("no real server found")
+	}
+	return ret, nil
+}
+
+func httpGet(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
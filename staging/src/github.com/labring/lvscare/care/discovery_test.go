@@ -0,0 +1,161 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestEndpointsRealServers(t *testing.T) {
+	addresses := func(ips ...string) []corev1.EndpointAddress {
+		ret := make([]corev1.EndpointAddress, 0, len(ips))
+		for _, ip := range ips {
+			ret = append(ret, corev1.EndpointAddress{IP: ip})
+		}
+		return ret
+	}
+	tests := []struct {
+		name     string
+		subsets  []corev1.EndpointSubset
+		portName string
+		want     []string
+	}{
+		{
+			name: "first port",
+			subsets: []corev1.EndpointSubset{{
+				Addresses: addresses("192.168.0.3", "192.168.0.2"),
+				Ports:     []corev1.EndpointPort{{Name: "https", Port: 6443}, {Name: "metrics", Port: 9090}},
+			}},
+			want: []string{"192.168.0.2:6443", "192.168.0.3:6443"},
+		},
+		{
+			name: "named port",
+			subsets: []corev1.EndpointSubset{{
+				Addresses: addresses("192.168.0.2"),
+				Ports:     []corev1.EndpointPort{{Name: "https", Port: 6443}, {Name: "metrics", Port: 9090}},
+			}},
+			portName: "metrics",
+			want:     []string{"192.168.0.2:9090"},
+		},
+		{
+			name: "subsets without port",
+			subsets: []corev1.EndpointSubset{
+				{
+					Addresses: addresses("192.168.0.2"),
+					Ports:     []corev1.EndpointPort{{Name: "https", Port: 6443}},
+				},
+				{
+					Addresses: addresses("192.168.0.3"),
+					Ports:     []corev1.EndpointPort{{Name: "metrics", Port: 9090}},
+				},
+				{
+					Addresses: addresses("192.168.0.4"),
+				},
+			},
+			portName: "https",
+			want:     []string{"192.168.0.2:6443"},
+		},
+		{
+			name: "ipv6 and multiple subsets",
+			subsets: []corev1.EndpointSubset{
+				{
+					Addresses: addresses("fd00::2"),
+					Ports:     []corev1.EndpointPort{{Port: 6443}},
+				},
+				{
+					Addresses: addresses("192.168.0.2"),
+					Ports:     []corev1.EndpointPort{{Port: 6443}},
+				},
+			},
+			want: []string{"192.168.0.2:6443", "[fd00::2]:6443"},
+		},
+		{
+			name: "not ready",
+			subsets: []corev1.EndpointSubset{{
+				NotReadyAddresses: addresses("192.168.0.2"),
+				Ports:             []corev1.EndpointPort{{Port: 6443}},
+			}},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := endpointsRealServers(&corev1.Endpoints{Subsets: tt.subsets}, tt.portName)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("endpointsRealServers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRealServers(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "lines",
+			s:    "192.168.0.2:6443\n192.168.0.3:6443\n",
+			want: []string{"192.168.0.2:6443", "192.168.0.3:6443"},
+		},
+		{
+			name: "commas and spaces",
+			s:    "192.168.0.2:6443, 192.168.0.3:6443\t192.168.0.4:6443",
+			want: []string{"192.168.0.2:6443", "192.168.0.3:6443", "192.168.0.4:6443"},
+		},
+		{
+			name: "comments and blank lines",
+			s:    "# masters\n\n  192.168.0.2:6443\n  # 192.168.0.3:6443\n",
+			want: []string{"192.168.0.2:6443"},
+		},
+		{
+			name: "ipv6",
+			s:    "[fd00::2]:6443",
+			want: []string{"[fd00::2]:6443"},
+		},
+		{
+			name:    "empty",
+			s:       "# nothing\n\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing port",
+			s:       "192.168.0.2:6443\n192.168.0.3",
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			s:       "192.168.0.2:65536",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRealServers(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRealServers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseRealServers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -32,6 +32,21 @@ type stopper interface {
 	Stop()
 }
 
+type configurer interface {
+	ConfigureVirtualServer(vs, scheduler string, prober Prober) error
+}
+
+// resyncer re-asserts its rules, it returns the rules which are missing or changed and
+// restored.
+type resyncer interface {
+	Resync() ([]ruleDrift, error)
+}
+
+type ruleDrift struct {
+	kind string
+	rule string
+}
+
 type Ruler interface {
 	Setup() error
 	Cleanup() error
@@ -17,8 +17,10 @@ package care
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	utilsysctl "k8s.io/component-helpers/node/util/sysctl"
 	proxyipvs "k8s.io/kubernetes/pkg/proxy/ipvs"
@@ -130,13 +132,13 @@ func (impl *iptablesImpl) Setup() error {
 		logger.Error("Failed to ensure ipset: %v", err)
 		return err
 	}
-	err := impl.ensureIptablesChains()
+	_, err := impl.ensureIptablesChains()
 	if err == nil {
 		go impl.iptables.Monitor(utiliptables.Chain("VIRTUAL-CANARY"),
 			[]utiliptables.Table{utiliptables.TableFilter, utiliptables.TableNAT},
 			func() {
 				logger.Info("looks like canary rules has been flushed, rebuild it")
-				if err := impl.ensureIptablesChains(); err != nil {
+				if _, err := impl.ensureIptablesChains(); err != nil {
 					logger.Error("Failed to ensure iptables chains: %v", err)
 				}
 			},
@@ -145,6 +147,51 @@ func (impl *iptablesImpl) Setup() error {
 	return err
 }
 
+// Resync re-asserts the dummy device and its addresses, the ipset entries and the iptables
+// rules, in case they are flushed by others.
+func (impl *iptablesImpl) Resync() ([]ruleDrift, error) {
+	var drifts []ruleDrift
+	exist, err := impl.nl.EnsureDummyDevice(impl.ifaceName)
+	if err != nil {
+		return drifts, fmt.Errorf("failed to ensure dummy device: %v", err)
+	}
+	if !exist {
+		drifts = append(drifts, ruleDrift{"device", impl.ifaceName})
+	}
+	for _, addr := range impl.bindAddresses {
+		exist, err := impl.nl.EnsureAddressBind(addr, impl.ifaceName)
+		if err != nil {
+			return drifts, fmt.Errorf("failed to bind address %s: %v", addr, err)
+		}
+		if !exist {
+			drifts = append(drifts, ruleDrift{"address", addr})
+		}
+	}
+	for _, set := range ipsetInfo {
+		applied, err := impl.ipset.ListEntries(set.name)
+		if err != nil {
+			logger.Debug("failed to list ipset %s: %v", set.name, err)
+			drifts = append(drifts, ruleDrift{"ipset", set.name})
+			continue
+		}
+		have := sets.NewString(applied...)
+		for _, entry := range impl.ipsetEntries(set.name) {
+			if !have.Has(entry) {
+				drifts = append(drifts, ruleDrift{"ipset", set.name + " " + entry})
+			}
+		}
+	}
+	if err := impl.ensureIpset(); err != nil {
+		return drifts, fmt.Errorf("failed to ensure ipset: %v", err)
+	}
+	missing, err := impl.ensureIptablesChains()
+	drifts = append(drifts, missing...)
+	if err != nil {
+		return drifts, fmt.Errorf("failed to ensure iptables chains: %v", err)
+	}
+	return drifts, nil
+}
+
 func (impl *iptablesImpl) Cleanup() error {
 	if encounteredError := impl.cleanupLeftovers(); encounteredError {
 		return errors.New("encountered an error while tearing down rules")
@@ -196,13 +243,17 @@ func ensureDummyDeviceAndAddresses(nl proxyipvs.NetLinkHandle, ifaceName string,
 	return nil
 }
 
+func (impl *iptablesImpl) ipsetEntries(name string) []string {
+	entries := make([]string, 0)
+	if name == virtualIPSet {
+		entries = append(entries, impl.virtualEntries...)
+	}
+	return entries
+}
+
 func (impl *iptablesImpl) ensureIpset() error {
 	for _, set := range ipsetInfo {
-		entries := make([]string, 0)
-		if set.name == virtualIPSet {
-			entries = append(entries, impl.virtualEntries...)
-		}
-		if err := ensureIPSetWithEntries(impl.ipset, set.name, set.comment, set.setType, entries...); err != nil {
+		if err := ensureIPSetWithEntries(impl.ipset, set.name, set.comment, set.setType, impl.ipsetEntries(set.name)...); err != nil {
 			return err
 		}
 	}
@@ -234,19 +285,28 @@ func (impl *iptablesImpl) extraChainRules() []iptablesRule {
 	return rules
 }
 
-func (impl *iptablesImpl) ensureIptablesChains() error {
+// ensureIptablesChains ensures the chains and rules, it returns the ones which did not exist.
+func (impl *iptablesImpl) ensureIptablesChains() ([]ruleDrift, error) {
+	var missing []ruleDrift
 	// service chain
 	for _, ch := range iptablesChains {
-		if _, err := impl.iptables.EnsureChain(ch.table, ch.chain); err != nil {
+		exist, err := impl.iptables.EnsureChain(ch.table, ch.chain)
+		if err != nil {
 			logger.Error("Failed to ensure chain, table: %s, chain: %s, %v", ch.table, ch.chain, err)
-			return err
+			return missing, err
+		}
+		if !exist {
+			missing = append(missing, ruleDrift{"iptables", fmt.Sprintf("chain %s/%s", ch.table, ch.chain)})
 		}
 	}
 	// jump chain
 	for _, jc := range iptablesJumpChain {
 		args := []string{"-m", "comment", "--comment", jc.comment, "-j", string(jc.to)}
-		if _, err := impl.iptables.EnsureRule(utiliptables.Append, jc.table, jc.from, args...); err != nil {
+		exist, err := impl.iptables.EnsureRule(utiliptables.Append, jc.table, jc.from, args...)
+		if err != nil {
 			logger.Error("Failed to ensure chain jumps, table: %s, src: %s, dst: %s, %v", jc.table, jc.from, jc.to, err)
+		} else if !exist {
+			missing = append(missing, ruleDrift{"iptables", fmt.Sprintf("jump %s/%s -> %s", jc.table, jc.from, jc.to)})
 		}
 	}
 
@@ -282,11 +342,15 @@ func (impl *iptablesImpl) ensureIptablesChains() error {
 	}
 	rules = append(rules, iptablesRule{utiliptables.Append, utiliptables.TableNAT, virtualPostroutingChain, masqArgs})
 	for i := range rules {
-		if _, err := impl.iptables.EnsureRule(rules[i].position, rules[i].table, rules[i].chain, rules[i].args...); err != nil {
-			return err
+		exist, err := impl.iptables.EnsureRule(rules[i].position, rules[i].table, rules[i].chain, rules[i].args...)
+		if err != nil {
+			return missing, err
+		}
+		if !exist {
+			missing = append(missing, ruleDrift{"iptables", fmt.Sprintf("rule %s/%s %s", rules[i].table, rules[i].chain, strings.Join(rules[i].args, " "))})
 		}
 	}
-	return nil
+	return missing, nil
 }
 
 func ensureIPSetWithEntries(handle utilipset.Interface, name, comment string, setType utilipset.Type, entries ...string) error {
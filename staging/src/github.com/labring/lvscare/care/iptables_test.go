@@ -0,0 +1,107 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"reflect"
+	"testing"
+
+	nltest "k8s.io/kubernetes/pkg/proxy/ipvs/testing"
+	ipsettest "k8s.io/kubernetes/pkg/util/ipset/testing"
+	utiliptables "k8s.io/kubernetes/pkg/util/iptables"
+	iptest "k8s.io/kubernetes/pkg/util/iptables/testing"
+)
+
+func TestIptablesImpl_Resync(t *testing.T) {
+	const entry = "10.103.97.2,tcp:6443"
+	type fakes struct {
+		ipset    *ipsettest.FakeIPSet
+		iptables *iptest.FakeIPTables
+		nl       *nltest.FakeNetlinkHandle
+	}
+	tests := []struct {
+		name string
+		// drift changes the rules set up by resync
+		drift func(t *testing.T, f fakes)
+		want  []ruleDrift
+	}{
+		{
+			name:  "unchanged",
+			drift: func(*testing.T, fakes) {},
+		},
+		{
+			name: "address unbound",
+			drift: func(t *testing.T, f fakes) {
+				if err := f.nl.UnbindAddress("10.103.97.2", "lvscare"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []ruleDrift{{"address", "10.103.97.2"}},
+		},
+		{
+			name: "ipset entry deleted",
+			drift: func(t *testing.T, f fakes) {
+				if err := f.ipset.DelEntry(entry, virtualIPSet); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []ruleDrift{{"ipset", virtualIPSet + " " + entry}},
+		},
+		{
+			name: "iptables chain flushed",
+			drift: func(t *testing.T, f fakes) {
+				if err := f.iptables.FlushChain(utiliptables.TableNAT, virtualPostroutingChain); err != nil {
+					t.Fatal(err)
+				}
+			},
+			want: []ruleDrift{
+				{"iptables", "rule nat/VIRTUAL-POSTROUTING -m mark ! --mark 0x00000001 -j RETURN"},
+				{"iptables", "rule nat/VIRTUAL-POSTROUTING -m comment --comment virtual service traffic requiring SNAT -j MASQUERADE"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newIptablesImpl(appName, 0, "10.103.97.2:6443")
+			if err != nil {
+				t.Fatal(err)
+			}
+			impl := r.(*iptablesImpl)
+			f := fakes{ipset: ipsettest.NewFake("7.0"), iptables: iptest.NewFake(), nl: nltest.NewFakeNetlinkHandle()}
+			impl.ipset, impl.iptables, impl.nl = f.ipset, f.iptables, f.nl
+			// the first resync sets up all the rules
+			if _, err := impl.Resync(); err != nil {
+				t.Fatalf("Resync() error = %v", err)
+			}
+			tt.drift(t, f)
+			got, err := impl.Resync()
+			if err != nil {
+				t.Fatalf("Resync() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resync() = %v, want %v", got, tt.want)
+			}
+			if entries, _ := f.ipset.ListEntries(virtualIPSet); !reflect.DeepEqual(entries, []string{entry}) {
+				t.Errorf("entries of ipset %s = %v, want %v", virtualIPSet, entries, []string{entry})
+			}
+			if addresses, _ := f.nl.ListBindAddress(appName); !reflect.DeepEqual(addresses, []string{"10.103.97.2"}) {
+				t.Errorf("addresses of %s = %v, want %v", appName, addresses, []string{"10.103.97.2"})
+			}
+			if got, _ := impl.Resync(); len(got) != 0 {
+				t.Errorf("Resync() after restored = %v, want none", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/labring/sealos/pkg/utils/logger"
+)
+
+// states of real servers
+const (
+	stateUnknown  = "unknown"
+	stateHealthy  = "healthy"
+	stateDraining = "draining"
+	stateRemoved  = "removed"
+)
+
+type RealServerStatus struct {
+	Address        string    `json:"address"`
+	Weight         int       `json:"weight"`
+	State          string    `json:"state"`
+	LastProbe      time.Time `json:"lastProbe"`
+	LastError      string    `json:"lastError,omitempty"`
+	LastTransition time.Time `json:"lastTransition"`
+}
+
+type VirtualServerStatus struct {
+	Address     string             `json:"address"`
+	Scheduler   string             `json:"scheduler"`
+	RealServers []RealServerStatus `json:"realServers"`
+}
+
+// Recorder records the probes and states of real servers as prometheus metrics, and keeps
+// the table of virtual servers for the status endpoint. A nil Recorder records nothing.
+type Recorder struct {
+	registry        *prometheus.Registry
+	probeDuration   *prometheus.HistogramVec
+	probeFailures   *prometheus.CounterVec
+	weight          *prometheus.GaugeVec
+	up              *prometheus.GaugeVec
+	transitions     *prometheus.CounterVec
+	drifts          *prometheus.CounterVec
+	mu              sync.Mutex
+	schedulers      map[string]string
+	virtualServices map[string]map[string]*RealServerStatus
+}
+
+func NewRecorder() *Recorder {
+	labels := []string{"virtual_server", "real_server"}
+	r := &Recorder{
+		registry: prometheus.NewRegistry(),
+		probeDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: appName,
+			Name:      "probe_duration_seconds",
+			Help:      "Duration of health probes of real servers.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		probeFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: appName,
+			Name:      "probe_failures_total",
+			Help:      "Number of failed health probes of real servers.",
+		}, labels),
+		weight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: appName,
+			Name:      "real_server_weight",
+			Help:      "IPVS weight of real servers, 0 if it is draining or removed.",
+		}, labels),
+		up: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: appName,
+			Name:      "real_server_up",
+			Help:      "Whether the last health probe of real servers succeeded.",
+		}, labels),
+		transitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: appName,
+			Name:      "real_server_transitions_total",
+			Help:      "Number of state transitions of real servers, by the state transitioned to.",
+		}, append(labels, "state")),
+		drifts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: appName,
+			Name:      "rule_drifts_total",
+			Help:      "Number of rules found missing or changed and restored, by the kind of rule.",
+		}, []string{"kind"}),
+		schedulers:      make(map[string]string),
+		virtualServices: make(map[string]map[string]*RealServerStatus),
+	}
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.probeDuration, r.probeFailures, r.weight, r.up, r.transitions, r.drifts,
+	)
+	return r
+}
+
+func (r *Recorder) addVirtualServer(vs, scheduler string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.schedulers[vs] = scheduler
+	if _, ok := r.virtualServices[vs]; !ok {
+		r.virtualServices[vs] = make(map[string]*RealServerStatus)
+	}
+}
+
+func (r *Recorder) deleteVirtualServer(vs string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for rs := range r.virtualServices[vs] {
+		r.deleteMetrics(vs, rs)
+	}
+	delete(r.virtualServices, vs)
+	delete(r.schedulers, vs)
+}
+
+func (r *Recorder) addRealServer(vs, rs string, weight int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rsMap, ok := r.virtualServices[vs]
+	if !ok {
+		rsMap = make(map[string]*RealServerStatus)
+		r.virtualServices[vs] = rsMap
+	}
+	if _, ok := rsMap[rs]; !ok {
+		rsMap[rs] = &RealServerStatus{Address: rs, Weight: weight, State: stateUnknown, LastTransition: time.Now()}
+		r.weight.WithLabelValues(vs, rs).Set(float64(weight))
+	}
+}
+
+func (r *Recorder) deleteRealServer(vs, rs string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.virtualServices[vs], rs)
+	r.deleteMetrics(vs, rs)
+}
+
+func (r *Recorder) deleteMetrics(vs, rs string) {
+	r.probeDuration.DeleteLabelValues(vs, rs)
+	r.probeFailures.DeleteLabelValues(vs, rs)
+	r.weight.DeleteLabelValues(vs, rs)
+	r.up.DeleteLabelValues(vs, rs)
+	for _, state := range []string{stateHealthy, stateDraining, stateRemoved} {
+		r.transitions.DeleteLabelValues(vs, rs, state)
+	}
+}
+
+// observeProbe records a probe of real server and the state and weight after it.
+func (r *Recorder) observeProbe(vs, rs string, duration time.Duration, probeErr error, state string, weight int) {
+	if r == nil {
+		return
+	}
+	r.probeDuration.WithLabelValues(vs, rs).Observe(duration.Seconds())
+	up := 1.0
+	if probeErr != nil {
+		up = 0
+		r.probeFailures.WithLabelValues(vs, rs).Inc()
+	}
+	r.up.WithLabelValues(vs, rs).Set(up)
+	r.weight.WithLabelValues(vs, rs).Set(float64(weight))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rsMap, ok := r.virtualServices[vs]
+	if !ok {
+		return
+	}
+	status, ok := rsMap[rs]
+	if !ok {
+		// deleted while probing
+		return
+	}
+	now := time.Now()
+	status.LastProbe, status.Weight, status.LastError = now, weight, ""
+	if probeErr != nil {
+		status.LastError = probeErr.Error()
+	}
+	if status.State != state {
+		logger.Info("real server %s of %s is %s", rs, vs, state)
+		status.State, status.LastTransition = state, now
+		r.transitions.WithLabelValues(vs, rs, state).Inc()
+	}
+}
+
+func (r *Recorder) observeDrift(kind string) {
+	if r == nil {
+		return
+	}
+	r.drifts.WithLabelValues(kind).Inc()
+}
+
+// Status returns the virtual servers and their real servers sorted by address.
+func (r *Recorder) Status() []VirtualServerStatus {
+	ret := make([]VirtualServerStatus, 0)
+	if r == nil {
+		return ret
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for vs, rsMap := range r.virtualServices {
+		vsStatus := VirtualServerStatus{Address: vs, Scheduler: r.schedulers[vs], RealServers: make([]RealServerStatus, 0, len(rsMap))}
+		for _, status := range rsMap {
+			vsStatus.RealServers = append(vsStatus.RealServers, *status)
+		}
+		sort.Slice(vsStatus.RealServers, func(i, j int) bool {
+			return vsStatus.RealServers[i].Address < vsStatus.RealServers[j].Address
+		})
+		ret = append(ret, vsStatus)
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Address < ret[j].Address })
+	return ret
+}
+
+// Handler serves the metrics on /metrics and the virtual servers as JSON on /status.
+func (r *Recorder) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(r.Status()); err != nil {
+			logger.Warn("failed to write status: %v", err)
+		}
+	})
+	return mux
+}
+
+// serve serves the handler on addr until ctx is done.
+func (r *Recorder) serve(ctx context.Context, addr string) error {
+	srv := &http.Server{Addr: addr, Handler: r.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("serving metrics and status on %s", addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func get(t *testing.T, url string) []byte {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: unexpected status code %d", url, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestRecorder_Handler(t *testing.T) {
+	const (
+		vs = "10.103.97.2:6443"
+		rs = "192.168.0.2:6443"
+	)
+	r := NewRecorder()
+	r.addVirtualServer(vs, "rr")
+	r.addRealServer(vs, rs, 1)
+	r.addRealServer(vs, "192.168.0.3:6443", 1)
+	errProbe := errors.New("connection refused")
+	for _, probe := range []struct {
+		err    error
+		state  string
+		weight int
+	}{
+		{nil, stateHealthy, 1},
+		{errProbe, stateDraining, 0},
+		// no transition if the state is unchanged
+		{errProbe, stateDraining, 0},
+		{errProbe, stateRemoved, 0},
+		{nil, stateHealthy, 1},
+	} {
+		r.observeProbe(vs, rs, time.Millisecond, probe.err, probe.state, probe.weight)
+	}
+	r.observeDrift("route")
+
+	srv := httptest.NewServer(r.Handler())
+	defer srv.Close()
+
+	var status []VirtualServerStatus
+	if err := json.Unmarshal(get(t, srv.URL+"/status"), &status); err != nil {
+		t.Fatalf("failed to decode status: %v", err)
+	}
+	if len(status) != 1 || status[0].Address != vs || status[0].Scheduler != "rr" || len(status[0].RealServers) != 2 {
+		t.Fatalf("status = %+v, want virtual server %s with 2 real servers", status, vs)
+	}
+	got := status[0].RealServers[0]
+	if got.Address != rs || got.State != stateHealthy || got.Weight != 1 || got.LastError != "" || got.LastProbe.IsZero() {
+		t.Errorf("status of %s = %+v, want healthy with weight 1", rs, got)
+	}
+	if other := status[0].RealServers[1]; other.State != stateUnknown || !other.LastProbe.IsZero() {
+		t.Errorf("status of %s = %+v, want unknown without probe", other.Address, other)
+	}
+
+	metrics := string(get(t, srv.URL+"/metrics"))
+	for _, want := range []string{
+		`lvscare_real_server_transitions_total{real_server="192.168.0.2:6443",state="healthy",virtual_server="10.103.97.2:6443"} 2`,
+		`lvscare_real_server_transitions_total{real_server="192.168.0.2:6443",state="draining",virtual_server="10.103.97.2:6443"} 1`,
+		`lvscare_real_server_transitions_total{real_server="192.168.0.2:6443",state="removed",virtual_server="10.103.97.2:6443"} 1`,
+		`lvscare_probe_failures_total{real_server="192.168.0.2:6443",virtual_server="10.103.97.2:6443"} 3`,
+		`lvscare_real_server_weight{real_server="192.168.0.2:6443",virtual_server="10.103.97.2:6443"} 1`,
+		`lvscare_real_server_up{real_server="192.168.0.2:6443",virtual_server="10.103.97.2:6443"} 1`,
+		`lvscare_rule_drifts_total{kind="route"} 1`,
+	} {
+		if !strings.Contains(metrics, want+"\n") {
+			t.Errorf("metrics do not contain %s", want)
+		}
+	}
+
+	r.deleteRealServer(vs, rs)
+	if metrics = string(get(t, srv.URL+"/metrics")); strings.Contains(metrics, `real_server="192.168.0.2:6443"`) {
+		t.Errorf("metrics of deleted real server %s are not deleted", rs)
+	}
+}
@@ -48,6 +48,15 @@ type options struct {
 	Interval      durationOrSecondValue
 	TargetIP      net.IP
 	MasqueradeBit int
+
+	RealServerSource string
+	Kubeconfig       string
+	Endpoints        string
+	EndpointsPort    string
+	RealServerFile   string
+	RealServerURL    string
+	MetricsAddress   string
+	ConfigFile       string
 }
 
 func (o *options) RegisterFlags(fs *pflag.FlagSet) {
@@ -62,6 +71,15 @@ func (o *options) RegisterFlags(fs *pflag.FlagSet) {
 	fs.Var(&o.Interval, "interval", "health check interval")
 	fs.IPVar(&o.TargetIP, "ip", nil, "target ip as route gateway, use with route mode")
 	fs.IntVar(&o.MasqueradeBit, "masqueradebit", 0, "IPTables masquerade bit")
+	fs.StringVar(&o.RealServerSource, "rs-source", staticSource,
+		fmt.Sprintf("source of real servers: %s/%s/%s/%s, the --rs list is used if the source is unavailable", staticSource, endpointsSource, fileSource, urlSource))
+	fs.StringVar(&o.Kubeconfig, "kubeconfig", "", "kubeconfig to watch endpoints, use in-cluster config if empty")
+	fs.StringVar(&o.Endpoints, "rs-endpoints", "default/kubernetes", "namespace/name of endpoints to watch, use with endpoints source")
+	fs.StringVar(&o.EndpointsPort, "rs-endpoints-port", "", "port name of endpoints, defaults to the first port")
+	fs.StringVar(&o.RealServerFile, "rs-file", "", "file of real servers separated by lines or commas, use with file source")
+	fs.StringVar(&o.RealServerURL, "rs-url", "", "url returning real servers separated by lines or commas, use with url source")
+	fs.StringVar(&o.MetricsAddress, "metrics-address", "", "address to serve prometheus metrics on /metrics and virtual servers on /status, for example 127.0.0.1:9277, disabled if empty")
+	fs.StringVar(&o.ConfigFile, "config", "", "config file of virtual servers and their real servers, instead of --vs and --rs")
 
 	// set klog flag
 	if v := os.Getenv("ENABLE_KLOG_FLAGS"); len(v) > 0 {
@@ -72,17 +90,35 @@ func (o *options) RegisterFlags(fs *pflag.FlagSet) {
 	}
 }
 
-func (o *options) RequiredFlags() []string {
-	return []string{"vs"}
-}
-
 func (o *options) ValidateAndSetDefaults() error {
-	if len(o.RealServer) == 0 && !o.CleanAndExit {
-		return errors.New(`required flag(s) "rs" not set`)
+	if o.ConfigFile != "" {
+		if o.VirtualServer != "" || len(o.RealServer) > 0 {
+			return errors.New(`flag "config" can not be used with "vs" or "rs"`)
+		}
+		if o.RealServerSource != "" && o.RealServerSource != staticSource {
+			return errors.New(`flag "config" can not be used with "rs-source"`)
+		}
+	} else if o.VirtualServer == "" {
+		return errors.New(`required flag(s) "vs" not set`)
 	}
-	switch o.scheduler {
-	case "rr", "lc", "dh", "sh", "wrr", "wlc":
+	switch o.RealServerSource {
+	case "", staticSource:
+		if len(o.RealServer) == 0 && o.ConfigFile == "" && !o.CleanAndExit {
+			return errors.New(`required flag(s) "rs" not set`)
+		}
+	case endpointsSource:
+	case fileSource:
+		if o.RealServerFile == "" {
+			return errors.New(`required flag(s) "rs-file" not set`)
+		}
+	case urlSource:
+		if o.RealServerURL == "" {
+			return errors.New(`required flag(s) "rs-url" not set`)
+		}
 	default:
+		return fmt.Errorf(`invalid flag "rs-source=%s"`, o.RealServerSource)
+	}
+	if !validScheduler(o.scheduler) {
 		return fmt.Errorf(`invalid flag "scheduler=%s"`, o.scheduler)
 	}
 	if o.TargetIP == nil && o.Mode == routeMode {
@@ -97,6 +133,15 @@ func (o *options) ValidateAndSetDefaults() error {
 	return nil
 }
 
+func validScheduler(scheduler string) bool {
+	switch scheduler {
+	case "rr", "lc", "dh", "sh", "wrr", "wlc":
+		return true
+	default:
+		return false
+	}
+}
+
 type durationOrSecondValue time.Duration
 
 func (d *durationOrSecondValue) Set(s string) error {
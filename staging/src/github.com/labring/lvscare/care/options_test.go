@@ -0,0 +1,86 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestOptions_ValidateAndSetDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name: "vs and rs",
+			args: []string{"--vs=10.103.97.2:6443", "--rs=192.168.0.2:6443"},
+		},
+		{
+			name: "config",
+			args: []string{"--config=lvscare.yaml"},
+		},
+		{
+			name: "config with static source",
+			args: []string{"--config=lvscare.yaml", "--rs-source=static"},
+		},
+		{
+			name:    "config with vs",
+			args:    []string{"--config=lvscare.yaml", "--vs=10.103.97.2:6443"},
+			wantErr: true,
+		},
+		{
+			name:    "config with rs",
+			args:    []string{"--config=lvscare.yaml", "--rs=192.168.0.2:6443"},
+			wantErr: true,
+		},
+		{
+			name:    "config with rs-source",
+			args:    []string{"--config=lvscare.yaml", "--rs-source=endpoints"},
+			wantErr: true,
+		},
+		{
+			name:    "neither vs nor config",
+			args:    []string{"--rs=192.168.0.2:6443"},
+			wantErr: true,
+		},
+		{
+			name:    "vs without rs",
+			args:    []string{"--vs=10.103.97.2:6443"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid scheduler",
+			args:    []string{"--vs=10.103.97.2:6443", "--rs=192.168.0.2:6443", "--scheduler=fifo"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &options{}
+			fs := pflag.NewFlagSet(appName, pflag.ContinueOnError)
+			o.RegisterFlags(fs)
+			// link mode does not look up the target ip in hosts file
+			if err := fs.Parse(append([]string{"--mode=link"}, tt.args...)); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+			if err := o.ValidateAndSetDefaults(); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAndSetDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -18,11 +18,14 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
@@ -34,16 +37,135 @@ type Prober interface {
 	Probe(string, string) error
 }
 
-type httpProber struct {
-	HealthPath         string
-	HealthScheme       string
-	Method             string
-	Headers            map[string]string
-	Body               string
-	ValidStatusCodes   []int
+// thresholder is a prober which changes the health of a real server only after a number of
+// consecutive successes or failures.
+type thresholder interface {
+	Thresholds() (success, failure int)
+}
+
+const (
+	httpProbe = "http"
+	tcpProbe  = "tcp"
+	tlsProbe  = "tls"
+	grpcProbe = "grpc"
+	execProbe = "exec"
+)
+
+// typedProber probes with the prober of --health-type.
+type typedProber struct {
+	Type               string
+	SuccessThreshold   int
+	FailureThreshold   int
 	InsecureSkipVerify bool
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
 	timeout            time.Duration
 
+	http     *httpProber
+	tcp      *tcpProber
+	tls      *tlsProber
+	grpc     *grpcProber
+	exec     *execProber
+	selected Prober
+}
+
+func newTypedProber() *typedProber {
+	return &typedProber{http: &httpProber{}, tcp: &tcpProber{}, tls: &tlsProber{}, grpc: &grpcProber{}, exec: &execProber{}}
+}
+
+func (p *typedProber) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&p.Type, "health-type", httpProbe, fmt.Sprintf("type of prober: %s/%s/%s/%s/%s", httpProbe, tcpProbe, tlsProbe, grpcProbe, execProbe))
+	fs.IntVar(&p.SuccessThreshold, "health-success-threshold", 1, "consecutive successes for an unhealthy real server to be considered healthy")
+	fs.IntVar(&p.FailureThreshold, "health-failure-threshold", 1, "consecutive failures for a healthy real server to be considered unhealthy")
+	fs.BoolVar(&p.InsecureSkipVerify, "health-insecure-skip-verify", true, "skip verify insecure request")
+	fs.StringVar(&p.CAFile, "health-ca-file", "", "CA file to verify real servers, use with https, tls and grpc probers")
+	fs.StringVar(&p.CertFile, "health-cert-file", "", "client certificate file, use with https, tls and grpc probers")
+	fs.StringVar(&p.KeyFile, "health-key-file", "", "client key file, use with https, tls and grpc probers")
+	fs.StringVar(&p.ServerName, "health-server-name", "", "server name to verify real servers, use with https, tls and grpc probers")
+	fs.DurationVar(&p.timeout, "health-timeout", 10*time.Second, "probe timeout")
+	for _, iter := range []interface{}{p.http, p.tcp, p.tls, p.grpc, p.exec} {
+		if registerer, ok := iter.(flagRegisterer); ok {
+			registerer.RegisterFlags(fs)
+		}
+	}
+}
+
+func (p *typedProber) ValidateAndSetDefaults() error {
+	if p.SuccessThreshold < 1 || p.FailureThreshold < 1 {
+		return errors.New("health thresholds must be at least 1")
+	}
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return err
+	}
+	switch p.Type {
+	case httpProbe:
+		p.http.tlsConfig, p.http.timeout = tlsConfig, p.timeout
+		p.selected = p.http
+	case tcpProbe:
+		p.tcp.timeout = p.timeout
+		p.selected = p.tcp
+	case tlsProbe:
+		p.tls.tlsConfig, p.tls.timeout = tlsConfig, p.timeout
+		p.selected = p.tls
+	case grpcProbe:
+		p.grpc.tlsConfig, p.grpc.timeout = tlsConfig, p.timeout
+		p.selected = p.grpc
+	case execProbe:
+		p.exec.timeout = p.timeout
+		p.selected = p.exec
+	default:
+		return fmt.Errorf(`invalid flag "health-type=%s"`, p.Type)
+	}
+	if validator, ok := p.selected.(flagValidator); ok {
+		return validator.ValidateAndSetDefaults()
+	}
+	return nil
+}
+
+func (p *typedProber) tlsConfig() (*tls.Config, error) {
+	// nosemgrep
+	cfg := &tls.Config{InsecureSkipVerify: p.InsecureSkipVerify, ServerName: p.ServerName}
+	if p.CAFile != "" {
+		ca, err := os.ReadFile(p.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", p.CAFile)
+		}
+	}
+	if p.CertFile != "" || p.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.CertFile, p.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func (p *typedProber) Probe(host, port string) error {
+	return p.selected.Probe(host, port)
+}
+
+func (p *typedProber) Thresholds() (int, int) {
+	return p.SuccessThreshold, p.FailureThreshold
+}
+
+type httpProber struct {
+	HealthPath       string
+	HealthScheme     string
+	Method           string
+	Headers          map[string]string
+	Body             string
+	ValidStatusCodes []int
+	tlsConfig        *tls.Config
+	timeout          time.Duration
+
 	client      *http.Client
 	validStatus sets.Int
 }
@@ -55,8 +177,6 @@ func (p *httpProber) RegisterFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&p.Body, "health-req-body", "", "body to send for health checker")
 	fs.StringToStringVar(&p.Headers, "health-req-headers", map[string]string{}, "http request headers")
 	fs.IntSliceVar(&p.ValidStatusCodes, "health-status", []int{}, "extra valid status codes greater than 400")
-	fs.BoolVar(&p.InsecureSkipVerify, "health-insecure-skip-verify", true, "skip verify insecure request")
-	fs.DurationVar(&p.timeout, "health-timeout", 10*time.Second, "http probe timeout")
 }
 
 func (p *httpProber) ValidateAndSetDefaults() error {
@@ -78,8 +198,7 @@ func (p *httpProber) Probe(host, port string) error {
 	if p.client == nil {
 		p.client = &http.Client{
 			Transport: &http.Transport{
-				// nosemgrep
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: p.InsecureSkipVerify},
+				TLSClientConfig: p.tlsConfig,
 			},
 		}
 	}
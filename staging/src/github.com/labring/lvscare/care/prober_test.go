@@ -0,0 +1,194 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func TestTypedProber_ValidateAndSetDefaults(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		wantErr        bool
+		wantThresholds [2]int
+		wantSelected   string
+	}{
+		{
+			name:           "defaults",
+			wantThresholds: [2]int{1, 1},
+			wantSelected:   "*care.httpProber",
+		},
+		{
+			name:           "tcp with thresholds",
+			args:           []string{"--health-type=tcp", "--health-success-threshold=2", "--health-failure-threshold=3"},
+			wantThresholds: [2]int{2, 3},
+			wantSelected:   "*care.tcpProber",
+		},
+		{
+			name:    "zero success threshold",
+			args:    []string{"--health-success-threshold=0"},
+			wantErr: true,
+		},
+		{
+			name:    "negative failure threshold",
+			args:    []string{"--health-failure-threshold=-1"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			args:    []string{"--health-type=udp"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newTypedProber()
+			fs := pflag.NewFlagSet(appName, pflag.ContinueOnError)
+			p.RegisterFlags(fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatalf("failed to parse flags: %v", err)
+			}
+			err := p.ValidateAndSetDefaults()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateAndSetDefaults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if success, failure := p.Thresholds(); success != tt.wantThresholds[0] || failure != tt.wantThresholds[1] {
+				t.Errorf("Thresholds() = %d, %d, want %v", success, failure, tt.wantThresholds)
+			}
+			if got := fmt.Sprintf("%T", p.selected); got != tt.wantSelected {
+				t.Errorf("selected prober = %s, want %s", got, tt.wantSelected)
+			}
+		})
+	}
+}
+
+func TestTCPProber_Probe(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, closedPort, _ := net.SplitHostPort(closed.Addr().String())
+	_ = closed.Close()
+
+	tests := []struct {
+		name    string
+		port    string
+		wantErr bool
+	}{
+		{
+			name: "listening",
+			port: port,
+		},
+		{
+			name:    "closed",
+			port:    closedPort,
+			wantErr: true,
+		},
+	}
+	p := &tcpProber{timeout: time.Second}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Probe(host, tt.port); (err != nil) != tt.wantErr {
+				t.Errorf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSProber_Probe(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	host, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	plain := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer plain.Close()
+	_, plainPort, _ := net.SplitHostPort(plain.Listener.Addr().String())
+
+	tests := []struct {
+		name      string
+		port      string
+		tlsConfig *tls.Config
+		wantErr   bool
+	}{
+		{
+			name: "insecure",
+			port: port,
+			// nosemgrep
+			tlsConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		{
+			name:      "verified",
+			port:      port,
+			tlsConfig: &tls.Config{RootCAs: roots},
+		},
+		{
+			name:      "unknown authority",
+			port:      port,
+			tlsConfig: &tls.Config{RootCAs: x509.NewCertPool()},
+			wantErr:   true,
+		},
+		{
+			name:      "server name mismatch",
+			port:      port,
+			tlsConfig: &tls.Config{RootCAs: roots, ServerName: "sealos.io"},
+			wantErr:   true,
+		},
+		{
+			name: "not tls",
+			port: plainPort,
+			// nosemgrep
+			tlsConfig: &tls.Config{InsecureSkipVerify: true},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &tlsProber{tlsConfig: tt.tlsConfig, timeout: time.Second}
+			if err := p.Probe(host, tt.port); (err != nil) != tt.wantErr {
+				t.Errorf("Probe() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
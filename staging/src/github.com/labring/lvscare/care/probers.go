@@ -0,0 +1,129 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// tcpProber checks that a TCP connection can be established.
+type tcpProber struct {
+	timeout time.Duration
+}
+
+func (p *tcpProber) Probe(host, port string) error {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), p.timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// tlsProber checks that the TLS handshake succeeds, the certificate of real server is
+// verified unless --health-insecure-skip-verify.
+type tlsProber struct {
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+func (p *tlsProber) Probe(host, port string) error {
+	dialer := &net.Dialer{Timeout: p.timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(host, port), p.tlsConfig)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// grpcProber checks the status of the gRPC health checking protocol is SERVING.
+type grpcProber struct {
+	Service string
+	TLS     bool
+
+	tlsConfig *tls.Config
+	timeout   time.Duration
+}
+
+func (p *grpcProber) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&p.Service, "health-grpc-service", "", "service name of gRPC health check, empty for the server")
+	fs.BoolVar(&p.TLS, "health-grpc-tls", false, "use TLS for gRPC health check")
+}
+
+func (p *grpcProber) Probe(host, port string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	creds := insecure.NewCredentials()
+	if p.TLS {
+		creds = credentials.NewTLS(p.tlsConfig)
+	}
+	conn, err := grpc.DialContext(ctx, net.JoinHostPort(host, port), grpc.WithTransportCredentials(creds), grpc.WithBlock())
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: p.Service})
+	if err != nil {
+		return err
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// execProber runs a command with the address of real server in env LVSCARE_RS_HOST and
+// LVSCARE_RS_PORT, the real server is healthy if it exits with 0.
+type execProber struct {
+	Command string
+
+	timeout time.Duration
+}
+
+func (p *execProber) RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&p.Command, "health-exec", "", "shell command of exec prober")
+}
+
+func (p *execProber) ValidateAndSetDefaults() error {
+	if p.Command == "" {
+		return errors.New(`required flag(s) "health-exec" not set`)
+	}
+	return nil
+}
+
+func (p *execProber) Probe(host, port string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", p.Command)
+	cmd.Env = append(os.Environ(), "LVSCARE_RS_HOST="+host, "LVSCARE_RS_PORT="+port)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
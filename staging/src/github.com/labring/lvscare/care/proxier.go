@@ -45,30 +45,78 @@ func (ep *endpoint) String() string {
 	return net.JoinHostPort(ep.IP, strconv.Itoa(int(ep.Port)))
 }
 
-func NewProxier(scheduler string, interval time.Duration, prober Prober, syncFn func() error) Proxier {
+func NewProxier(scheduler string, interval time.Duration, prober Prober, syncFn func() error, recorder *Recorder) Proxier {
 	return &realProxier{
-		scheduler:  scheduler,
-		ipvsHandle: ipvs.New(),
-		syncFn:     syncFn,
-		serviceMap: make(map[endpoint]map[string]endpoint),
-		prober:     prober,
-		ticker:     time.NewTicker(interval),
-		tryCh:      make(chan struct{}, 1),
-		errCh:      make(chan error, 1),
+		defaultConfig: newVirtualServerConfig(scheduler, prober),
+		configs:       make(map[endpoint]*virtualServerConfig),
+		ipvsHandle:    ipvs.New(),
+		syncFn:        syncFn,
+		serviceMap:    make(map[endpoint]map[string]endpoint),
+		recorder:      recorder,
+		counters:      make(map[string]*probeCounter),
+		ticker:        time.NewTicker(interval),
+		tryCh:         make(chan struct{}, 1),
+		errCh:         make(chan error, 1),
 	}
 }
 
 type realProxier struct {
-	scheduler  string
-	ipvsHandle ipvs.Interface
-	syncFn     func() error
+	// defaultConfig is used for the virtual servers not configured
+	defaultConfig *virtualServerConfig
+	configs       map[endpoint]*virtualServerConfig
+	ipvsHandle    ipvs.Interface
+	syncFn        func() error
 
 	// for prober
 	serviceMap map[endpoint]map[string]endpoint
-	prober     Prober
+	recorder   *Recorder
 	ticker     *time.Ticker
 	tryCh      chan struct{}
 	errCh      chan error
+
+	countersMu sync.Mutex
+	counters   map[string]*probeCounter
+}
+
+// virtualServerConfig is the scheduler and prober of a virtual server.
+type virtualServerConfig struct {
+	scheduler        string
+	prober           Prober
+	successThreshold int
+	failureThreshold int
+}
+
+func newVirtualServerConfig(scheduler string, prober Prober) *virtualServerConfig {
+	c := &virtualServerConfig{scheduler: scheduler, prober: prober, successThreshold: 1, failureThreshold: 1}
+	if t, ok := prober.(thresholder); ok {
+		c.successThreshold, c.failureThreshold = t.Thresholds()
+	}
+	return c
+}
+
+// probeCounter is the consecutive results of probes of a real server.
+type probeCounter struct {
+	successes int
+	failures  int
+	unhealthy bool
+}
+
+// ConfigureVirtualServer sets the scheduler and prober of virtual server, it must be called
+// before the loop runs.
+func (p *realProxier) ConfigureVirtualServer(vs, scheduler string, prober Prober) error {
+	ep, err := parseEndpoint(vs)
+	if err != nil {
+		return err
+	}
+	p.configs[ep] = newVirtualServerConfig(scheduler, prober)
+	return nil
+}
+
+func (p *realProxier) config(ep endpoint) *virtualServerConfig {
+	if c, ok := p.configs[ep]; ok {
+		return c
+	}
+	return p.defaultConfig
 }
 
 func (p *realProxier) ensureVirtualServer(vs *ipvs.VirtualServer) (*ipvs.VirtualServer, error) {
@@ -101,6 +149,7 @@ func (p *realProxier) EnsureVirtualServer(vs string) error {
 	if _, ok := p.serviceMap[ep]; !ok {
 		p.serviceMap[ep] = make(map[string]endpoint)
 	}
+	p.recorder.addVirtualServer(ep.String(), p.config(ep).scheduler)
 	return nil
 }
 
@@ -117,7 +166,11 @@ func (p *realProxier) DeleteVirtualServer(vs string) error {
 			return err
 		}
 	}
+	for rs := range p.serviceMap[ep] {
+		p.resetCounter(ep, rs)
+	}
 	delete(p.serviceMap, ep)
+	p.recorder.deleteVirtualServer(ep.String())
 	return nil
 }
 
@@ -162,6 +215,7 @@ func (p *realProxier) EnsureRealServer(vs, rs string) error {
 	defer func() {
 		if err == nil {
 			p.serviceMap[vsEp][rsEp.String()] = rsEp
+			p.recorder.addRealServer(vsEp.String(), rsEp.String(), int(p.buildRealServer(&rsEp).Weight))
 		}
 	}()
 	if rSrv != nil {
@@ -188,13 +242,16 @@ func (p *realProxier) DeleteRealServer(vs, rs string) error {
 	if err != nil {
 		return err
 	}
-	if rSrv == nil {
-		return nil
-	}
-	if err = p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
-		logger.Error("Failed to delete real server: %v", err)
-		return err
+	if rSrv != nil {
+		if err = p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
+			logger.Error("Failed to delete real server: %v", err)
+			return err
+		}
 	}
+	// stop checking it, or it is added back once it is healthy
+	delete(p.serviceMap[vsEp], rsEp.String())
+	p.resetCounter(vsEp, rsEp.String())
+	p.recorder.deleteRealServer(vsEp.String(), rsEp.String())
 	return nil
 }
 
@@ -240,9 +297,11 @@ func (p *realProxier) Stop() {
 	close(p.errCh)
 }
 
-func (p *realProxier) checkRealServer(wg *sync.WaitGroup, vSrv *ipvs.VirtualServer, rs endpoint) {
+func (p *realProxier) checkRealServer(wg *sync.WaitGroup, cfg *virtualServerConfig, vs endpoint, vSrv *ipvs.VirtualServer, rs endpoint) {
 	defer wg.Done()
-	probeErr := p.prober.Probe(rs.IP, strconv.Itoa(int(rs.Port)))
+	start := time.Now()
+	probeErr := cfg.prober.Probe(rs.IP, strconv.Itoa(int(rs.Port)))
+	duration := time.Since(start)
 	rSrv, err := p.getRealServer(vSrv, p.buildRealServer(&rs))
 	if err != nil {
 		logger.Warn("Failed to get real server: %v", err)
@@ -250,36 +309,82 @@ func (p *realProxier) checkRealServer(wg *sync.WaitGroup, vSrv *ipvs.VirtualServ
 	}
 	if probeErr != nil {
 		logger.Debug("probe error: %v", probeErr)
-		if rSrv != nil {
-			if rSrv.Weight != 0 {
-				logger.Debug("Trying to update wight to 0 for graceful termination")
-				rSrv.Weight = 0
-				if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
-					logger.Warn("Failed to update real server wight: %v", err)
-				}
-				return
-			}
-			logger.Debug("Trying to delete real server")
-			if err = p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
-				logger.Warn("Failed to delete real server: %v", err)
+	}
+	state, weight := p.updateRealServer(vSrv, rSrv, rs, p.healthy(cfg, vs, rs.String(), probeErr))
+	p.recorder.observeProbe(vs.String(), rs.String(), duration, probeErr, state, weight)
+}
+
+// healthy counts the probe result of real server, it returns whether the real server is
+// healthy considering the thresholds.
+func (p *realProxier) healthy(cfg *virtualServerConfig, vs endpoint, rs string, probeErr error) bool {
+	p.countersMu.Lock()
+	defer p.countersMu.Unlock()
+	key := vs.String() + "/" + rs
+	c, ok := p.counters[key]
+	if !ok {
+		c = &probeCounter{}
+		p.counters[key] = c
+	}
+	if probeErr == nil {
+		c.successes, c.failures = c.successes+1, 0
+		if c.unhealthy && c.successes >= cfg.successThreshold {
+			c.unhealthy = false
+		}
+	} else {
+		c.successes, c.failures = 0, c.failures+1
+		if !c.unhealthy && c.failures >= cfg.failureThreshold {
+			c.unhealthy = true
+		}
+	}
+	return !c.unhealthy
+}
+
+func (p *realProxier) resetCounter(vs endpoint, rs string) {
+	p.countersMu.Lock()
+	defer p.countersMu.Unlock()
+	delete(p.counters, vs.String()+"/"+rs)
+}
+
+// updateRealServer updates the real server of the health, and returns the state and weight
+// of it after the update.
+func (p *realProxier) updateRealServer(vSrv *ipvs.VirtualServer, rSrv *ipvs.RealServer, rs endpoint, healthy bool) (string, int) {
+	if !healthy {
+		if rSrv == nil {
+			return stateRemoved, 0
+		}
+		if rSrv.Weight != 0 {
+			logger.Debug("Trying to update wight to 0 for graceful termination")
+			rSrv.Weight = 0
+			if err := p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
+				logger.Warn("Failed to update real server wight: %v", err)
+				return stateHealthy, 1
 			}
+			return stateDraining, 0
 		}
-		return
+		logger.Debug("Trying to delete real server")
+		if err := p.ipvsHandle.DeleteRealServer(vSrv, rSrv); err != nil {
+			logger.Warn("Failed to delete real server: %v", err)
+			return stateDraining, 0
+		}
+		return stateRemoved, 0
 	}
 	if rSrv != nil {
 		if rSrv.Weight == 0 {
 			logger.Debug("Trying to update wight to 1 to receive traffic")
 			rSrv.Weight = 1
-			if err = p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
+			if err := p.ipvsHandle.UpdateRealServer(vSrv, rSrv); err != nil {
 				logger.Warn("Failed to update real server wight: %v", err)
+				return stateDraining, 0
 			}
 		}
-		return
+		return stateHealthy, rSrv.Weight
 	}
 	logger.Debug("Trying to add real server back")
-	if err = p.ipvsHandle.AddRealServer(vSrv, p.buildRealServer(&rs)); err != nil {
+	if err := p.ipvsHandle.AddRealServer(vSrv, p.buildRealServer(&rs)); err != nil {
 		logger.Warn("Failed to add real server back: %v", err)
+		return stateRemoved, 0
 	}
+	return stateHealthy, 1
 }
 
 func (p *realProxier) runCheck() {
@@ -290,9 +395,10 @@ func (p *realProxier) runCheck() {
 			logger.Error("Failed to get or create IPVS service: %v", err)
 			continue
 		}
+		cfg := p.config(vs)
 		for _, rs := range rsMap {
 			wg.Add(1)
-			go p.checkRealServer(wg, vSrv, rs)
+			go p.checkRealServer(wg, cfg, vs, vSrv, rs)
 		}
 	}
 	wg.Wait()
@@ -303,7 +409,7 @@ func (p *realProxier) buildVirtualServer(ep *endpoint) *ipvs.VirtualServer {
 		Address:   net.ParseIP(ep.IP),
 		Protocol:  "TCP",
 		Port:      ep.Port,
-		Scheduler: p.scheduler,
+		Scheduler: p.config(*ep).scheduler,
 		Flags:     0,
 		Timeout:   0,
 	}
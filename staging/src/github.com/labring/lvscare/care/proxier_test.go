@@ -0,0 +1,92 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type fakeThresholdProber struct {
+	success, failure int
+}
+
+func (p *fakeThresholdProber) Probe(string, string) error { return nil }
+
+func (p *fakeThresholdProber) Thresholds() (int, int) { return p.success, p.failure }
+
+func TestRealProxier_healthy(t *testing.T) {
+	errProbe := errors.New("connection refused")
+	// each probe is a success if true, or a failure if false
+	tests := []struct {
+		name   string
+		prober Prober
+		probes []bool
+		want   []bool
+	}{
+		{
+			name:   "without thresholds",
+			prober: &tcpProber{},
+			probes: []bool{true, false, true, false, false},
+			want:   []bool{true, false, true, false, false},
+		},
+		{
+			name:   "failure threshold",
+			prober: &fakeThresholdProber{success: 1, failure: 3},
+			probes: []bool{false, false, true, false, false, false, false},
+			want:   []bool{true, true, true, true, true, false, false},
+		},
+		{
+			name:   "success threshold",
+			prober: &fakeThresholdProber{success: 2, failure: 1},
+			probes: []bool{false, true, false, true, true, true},
+			want:   []bool{false, false, false, false, true, true},
+		},
+		{
+			name:   "both thresholds",
+			prober: &fakeThresholdProber{success: 2, failure: 2},
+			probes: []bool{false, true, false, false, true, false, true, true},
+			want:   []bool{true, true, true, false, false, false, false, true},
+		},
+	}
+	vs := endpoint{IP: "10.103.97.2", Port: 6443}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProxier("rr", time.Second, tt.prober, nil, nil).(*realProxier)
+			cfg := p.config(vs)
+			got := make([]bool, 0, len(tt.probes))
+			for _, ok := range tt.probes {
+				var err error
+				if !ok {
+					err = errProbe
+				}
+				got = append(got, p.healthy(cfg, vs, "192.168.0.2:6443", err))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("healthy() = %v, want %v", got, tt.want)
+			}
+			// the counters are per real server
+			if !p.healthy(cfg, vs, "192.168.0.3:6443", nil) {
+				t.Errorf("healthy() of another real server = false, want true")
+			}
+			p.resetCounter(vs, "192.168.0.2:6443")
+			if !p.healthy(cfg, vs, "192.168.0.2:6443", nil) {
+				t.Errorf("healthy() after reset = false, want true")
+			}
+		})
+	}
+}
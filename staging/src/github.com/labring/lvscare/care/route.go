@@ -15,27 +15,67 @@
 package care
 
 import (
+	"errors"
+	"strings"
+
 	"github.com/labring/lvscare/pkg/route"
 
 	"github.com/labring/sealos/pkg/utils/logger"
 )
 
 type routeImpl struct {
-	*route.Route
+	routes []*route.Route
 }
 
-func newRouteImpl(target, gw string) (Ruler, error) {
-	return &routeImpl{
-		route.New(target, gw),
-	}, nil
+func newRouteImpl(gw string, targets ...string) (Ruler, error) {
+	impl := &routeImpl{}
+	for i := range targets {
+		impl.routes = append(impl.routes, route.New(targets[i], gw))
+	}
+	return impl, nil
 }
 
 func (impl *routeImpl) Setup() error {
-	logger.Info("Trying to add route")
-	return impl.SetRoute()
+	for _, r := range impl.routes {
+		logger.Info("Trying to add route to %s", r.Host)
+		if err := r.SetRoute(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (impl *routeImpl) Cleanup() error {
-	logger.Info("Trying to delete route")
-	return impl.DelRoute()
+	var errs []string
+	for _, r := range impl.routes {
+		logger.Info("Trying to delete route to %s", r.Host)
+		if err := r.DelRoute(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Resync adds back the routes which are deleted by others.
+func (impl *routeImpl) Resync() ([]ruleDrift, error) {
+	var drifts []ruleDrift
+	var errs []string
+	for _, r := range impl.routes {
+		ok, err := r.HasRoute()
+		if err == nil && !ok {
+			if err = r.SetRoute(); err == nil {
+				drifts = append(drifts, ruleDrift{"route", r.Host})
+			}
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return drifts, errors.New(strings.Join(errs, ", "))
+	}
+	return drifts, nil
 }
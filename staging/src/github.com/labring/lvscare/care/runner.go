@@ -18,9 +18,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 
 	"github.com/labring/sealos/pkg/utils/logger"
@@ -28,16 +31,29 @@ import (
 
 var LVS = &runner{
 	options: &options{},
-	prober:  &httpProber{},
+	prober:  newTypedProber(),
 }
 
 type runner struct {
 	*options
 	prober Prober
 
-	proxier      Proxier
-	ruler        Ruler
-	cleanupFuncs []func() error
+	// virtualServers are the virtual servers of --config, or the one of --vs
+	virtualServers []*virtualServer
+	proxier        Proxier
+	ruler          Ruler
+	recorder       *Recorder
+	cleanupFuncs   []func() error
+
+	source realServerSource
+	// realServers are the real servers applied to the virtual server
+	realServers sets.String
+	mu          sync.Mutex
+	// discovered is the latest real servers of source, the static ones are used if it is empty
+	discovered        []string
+	discoveredChanged bool
+	// rulesReady is set once the rules are set up, then they are resynced periodically
+	rulesReady atomic.Bool
 }
 
 func (r *runner) Run() (err error) {
@@ -59,12 +75,18 @@ func (r *runner) Run() (err error) {
 	}
 
 	cleanVirtualServer := func() error {
-		logger.Info("delete IPVS service %s", r.options.VirtualServer)
-		err := r.proxier.DeleteVirtualServer(r.options.VirtualServer)
-		if err != nil {
-			logger.Warn("failed to delete IPVS service: %v", err)
+		var errs []string
+		for _, vs := range r.virtualServers {
+			logger.Info("delete IPVS service %s", vs.Address)
+			if err := r.proxier.DeleteVirtualServer(vs.Address); err != nil {
+				logger.Warn("failed to delete IPVS service: %v", err)
+				errs = append(errs, err.Error())
+			}
 		}
-		return err
+		if len(errs) > 0 {
+			return errors.New(strings.Join(errs, ", "))
+		}
+		return nil
 	}
 	if r.options.CleanAndExit {
 		r.cleanupFuncs = append(r.cleanupFuncs, cleanVirtualServer)
@@ -75,9 +97,21 @@ func (r *runner) Run() (err error) {
 	}
 	errCh := make(chan error, 1)
 	ctx := signals.SetupSignalHandler()
+	r.realServers = sets.NewString(r.options.RealServer...)
 	go func() {
 		errCh <- r.proxier.RunLoop(ctx)
 	}()
+	if r.recorder != nil {
+		go func() {
+			if err := r.recorder.serve(ctx, r.options.MetricsAddress); err != nil {
+				logger.Error("failed to serve metrics: %v", err)
+				select {
+				case errCh <- err:
+				default:
+				}
+			}
+		}()
+	}
 	// fire at once, no need to check error here
 	_ = r.proxier.TryRun()
 	// ensure ipvs
@@ -89,28 +123,106 @@ func (r *runner) Run() (err error) {
 			return err
 		}
 	}
+	r.rulesReady.Store(true)
+	if r.source != nil {
+		go r.source.Run(ctx, r.setDiscovered)
+	}
 	return <-errCh
 }
 
 // run once at startup
 func (r *runner) ensureIPVSRules() error {
-	if err := r.proxier.EnsureVirtualServer(r.options.VirtualServer); err != nil {
-		return err
-	}
-	for i := range r.options.RealServer {
-		if err := r.proxier.EnsureRealServer(r.options.VirtualServer, r.options.RealServer[i]); err != nil {
+	for _, vs := range r.virtualServers {
+		if err := r.proxier.EnsureVirtualServer(vs.Address); err != nil {
 			return err
 		}
+		for i := range vs.RealServers {
+			if err := r.proxier.EnsureRealServer(vs.Address, vs.RealServers[i]); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
 func (r *runner) periodicRun() error {
-	// ensure ipset/iptables ruler?
-	// or only run once at startup?
+	r.syncRealServers()
+	r.resyncRules()
 	return nil
 }
 
+// resyncRules re-asserts the rules of ruler, in case they are flushed by kube-proxy or
+// admins, the drifted ones are reported. Failures are retried in the next loop. The IPVS
+// virtual servers are re-asserted by the checks of proxier.
+func (r *runner) resyncRules() {
+	if !r.rulesReady.Load() {
+		return
+	}
+	s, ok := r.ruler.(resyncer)
+	if !ok {
+		return
+	}
+	drifts, err := s.Resync()
+	for _, d := range drifts {
+		logger.Warn("%s rule %s drifted, restored it", d.kind, d.rule)
+		r.recorder.observeDrift(d.kind)
+	}
+	if err != nil {
+		logger.Warn("failed to resync rules: %v", err)
+	}
+}
+
+// setDiscovered is called by the source of real servers, the real servers are applied in
+// the loop of proxier.
+func (r *runner) setDiscovered(rs []string) {
+	r.mu.Lock()
+	r.discovered, r.discoveredChanged = rs, true
+	r.mu.Unlock()
+	// the next check applies it if a check is already queued
+	_ = r.proxier.TryRun()
+}
+
+// syncRealServers adds the discovered real servers not applied and deletes the applied ones
+// not discovered, it is retried in the next loop if it fails.
+func (r *runner) syncRealServers() {
+	r.mu.Lock()
+	if !r.discoveredChanged {
+		r.mu.Unlock()
+		return
+	}
+	desired := r.discovered
+	r.discoveredChanged = false
+	r.mu.Unlock()
+	if len(desired) == 0 {
+		desired = r.options.RealServer
+	}
+	want := sets.NewString(desired...)
+	failed := false
+	for _, rs := range want.Difference(r.realServers).List() {
+		logger.Info("add real server %s", rs)
+		if err := r.proxier.EnsureRealServer(r.options.VirtualServer, rs); err != nil {
+			logger.Warn("failed to add real server %s: %v", rs, err)
+			failed = true
+			continue
+		}
+		r.realServers.Insert(rs)
+	}
+	for _, rs := range r.realServers.Difference(want).List() {
+		logger.Info("delete real server %s", rs)
+		if err := r.proxier.DeleteRealServer(r.options.VirtualServer, rs); err != nil {
+			logger.Warn("failed to delete real server %s: %v", rs, err)
+			failed = true
+			continue
+		}
+		r.realServers.Delete(rs)
+	}
+	if failed {
+		r.mu.Lock()
+		r.discoveredChanged = true
+		r.mu.Unlock()
+	}
+}
+
 func (r *runner) cleanup() error {
 	var errs []string
 	for _, fn := range r.cleanupFuncs {
@@ -145,11 +257,44 @@ func (r *runner) ValidateAndSetDefaults() error {
 			}
 		}
 	}
-	r.proxier = NewProxier(r.options.scheduler, time.Duration(r.options.Interval), r.prober, r.periodicRun)
-	virtualIP, _, err := splitHostPort(r.options.VirtualServer)
+	if r.options.MetricsAddress != "" && !r.options.RunOnce && !r.options.CleanAndExit {
+		r.recorder = NewRecorder()
+	}
+	var err error
+	if r.options.ConfigFile != "" {
+		r.virtualServers, err = loadConfig(r.options.ConfigFile, r.options.scheduler, r.prober)
+	} else {
+		r.virtualServers = []*virtualServer{{
+			Address:     r.options.VirtualServer,
+			Scheduler:   r.options.scheduler,
+			RealServers: r.options.RealServer,
+			prober:      r.prober,
+		}}
+	}
 	if err != nil {
 		return err
 	}
+	r.proxier = NewProxier(r.options.scheduler, time.Duration(r.options.Interval), r.prober, r.periodicRun, r.recorder)
+	addresses := make([]string, 0, len(r.virtualServers))
+	virtualIPs := sets.NewString()
+	for _, vs := range r.virtualServers {
+		virtualIP, _, err := splitHostPort(vs.Address)
+		if err != nil {
+			return err
+		}
+		if c, ok := r.proxier.(configurer); ok {
+			if err = c.ConfigureVirtualServer(vs.Address, vs.Scheduler, vs.prober); err != nil {
+				return err
+			}
+		}
+		addresses = append(addresses, vs.Address)
+		virtualIPs.Insert(virtualIP)
+	}
+	if !r.options.RunOnce && !r.options.CleanAndExit {
+		if r.source, err = newRealServerSource(r.options); err != nil {
+			return err
+		}
+	}
 
 	var ruler Ruler
 	switch r.Mode {
@@ -158,9 +303,9 @@ func (r *runner) ValidateAndSetDefaults() error {
 			logger.Warn("running routeMode and Target IP is not valid IP, skipping")
 			break
 		}
-		ruler, err = newRouteImpl(virtualIP, r.options.TargetIP.String())
+		ruler, err = newRouteImpl(r.options.TargetIP.String(), virtualIPs.List()...)
 	case linkMode:
-		ruler, err = newIptablesImpl(r.options.IfaceName, r.options.MasqueradeBit, r.options.VirtualServer)
+		ruler, err = newIptablesImpl(r.options.IfaceName, r.options.MasqueradeBit, addresses...)
 	case "":
 		// do nothing, disable ruler
 	default:
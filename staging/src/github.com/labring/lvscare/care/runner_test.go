@@ -0,0 +1,143 @@
+// Copyright © 2023 sealos.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package care
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// fakeProxier records the real servers of the virtual server, the real servers in failures
+// fail to be ensured or deleted.
+type fakeProxier struct {
+	realServers sets.String
+	failures    sets.String
+	tries       int
+}
+
+func (p *fakeProxier) EnsureVirtualServer(string) error { return nil }
+
+func (p *fakeProxier) DeleteVirtualServer(string) error { return nil }
+
+func (p *fakeProxier) EnsureRealServer(_, rs string) error {
+	if p.failures.Has(rs) {
+		return errors.New("fake error")
+	}
+	p.realServers.Insert(rs)
+	return nil
+}
+
+func (p *fakeProxier) DeleteRealServer(_, rs string) error {
+	if p.failures.Has(rs) {
+		return errors.New("fake error")
+	}
+	p.realServers.Delete(rs)
+	return nil
+}
+
+func (p *fakeProxier) RunLoop(context.Context) error { return nil }
+
+func (p *fakeProxier) TryRun() error {
+	p.tries++
+	return nil
+}
+
+func TestRunner_syncRealServers(t *testing.T) {
+	static := []string{"192.168.0.2:6443", "192.168.0.3:6443"}
+	// each step sets the discovered real servers if set, fails the real servers of
+	// failures and syncs the real servers
+	type step struct {
+		discovered  []string
+		set         bool
+		failures    []string
+		want        []string
+		wantChanged bool
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "add and delete",
+			steps: []step{
+				{discovered: []string{"192.168.0.3:6443", "192.168.0.4:6443"}, set: true, want: []string{"192.168.0.3:6443", "192.168.0.4:6443"}},
+				{discovered: []string{"192.168.0.4:6443"}, set: true, want: []string{"192.168.0.4:6443"}},
+			},
+		},
+		{
+			name: "unchanged",
+			steps: []step{
+				{want: static},
+			},
+		},
+		{
+			name: "empty discovery falls back to static",
+			steps: []step{
+				{discovered: []string{"192.168.0.4:6443"}, set: true, want: []string{"192.168.0.4:6443"}},
+				{discovered: nil, set: true, want: static},
+			},
+		},
+		{
+			name: "retry failed ensure",
+			steps: []step{
+				{discovered: []string{"192.168.0.2:6443", "192.168.0.4:6443"}, set: true, failures: []string{"192.168.0.4:6443"},
+					want: []string{"192.168.0.2:6443"}, wantChanged: true},
+				{want: []string{"192.168.0.2:6443", "192.168.0.4:6443"}},
+			},
+		},
+		{
+			name: "retry failed delete",
+			steps: []step{
+				{discovered: []string{"192.168.0.2:6443"}, set: true, failures: []string{"192.168.0.3:6443"},
+					want: static, wantChanged: true},
+				{want: []string{"192.168.0.2:6443"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakeProxier{realServers: sets.NewString(static...)}
+			r := &runner{
+				options:     &options{VirtualServer: "10.103.97.2:6443", RealServer: static},
+				proxier:     p,
+				realServers: sets.NewString(static...),
+			}
+			for i, s := range tt.steps {
+				if s.set {
+					tries := p.tries
+					r.setDiscovered(s.discovered)
+					if p.tries != tries+1 {
+						t.Errorf("step %d: setDiscovered() did not trigger a check", i)
+					}
+				}
+				p.failures = sets.NewString(s.failures...)
+				r.syncRealServers()
+				if got := p.realServers.List(); !reflect.DeepEqual(got, s.want) {
+					t.Errorf("step %d: real servers of proxier = %v, want %v", i, got, s.want)
+				}
+				if got := r.realServers.List(); !reflect.DeepEqual(got, s.want) {
+					t.Errorf("step %d: real servers of runner = %v, want %v", i, got, s.want)
+				}
+				if r.discoveredChanged != s.wantChanged {
+					t.Errorf("step %d: discoveredChanged = %v, want %v", i, r.discoveredChanged, s.wantChanged)
+				}
+			}
+		})
+	}
+}
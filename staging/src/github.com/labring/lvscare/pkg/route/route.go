@@ -77,6 +77,25 @@ func (r *Route) DelRoute() error {
 }
 
 // addRouteGatewayViaHost host: 10.103.97.2  gateway 192.168.253.129
+// HasRoute returns true if the route to host via gateway exists.
+func (r *Route) HasRoute() (bool, error) {
+	if err := validateIPv4Type(r.Gateway, r.Host); err != nil {
+		return false, err
+	}
+
+	routes, err := netlink.RouteListFiltered(netlink.FAMILY_V4, &netlink.Route{
+		Dst: &net.IPNet{
+			IP:   net.ParseIP(r.Host),
+			Mask: net.CIDRMask(32, 32),
+		},
+		Gw: net.ParseIP(r.Gateway),
+	}, netlink.RT_FILTER_DST|netlink.RT_FILTER_GW)
+	if err != nil {
+		return false, fmt.Errorf("failed to list %s routes err: %v", r.Host, err)
+	}
+	return len(routes) > 0, nil
+}
+
 func addRouteGatewayViaHost(host, gateway string, priority int) error {
 	Dst := &net.IPNet{
 		IP:   net.ParseIP(host),